@@ -3,6 +3,7 @@ package pg
 import (
 	"context"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/go-pg/pg/v10/internal"
@@ -18,6 +19,8 @@ type baseDB struct {
 
 	fmter      *orm.Formatter
 	queryHooks []QueryHook
+
+	replicas *replicaSet // nil for Tx, Conn, and DB without replicas
 }
 
 // PoolStats contains the stats of a connection pool.
@@ -37,12 +40,15 @@ func (db *baseDB) clone() *baseDB {
 
 		fmter:      db.fmter,
 		queryHooks: copyQueryHooks(db.queryHooks),
+
+		replicas: db.replicas,
 	}
 }
 
 func (db *baseDB) withPool(p pool.Pooler) *baseDB {
 	cp := db.clone()
 	cp.pool = p
+	cp.replicas = nil
 	return cp
 }
 
@@ -101,27 +107,83 @@ func (db *baseDB) initConn(ctx context.Context, cn *pool.Conn) error {
 		return nil
 	}
 	cn.Inited = true
+	cn.Addr = dialedAddr(db.opt, cn.NetConn())
 
-	if db.opt.TLSConfig != nil {
-		err := db.enableSSL(ctx, cn, db.opt.TLSConfig)
-		if err != nil {
-			return err
-		}
+	if db.opt.ProtocolTrace != nil {
+		cn.SetTrace(db.opt.ProtocolTrace)
 	}
 
-	err := db.startup(ctx, cn, db.opt.User, db.opt.Password, db.opt.Database, db.opt.ApplicationName)
+	err := db.handshake(ctx, cn)
 	if err != nil {
 		return err
 	}
 
-	if db.opt.OnConnect != nil {
+	if db.opt.StatementTimeout > 0 || db.opt.LockTimeout > 0 || db.opt.OnConnect != nil {
 		p := pool.NewSingleConnPool(db.pool, cn)
-		return db.opt.OnConnect(ctx, newConn(ctx, db.withPool(p)))
+		conn := newConn(ctx, db.withPool(p))
+
+		if db.opt.StatementTimeout > 0 {
+			_, err := conn.ExecContext(ctx, "SET statement_timeout = ?",
+				durationMillis(db.opt.StatementTimeout))
+			if err != nil {
+				return err
+			}
+		}
+		if db.opt.LockTimeout > 0 {
+			_, err := conn.ExecContext(ctx, "SET lock_timeout = ?",
+				durationMillis(db.opt.LockTimeout))
+			if err != nil {
+				return err
+			}
+		}
+
+		if db.opt.OnConnect != nil {
+			conn.netConnInfo = &NetConnInfo{netConn: cn.NetConn()}
+			return db.opt.OnConnect(ctx, conn)
+		}
 	}
 
 	return nil
 }
 
+// handshake negotiates TLS and authenticates the connection
+// within Options.ConnectTimeout.
+func (db *baseDB) handshake(ctx context.Context, cn *pool.Conn) error {
+	if db.opt.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, db.opt.ConnectTimeout)
+		defer cancel()
+	}
+
+	if db.opt.TLSConfig != nil {
+		err := db.enableSSL(ctx, cn, db.opt.TLSConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	password := db.opt.Password
+	if db.opt.GetPassword != nil {
+		var err error
+		password, err = db.opt.GetPassword(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return db.startup(ctx, cn, db.opt.User, password, db.opt.Database, db.opt.ApplicationName)
+}
+
+// durationMillis converts d to milliseconds used by timeout settings
+// rounding positive durations up so they don't disable the timeout.
+func durationMillis(d time.Duration) int64 {
+	ms := int64(d / time.Millisecond)
+	if ms == 0 && d > 0 {
+		ms = 1
+	}
+	return ms
+}
+
 func (db *baseDB) releaseConn(ctx context.Context, cn *pool.Conn, err error) {
 	if isBadConn(err, false) {
 		db.pool.Remove(ctx, cn, err)
@@ -130,6 +192,12 @@ func (db *baseDB) releaseConn(ctx context.Context, cn *pool.Conn, err error) {
 	}
 }
 
+// withConn runs fn with a connection from the pool. When the context is
+// canceled, a cancel request is sent to the server using a side connection
+// and the connection is returned to the pool once the server reports that
+// the query was canceled. The connection is closed when the cancel request
+// fails or the server does not respond within cancelGracePeriod after the
+// context is done.
 func (db *baseDB) withConn(
 	ctx context.Context, fn func(context.Context, *pool.Conn) error,
 ) error {
@@ -139,15 +207,24 @@ func (db *baseDB) withConn(
 	}
 
 	var fnDone chan struct{}
+	var cancelErr error
+	var graceCtx *cancelGraceContext
 	if ctx != nil && ctx.Done() != nil {
 		fnDone = make(chan struct{})
+		graceCtx = &cancelGraceContext{Context: ctx}
 		go func() {
 			select {
 			case <-fnDone: // fn has finished, skip cancel
 			case <-ctx.Done():
-				err := db.cancelRequest(cn.ProcessID, cn.SecretKey)
-				if err != nil {
-					internal.Logger.Printf(ctx, "cancelRequest failed: %s", err)
+				cancelErr = db.cancelRequest(cn)
+				if cancelErr != nil {
+					internal.Logger.Printf(ctx, "cancelRequest failed: %s", cancelErr)
+					// Unblock fn that waits for the server.
+					_ = cn.Close()
+				} else {
+					// Don't wait forever if the cancel request is lost
+					// or the server does not respond.
+					_ = cn.NetConn().SetDeadline(graceCtx.canceled())
 				}
 				// Signal end of conn use.
 				fnDone <- struct{}{}
@@ -163,17 +240,73 @@ func (db *baseDB) withConn(
 
 		select {
 		case <-fnDone: // wait for cancel to finish request
-			// Looks like the canceled connection must be always removed from the pool.
-			db.pool.Remove(ctx, cn, err)
+			if cancelErr == nil && isQueryCanceled(err) {
+				// The server has canceled the query and the connection
+				// is ready for the next query.
+				db.pool.Put(ctx, cn)
+			} else {
+				// The cancel request may arrive after the query has finished
+				// and cancel the next query on the connection.
+				db.pool.Remove(ctx, cn, err)
+			}
 		case fnDone <- struct{}{}: // signal fn finish, skip cancel goroutine
 			db.releaseConn(ctx, cn, err)
 		}
 	}()
 
-	err = fn(ctx, cn)
+	if fnDone != nil {
+		// The query is canceled using a cancel request, so network deadline
+		// is extended to give the server time to cancel the query.
+		err = fn(graceCtx, cn)
+	} else {
+		err = fn(ctx, cn)
+	}
 	return err
 }
 
+// cancelGracePeriod is the time the server has to cancel the query after
+// the context is done before the connection is closed.
+const cancelGracePeriod = 3 * time.Second
+
+// cancelGraceContext extends the context deadline used for the connection
+// read and write deadlines by cancelGracePeriod, so the query is canceled
+// by the server instead of breaking the connection. Contexts without
+// a deadline get one when they are canceled.
+type cancelGraceContext struct {
+	context.Context
+
+	mu         sync.Mutex
+	canceledAt time.Time
+}
+
+func (c *cancelGraceContext) Deadline() (time.Time, bool) {
+	deadline, ok := c.Context.Deadline()
+	if ok {
+		deadline = deadline.Add(cancelGracePeriod)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.canceledAt.IsZero() {
+		if tm := c.canceledAt.Add(cancelGracePeriod); !ok || tm.Before(deadline) {
+			return tm, true
+		}
+	}
+	return deadline, ok
+}
+
+// canceled records the cancellation and returns the new deadline.
+func (c *cancelGraceContext) canceled() time.Time {
+	c.mu.Lock()
+	if c.canceledAt.IsZero() {
+		c.canceledAt = time.Now()
+	}
+	c.mu.Unlock()
+
+	deadline, _ := c.Deadline()
+	return deadline
+}
+
 func (db *baseDB) shouldRetry(err error) bool {
 	switch err {
 	case io.EOF, io.ErrUnexpectedEOF:
@@ -207,7 +340,13 @@ func (db *baseDB) shouldRetry(err error) bool {
 // It is rare to Close a DB, as the DB handle is meant to be
 // long-lived and shared between many goroutines.
 func (db *baseDB) Close() error {
-	return db.pool.Close()
+	err := db.pool.Close()
+	if db.replicas != nil {
+		if replicaErr := db.replicas.Close(); err == nil {
+			err = replicaErr
+		}
+	}
+	return err
 }
 
 // Exec executes a query ignoring returned rows. The params are for any
@@ -291,6 +430,16 @@ func (db *baseDB) QueryContext(c context.Context, model, query interface{}, para
 }
 
 func (db *baseDB) query(ctx context.Context, model, query interface{}, params ...interface{}) (Result, error) {
+	if r := db.readReplica(query); r != nil {
+		res, err := db.withPool(r.pool).query(ctx, model, query, params...)
+		if !isReplicaDown(err) || ctx.Err() != nil {
+			return res, err
+		}
+		// The replica is skipped until the next successful health check.
+		internal.Logger.Printf(ctx, "pg: replica %s failed: %s (retrying on primary)", r.addr, err)
+		r.markDown()
+	}
+
 	wb := pool.GetWriteBuffer()
 	defer pool.PutWriteBuffer(wb)
 
@@ -405,13 +554,18 @@ func (db *baseDB) copyFrom(
 	}
 
 	for {
+		var readErr error
 		err = cn.WithWriter(ctx, db.opt.WriteTimeout, func(wb *pool.WriteBuffer) error {
-			return writeCopyData(wb, r)
+			readErr = writeCopyData(wb, r)
+			return readErr
 		})
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
+			if err == readErr {
+				return nil, db.copyFail(ctx, cn, readErr)
+			}
 			return nil, err
 		}
 	}
@@ -435,6 +589,28 @@ func (db *baseDB) copyFrom(
 	return res, nil
 }
 
+// copyFail aborts COPY FROM STDIN after the reader failed so the connection
+// can be used again. It returns the reader error.
+func (db *baseDB) copyFail(ctx context.Context, cn *pool.Conn, readErr error) error {
+	err := cn.WithWriter(ctx, db.opt.WriteTimeout, func(wb *pool.WriteBuffer) error {
+		writeCopyFail(wb, readErr.Error())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cn.WithReader(ctx, db.opt.ReadTimeout, func(rd *pool.ReaderContext) error {
+		_, err := readReadyForQuery(rd)
+		return err
+	})
+	if _, ok := err.(Error); err != nil && !ok {
+		return err
+	}
+
+	return readErr
+}
+
 // CopyTo copies data from a table to writer.
 func (db *baseDB) CopyTo(w io.Writer, query interface{}, params ...interface{}) (res Result, err error) {
 	c := db.db.Context()
@@ -506,30 +682,43 @@ func (db *baseDB) Ping(ctx context.Context) error {
 
 // Model returns new query for the model.
 func (db *baseDB) Model(model ...interface{}) *Query {
-	return orm.NewQuery(db.db, model...)
+	return db.withMaxRows(orm.NewQuery(db.db, model...))
 }
 
 func (db *baseDB) ModelContext(c context.Context, model ...interface{}) *Query {
-	return orm.NewQueryContext(c, db.db, model...)
+	return db.withMaxRows(orm.NewQueryContext(c, db.db, model...))
+}
+
+func (db *baseDB) withMaxRows(q *Query) *Query {
+	if db.opt.MaxRows > 0 {
+		q = q.MaxRows(db.opt.MaxRows)
+	}
+	return q
 }
 
 func (db *baseDB) Formatter() orm.QueryFormatter {
 	return db.fmter
 }
 
-func (db *baseDB) cancelRequest(processID, secretKey int32) error {
+// cancelRequest asks the server that runs the backend of cn to cancel
+// the current query. The server is dialed directly, because with
+// Options.Addrs the pool dialer can pick another server.
+func (db *baseDB) cancelRequest(cn *pool.Conn) error {
 	c := context.TODO()
 
-	cn, err := db.pool.NewConn(c)
+	addr := cn.Addr
+	if addr == "" {
+		addr = db.opt.Addr
+	}
+
+	netConn, err := db.opt.Dialer(c, db.opt.Network, addr)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		_ = db.pool.CloseConn(cn)
-	}()
+	defer netConn.Close()
 
-	return cn.WithWriter(c, db.opt.WriteTimeout, func(wb *pool.WriteBuffer) error {
-		writeCancelRequestMsg(wb, processID, secretKey)
+	return pool.NewConn(netConn).WithWriter(c, db.opt.WriteTimeout, func(wb *pool.WriteBuffer) error {
+		writeCancelRequestMsg(wb, cn.ProcessID, cn.SecretKey)
 		return nil
 	})
 }
@@ -190,6 +190,7 @@ func (test *conversionTest) Assert(t *testing.T, err error) {
 }
 
 func conversionTests() []conversionTest {
+	bar := "bar"
 	return []conversionTest{
 		{src: nil, dst: nil, wanterr: "pg: Scan(nil)"},
 		{src: nil, dst: new(uintptr), wanterr: "pg: Scan(unsupported uintptr)"},
@@ -331,6 +332,7 @@ func conversionTests() []conversionTest {
 		{src: pg.Hstore(map[string]string{}), dst: pg.Hstore(new(map[string]string)), pgtype: "hstore"},
 		{src: pg.Hstore(map[string]string{"foo": "bar"}), dst: pg.Hstore(new(map[string]string)), pgtype: "hstore"},
 		{src: pg.Hstore(map[string]string{`'"\{}=>`: `'"\{}=>`}), dst: pg.Hstore(new(map[string]string)), pgtype: "hstore"},
+		{src: pg.Hstore(map[string]*string{"foo": &bar, "null": nil}), dst: pg.Hstore(new(map[string]*string)), pgtype: "hstore"},
 
 		{src: nil, dst: sql.NullBool{}, pgtype: "bool", wanterr: "pg: Scan(non-pointer sql.NullBool)"},
 		{src: nil, dst: new(*sql.NullBool), pgtype: "bool", wantnil: true},
@@ -407,6 +409,18 @@ func conversionTests() []conversionTest {
 		{src: mustParseCIDR("2001:4f8:3:ba::/64"), dst: new(net.IPNet), pgtype: "cidr"},
 		{src: mustParseCIDR("2001:4f8:3:ba:2e0:81ff:fe22:d1f1/128"), dst: new(net.IPNet), pgtype: "cidr"},
 
+		{src: nil, dst: new(pg.Inet), wanted: pg.Inet{}, pgtype: "inet"},
+		{src: mustParseInet("192.168.100.128/25"), dst: new(pg.Inet), pgtype: "inet"},
+		{src: mustParseInet("10.0.0.1"), dst: new(pg.Inet), pgtype: "inet"},
+		{src: mustParseInet("2001:4f8:3:ba:2e0:81ff:fe22:d1f1/64"), dst: new(pg.Inet), pgtype: "inet"},
+		{src: nil, dst: new(pg.CIDR), wanted: pg.CIDR{}, pgtype: "cidr"},
+		{src: mustParsePgCIDR("192.168.100.128/25"), dst: new(pg.CIDR), pgtype: "cidr"},
+		{src: mustParsePgCIDR("2001:4f8:3:ba::/64"), dst: new(pg.CIDR), pgtype: "cidr"},
+
+		{src: nil, dst: new(pg.Numeric), wanted: pg.Numeric{}, pgtype: "numeric"},
+		{src: mustParseNumeric("12345678901234567890.123456789"), dst: new(pg.Numeric), pgtype: "numeric"},
+		{src: mustParseNumeric("-0.0100"), dst: new(pg.Numeric), pgtype: "numeric"},
+
 		{src: nil, dst: new(Valuer), wanted: Valuer{}},
 		{src: (*Valuer)(nil), dst: new(Valuer), wanted: Valuer{}},
 		{src: new(Valuer), dst: new(Valuer), wanted: Valuer{}},
@@ -482,6 +496,30 @@ func mustParseCIDR(s string) *net.IPNet {
 	return ipnet
 }
 
+func mustParseNumeric(s string) pg.Numeric {
+	num, err := pg.ParseNumeric(s)
+	if err != nil {
+		panic(err)
+	}
+	return num
+}
+
+func mustParseInet(s string) pg.Inet {
+	inet, err := pg.ParseInet(s)
+	if err != nil {
+		panic(err)
+	}
+	return inet
+}
+
+func mustParsePgCIDR(s string) pg.CIDR {
+	cidr, err := pg.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return cidr
+}
+
 func TestReadColumnValue(t *testing.T) {
 	db := pg.Connect(pgOptions())
 	defer db.Close()
@@ -0,0 +1,521 @@
+package pg
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/go-pg/pg/v10/internal"
+	"github.com/go-pg/pg/v10/internal/pool"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-pg/pg/v10/types"
+)
+
+// Type OIDs supported by CopyToQuery.
+const (
+	oidBool        = 16
+	oidBytea       = 17
+	oidName        = 19
+	oidInt8        = 20
+	oidInt2        = 21
+	oidInt4        = 23
+	oidText        = 25
+	oidOID         = 26
+	oidJSON        = 114
+	oidCIDR        = 650
+	oidFloat4      = 700
+	oidFloat8      = 701
+	oidInet        = 869
+	oidBpchar      = 1042
+	oidVarchar     = 1043
+	oidDate        = 1082
+	oidTime        = 1083
+	oidTimestamp   = 1114
+	oidTimestamptz = 1184
+	oidNumeric     = 1700
+	oidUUID        = 2950
+	oidJSONB       = 3802
+)
+
+var copyBinarySignature = []byte("PGCOPY\n\377\r\n\000")
+
+// pgEpoch is the origin of PostgreSQL binary date and time values.
+var pgEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// CopyToQuery selects rows using
+// COPY (SELECT ...) TO STDOUT WITH (FORMAT binary) and scans them into
+// the model. It is faster than Select for large exports, but has-many and
+// many-to-many relations are not loaded and columns must have one of
+// the built-in types, e.g. enums should be cast to text.
+func (db *baseDB) CopyToQuery(c context.Context, model interface{}, q *orm.Query) (res Result, err error) {
+	err = db.withConn(c, func(c context.Context, cn *pool.Conn) error {
+		res, err = db.copyToQuery(c, cn, model, q)
+		return err
+	})
+	return res, err
+}
+
+func (db *baseDB) copyToQuery(
+	c context.Context, cn *pool.Conn, model interface{}, q *orm.Query,
+) (Result, error) {
+	query, err := orm.NewSelectQuery(q).AppendQuery(db.fmter, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := (&inlineParamsQuery{q: string(query)}).AppendQuery(nil, nil); err != nil {
+		return nil, errors.New("pg: CopyToQuery does not support bind parameters like $1")
+	}
+
+	columns, err := db.describe(c, cn, string(query))
+	if err != nil {
+		return nil, err
+	}
+	for _, col := range columns {
+		if !isCopyBinaryType(col.DataType) {
+			return nil, fmt.Errorf(
+				"pg: CopyToQuery does not support column=%q with type oid=%d",
+				col.Name, col.DataType)
+		}
+	}
+
+	m, err := newModel(model)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := &copyBinaryDecoder{
+		ctx:     c,
+		columns: columns,
+		model:   m,
+	}
+	res, err := db.copyTo(c, cn, dec, &copyToQuery{query: query})
+	if err != nil {
+		return nil, err
+	}
+	if err := dec.finish(); err != nil {
+		return nil, err
+	}
+
+	return &result{
+		model:    m,
+		affected: res.RowsAffected(),
+		returned: dec.returned,
+	}, nil
+}
+
+// describe returns columns of the query using the unnamed statement.
+func (db *baseDB) describe(
+	c context.Context, cn *pool.Conn, q string,
+) ([]types.ColumnInfo, error) {
+	err := cn.WithWriter(c, db.opt.WriteTimeout, func(wb *pool.WriteBuffer) error {
+		writeParseDescribeSyncMsg(wb, "", q)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []types.ColumnInfo
+	err = cn.WithReader(c, db.opt.ReadTimeout, func(rd *pool.ReaderContext) error {
+		columns, err = readParseDescribeSync(rd)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+//------------------------------------------------------------------------------
+
+type copyToQuery struct {
+	query []byte
+}
+
+var _ orm.QueryAppender = (*copyToQuery)(nil)
+
+func (q *copyToQuery) AppendQuery(_ orm.QueryFormatter, b []byte) ([]byte, error) {
+	b = append(b, "COPY ("...)
+	b = append(b, q.query...)
+	b = append(b, ") TO STDOUT WITH (FORMAT binary)"...)
+	return b, nil
+}
+
+//------------------------------------------------------------------------------
+
+// copyBinaryDecoder decodes COPY binary format and scans rows into
+// the model. Rows can be split across several Write calls.
+type copyBinaryDecoder struct {
+	ctx     context.Context
+	columns []types.ColumnInfo
+	model   orm.Model
+
+	buf      []byte
+	text     []byte
+	header   bool
+	trailer  bool
+	returned int
+	firstErr error
+}
+
+// Write never returns an error so the rest of COPY data is consumed
+// and the connection stays usable. Errors are reported by finish.
+func (d *copyBinaryDecoder) Write(p []byte) (int, error) {
+	d.buf = append(d.buf, p...)
+
+	for d.firstErr == nil && !d.trailer {
+		n, err := d.decode(d.buf)
+		if err != nil {
+			d.firstErr = err
+			break
+		}
+		if n == 0 {
+			break
+		}
+		d.buf = d.buf[n:]
+	}
+
+	if len(d.buf) == 0 {
+		d.buf = nil
+	}
+	return len(p), nil
+}
+
+func (d *copyBinaryDecoder) finish() error {
+	if d.firstErr != nil {
+		return d.firstErr
+	}
+	if !d.trailer {
+		return errors.New("pg: COPY binary data is truncated")
+	}
+	return nil
+}
+
+// decode decodes the header or a single row and returns the number of
+// consumed bytes or 0 if more data is needed.
+func (d *copyBinaryDecoder) decode(b []byte) (int, error) {
+	if !d.header {
+		const headerLen = 19
+		if len(b) < headerLen {
+			return 0, nil
+		}
+		if !bytes.Equal(b[:len(copyBinarySignature)], copyBinarySignature) {
+			return 0, errors.New("pg: invalid COPY binary signature")
+		}
+		extLen := int(binary.BigEndian.Uint32(b[15:]))
+		if len(b) < headerLen+extLen {
+			return 0, nil
+		}
+		d.header = true
+		return headerLen + extLen, nil
+	}
+
+	if len(b) < 2 {
+		return 0, nil
+	}
+	numCol := int(int16(binary.BigEndian.Uint16(b)))
+	if numCol == -1 {
+		d.trailer = true
+		return 2, nil
+	}
+	if numCol != len(d.columns) {
+		return 0, fmt.Errorf("pg: got %d columns in COPY row, wanted %d",
+			numCol, len(d.columns))
+	}
+
+	// Check that the whole row is buffered.
+	pos := 2
+	for i := 0; i < numCol; i++ {
+		if len(b) < pos+4 {
+			return 0, nil
+		}
+		n := int(int32(binary.BigEndian.Uint32(b[pos:])))
+		pos += 4
+		if n > 0 {
+			pos += n
+		}
+	}
+	if len(b) < pos {
+		return 0, nil
+	}
+
+	return pos, d.scanRow(b[2:pos])
+}
+
+func (d *copyBinaryDecoder) scanRow(b []byte) error {
+	scanner := d.model.NextColumnScanner()
+
+	if h, ok := scanner.(orm.BeforeScanHook); ok {
+		if err := h.BeforeScan(d.ctx); err != nil {
+			return err
+		}
+	}
+
+	var firstErr error
+	for _, col := range d.columns {
+		n := int(int32(binary.BigEndian.Uint32(b)))
+		b = b[4:]
+
+		if n == -1 {
+			if err := scanner.ScanColumn(col, pool.NewBytesReader(nil), -1); err != nil && firstErr == nil {
+				firstErr = internal.Errorf(err.Error())
+			}
+			continue
+		}
+
+		text, err := appendCopyBinaryText(d.text[:0], col.DataType, b[:n])
+		b = b[n:]
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		d.text = text
+
+		if err := scanner.ScanColumn(col, pool.NewBytesReader(text), len(text)); err != nil && firstErr == nil {
+			firstErr = internal.Errorf(err.Error())
+		}
+	}
+
+	if h, ok := scanner.(orm.AfterScanHook); ok {
+		if err := h.AfterScan(d.ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := d.model.AddColumnScanner(scanner); err != nil {
+		return err
+	}
+
+	d.returned++
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+func isCopyBinaryType(dataType int32) bool {
+	switch dataType {
+	case oidBool, oidBytea, oidName, oidInt8, oidInt2, oidInt4, oidText, oidOID,
+		oidJSON, oidCIDR, oidFloat4, oidFloat8, oidInet, oidBpchar, oidVarchar,
+		oidDate, oidTime, oidTimestamp, oidTimestamptz, oidNumeric, oidUUID,
+		oidJSONB:
+		return true
+	}
+	return false
+}
+
+// appendCopyBinaryText converts a value in the binary format to
+// the PostgreSQL text format understood by the column scanners.
+func appendCopyBinaryText(b []byte, dataType int32, v []byte) ([]byte, error) {
+	switch dataType {
+	case oidText, oidVarchar, oidBpchar, oidName, oidJSON:
+		return append(b, v...), nil
+	case oidJSONB:
+		if len(v) == 0 || v[0] != 1 {
+			return nil, errors.New("pg: unsupported jsonb binary version")
+		}
+		return append(b, v[1:]...), nil
+	case oidBytea:
+		b = append(b, `\x`...)
+		start := len(b)
+		b = append(b, make([]byte, hex.EncodedLen(len(v)))...)
+		hex.Encode(b[start:], v)
+		return b, nil
+	}
+
+	if err := checkCopyBinaryLen(dataType, v); err != nil {
+		return nil, err
+	}
+
+	switch dataType {
+	case oidBool:
+		if v[0] != 0 {
+			return append(b, 't'), nil
+		}
+		return append(b, 'f'), nil
+	case oidInt2:
+		return strconv.AppendInt(b, int64(int16(binary.BigEndian.Uint16(v))), 10), nil
+	case oidInt4:
+		return strconv.AppendInt(b, int64(int32(binary.BigEndian.Uint32(v))), 10), nil
+	case oidOID:
+		return strconv.AppendUint(b, uint64(binary.BigEndian.Uint32(v)), 10), nil
+	case oidInt8:
+		return strconv.AppendInt(b, int64(binary.BigEndian.Uint64(v)), 10), nil
+	case oidFloat4:
+		f := math.Float32frombits(binary.BigEndian.Uint32(v))
+		return strconv.AppendFloat(b, float64(f), 'g', -1, 32), nil
+	case oidFloat8:
+		f := math.Float64frombits(binary.BigEndian.Uint64(v))
+		return strconv.AppendFloat(b, f, 'g', -1, 64), nil
+	case oidDate:
+		days := int32(binary.BigEndian.Uint32(v))
+		switch days {
+		case math.MaxInt32:
+			return append(b, "infinity"...), nil
+		case math.MinInt32:
+			return append(b, "-infinity"...), nil
+		}
+		return pgEpoch.AddDate(0, 0, int(days)).AppendFormat(b, "2006-01-02"), nil
+	case oidTime:
+		usec := int64(binary.BigEndian.Uint64(v))
+		tm := time.Time{}.Add(time.Duration(usec) * time.Microsecond)
+		return tm.AppendFormat(b, "15:04:05.999999"), nil
+	case oidTimestamp, oidTimestamptz:
+		usec := int64(binary.BigEndian.Uint64(v))
+		switch usec {
+		case math.MaxInt64:
+			return append(b, "infinity"...), nil
+		case math.MinInt64:
+			return append(b, "-infinity"...), nil
+		}
+		tm := pgEpoch.Add(time.Duration(usec/1e6) * time.Second).
+			Add(time.Duration(usec%1e6) * time.Microsecond)
+		if dataType == oidTimestamptz {
+			return tm.AppendFormat(b, "2006-01-02 15:04:05.999999-07:00"), nil
+		}
+		return tm.AppendFormat(b, "2006-01-02 15:04:05.999999"), nil
+	case oidUUID:
+		var buf [36]byte
+		hex.Encode(buf[0:8], v[0:4])
+		buf[8] = '-'
+		hex.Encode(buf[9:13], v[4:6])
+		buf[13] = '-'
+		hex.Encode(buf[14:18], v[6:8])
+		buf[18] = '-'
+		hex.Encode(buf[19:23], v[8:10])
+		buf[23] = '-'
+		hex.Encode(buf[24:], v[10:])
+		return append(b, buf[:]...), nil
+	case oidInet, oidCIDR:
+		return appendCopyBinaryInet(b, dataType, v)
+	case oidNumeric:
+		return appendCopyBinaryNumeric(b, v)
+	default:
+		return nil, fmt.Errorf("pg: unsupported COPY binary type oid=%d", dataType)
+	}
+}
+
+func checkCopyBinaryLen(dataType int32, v []byte) error {
+	var wanted int
+	switch dataType {
+	case oidBool:
+		wanted = 1
+	case oidInt2:
+		wanted = 2
+	case oidInt4, oidOID, oidFloat4, oidDate:
+		wanted = 4
+	case oidInt8, oidFloat8, oidTime, oidTimestamp, oidTimestamptz:
+		wanted = 8
+	case oidUUID:
+		wanted = 16
+	case oidInet, oidCIDR, oidNumeric:
+		if len(v) < 4 {
+			return fmt.Errorf("pg: invalid COPY binary value for type oid=%d", dataType)
+		}
+		return nil
+	default:
+		return nil
+	}
+	if len(v) != wanted {
+		return fmt.Errorf("pg: invalid COPY binary value for type oid=%d", dataType)
+	}
+	return nil
+}
+
+func appendCopyBinaryInet(b []byte, dataType int32, v []byte) ([]byte, error) {
+	bits, isCIDR, n := int(v[1]), v[2] != 0, int(v[3])
+	if len(v) != 4+n || (n != net.IPv4len && n != net.IPv6len) {
+		return nil, fmt.Errorf("pg: invalid COPY binary value for type oid=%d", dataType)
+	}
+
+	b = append(b, net.IP(v[4:]).String()...)
+	if isCIDR || bits != n*8 {
+		b = append(b, '/')
+		b = strconv.AppendInt(b, int64(bits), 10)
+	}
+	return b, nil
+}
+
+// appendCopyBinaryNumeric converts numeric value that is stored as
+// base-10000 digits with a weight of the first digit.
+func appendCopyBinaryNumeric(b []byte, v []byte) ([]byte, error) {
+	const (
+		numericPos    = 0x0000
+		numericNeg    = 0x4000
+		numericNaN    = 0xC000
+		numericPosInf = 0xD000
+		numericNegInf = 0xF000
+	)
+
+	if len(v) < 8 {
+		return nil, errors.New("pg: invalid COPY binary value for numeric")
+	}
+	ndigits := int(binary.BigEndian.Uint16(v))
+	weight := int(int16(binary.BigEndian.Uint16(v[2:])))
+	sign := binary.BigEndian.Uint16(v[4:])
+	dscale := int(binary.BigEndian.Uint16(v[6:]))
+	if len(v) != 8+2*ndigits {
+		return nil, errors.New("pg: invalid COPY binary value for numeric")
+	}
+
+	switch sign {
+	case numericNaN:
+		return append(b, "NaN"...), nil
+	case numericPosInf:
+		return append(b, "Infinity"...), nil
+	case numericNegInf:
+		return append(b, "-Infinity"...), nil
+	case numericNeg:
+		b = append(b, '-')
+	case numericPos:
+	default:
+		return nil, errors.New("pg: invalid COPY binary value for numeric")
+	}
+
+	digit := func(i int) int {
+		if i < 0 || i >= ndigits {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(v[8+2*i:]))
+	}
+
+	if weight < 0 {
+		b = append(b, '0')
+	} else {
+		b = strconv.AppendInt(b, int64(digit(0)), 10)
+		for i := 1; i <= weight; i++ {
+			b = appendNumericDigit(b, digit(i))
+		}
+	}
+
+	if dscale > 0 {
+		b = append(b, '.')
+		start := len(b)
+		for i := weight + 1; len(b)-start < dscale; i++ {
+			b = appendNumericDigit(b, digit(i))
+		}
+		b = b[:start+dscale]
+	}
+
+	return b, nil
+}
+
+func appendNumericDigit(b []byte, d int) []byte {
+	return append(b,
+		byte('0'+d/1000),
+		byte('0'+d/100%10),
+		byte('0'+d/10%10),
+		byte('0'+d%10))
+}
@@ -0,0 +1,105 @@
+package pg
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+var copyBinaryTextTests = []struct {
+	dataType int32
+	v        []byte
+	wanted   string
+}{
+	{oidBool, []byte{1}, "t"},
+	{oidInt2, []byte{0xff, 0xfe}, "-2"},
+	{oidInt8, []byte{0, 0, 0, 0, 0, 0, 0x01, 0x00}, "256"},
+	{oidBytea, []byte{0xde, 0xad}, `\xdead`},
+	{oidJSONB, []byte("\x01{}"), "{}"},
+	{oidDate, []byte{0, 0, 0, 31}, "2000-02-01"},
+	{oidTimestamptz, []byte{0, 0, 0, 0, 0x3b, 0x9a, 0xca, 0x01}, "2000-01-01 00:16:40.000001+00:00"},
+	{
+		oidUUID,
+		[]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0},
+		"12345678-9abc-def0-1234-56789abcdef0",
+	},
+	{oidInet, []byte{2, 24, 0, 4, 192, 168, 0, 1}, "192.168.0.1/24"},
+	{oidInet, []byte{2, 32, 0, 4, 192, 168, 0, 1}, "192.168.0.1"},
+	{oidCIDR, []byte{2, 32, 1, 4, 10, 0, 0, 1}, "10.0.0.1/32"},
+	// 12345.6780 = 1 2345 . 6780 with dscale 4.
+	{oidNumeric, []byte{0, 3, 0, 1, 0, 0, 0, 4, 0, 1, 0x09, 0x29, 0x1a, 0x7c}, "12345.6780"},
+	// -0.05 = 0 . 0500 with dscale 2.
+	{oidNumeric, []byte{0, 1, 0xff, 0xff, 0x40, 0, 0, 2, 0x01, 0xf4}, "-0.05"},
+	{oidNumeric, []byte{0, 0, 0, 0, 0xc0, 0, 0, 0}, "NaN"},
+}
+
+func TestAppendCopyBinaryText(t *testing.T) {
+	for _, test := range copyBinaryTextTests {
+		got, err := appendCopyBinaryText(nil, test.dataType, test.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.wanted {
+			t.Fatalf("oid=%d: got %q, wanted %q", test.dataType, got, test.wanted)
+		}
+	}
+}
+
+func TestCopyBinaryDecoder(t *testing.T) {
+	type Row struct {
+		ID   int
+		Name string
+	}
+
+	b := append([]byte(nil), copyBinarySignature...)
+	b = append(b, 0, 0, 0, 0, 0, 0, 0, 0)
+	b = appendCopyBinaryRow(b, []byte{0, 0, 0, 1}, []byte("one"))
+	b = appendCopyBinaryRow(b, []byte{0, 0, 0, 2}, nil)
+	b = append(b, 0xff, 0xff)
+
+	var rows []Row
+	m, err := newModel(&rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dec := &copyBinaryDecoder{
+		columns: []types.ColumnInfo{
+			{Name: "id", DataType: oidInt4},
+			{Name: "name", DataType: oidText},
+		},
+		model: m,
+	}
+	// Write byte by byte to check that rows split across writes are decoded.
+	for i := range b {
+		if _, err := dec.Write(b[i : i+1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dec.finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	if dec.returned != 2 {
+		t.Fatalf("got %d rows, wanted 2", dec.returned)
+	}
+	if rows[0] != (Row{ID: 1, Name: "one"}) || rows[1] != (Row{ID: 2}) {
+		t.Fatalf("got %v", rows)
+	}
+}
+
+func appendCopyBinaryRow(b []byte, values ...[]byte) []byte {
+	b = append(b, 0, byte(len(values)))
+	for _, v := range values {
+		if v == nil {
+			b = append(b, 0xff, 0xff, 0xff, 0xff)
+			continue
+		}
+		var n [4]byte
+		binary.BigEndian.PutUint32(n[:], uint32(len(v)))
+		b = append(b, n[:]...)
+		b = append(b, v...)
+	}
+	return b
+}
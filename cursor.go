@@ -0,0 +1,146 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-pg/pg/v10/types"
+)
+
+var errCursorClosed = errors.New("pg: cursor is closed")
+
+var txCursorSeq uint64
+
+// Cursor is a server-side cursor declared with Tx.Cursor. It is used to
+// process large results in batches without loading all rows at once:
+//
+//	cur, err := tx.Cursor(ctx, tx.Model((*Book)(nil)).Order("id"))
+//	if err != nil {
+//		return err
+//	}
+//	defer cur.Close()
+//
+//	for {
+//		var books []Book
+//		n, err := cur.Fetch(1000, &books)
+//		if err != nil {
+//			return err
+//		}
+//		if n == 0 {
+//			break
+//		}
+//		// process books
+//	}
+//
+// Cursors only exist inside a transaction. The cursor is closed by Close,
+// when the transaction ends, or when a Fetch fails, because the failed
+// statement aborts the transaction.
+type Cursor struct {
+	tx   *Tx
+	ctx  context.Context
+	name string
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Cursor declares a cursor for the query. Query is either a *Query,
+// which is used as a SELECT query, or an SQL string with params.
+func (tx *Tx) Cursor(ctx context.Context, query interface{}, params ...interface{}) (*Cursor, error) {
+	switch q := query.(type) {
+	case *Query:
+		query = orm.NewSelectQuery(q)
+	case string:
+		query = orm.SafeQuery(q, params...)
+	}
+
+	cur := &Cursor{
+		tx:   tx,
+		ctx:  ctx,
+		name: fmt.Sprintf("pg_tx_cursor_%d", atomic.AddUint64(&txCursorSeq, 1)),
+	}
+
+	_, err := tx.ExecContext(ctx, "DECLARE ? NO SCROLL CURSOR FOR ?", types.Ident(cur.name), query)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.cursorsMu.Lock()
+	tx.cursors = append(tx.cursors, cur)
+	tx.cursorsMu.Unlock()
+
+	return cur, nil
+}
+
+// Fetch fetches the next n rows into the model and returns the number of
+// fetched rows. Slice models are reset before every batch. Zero rows
+// means that the cursor is exhausted.
+func (cur *Cursor) Fetch(n int, model interface{}) (int, error) {
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	if cur.closed {
+		return 0, errCursorClosed
+	}
+
+	res, err := cur.tx.QueryContext(cur.ctx, model, "FETCH FORWARD ? FROM ?", n, types.Ident(cur.name))
+	if err != nil {
+		cur.close()
+		return 0, err
+	}
+	return res.RowsReturned(), nil
+}
+
+// Close closes the cursor. It is safe to call Close after the transaction
+// has ended or when the cursor is already closed.
+func (cur *Cursor) Close() error {
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	if cur.closed {
+		return nil
+	}
+	cur.close()
+
+	if cur.tx.closed() {
+		return nil
+	}
+	_, err := cur.tx.ExecContext(cur.ctx, "CLOSE ?", types.Ident(cur.name))
+	return err
+}
+
+func (cur *Cursor) close() {
+	cur.closed = true
+	cur.tx.removeCursor(cur)
+}
+
+func (tx *Tx) removeCursor(cur *Cursor) {
+	tx.cursorsMu.Lock()
+	defer tx.cursorsMu.Unlock()
+
+	for i, c := range tx.cursors {
+		if c == cur {
+			tx.cursors = append(tx.cursors[:i], tx.cursors[i+1:]...)
+			return
+		}
+	}
+}
+
+// closeCursors marks cursors as closed when the transaction ends. The server
+// closes them together with the transaction.
+func (tx *Tx) closeCursors() {
+	tx.cursorsMu.Lock()
+	cursors := tx.cursors
+	tx.cursors = nil
+	tx.cursorsMu.Unlock()
+
+	for _, cur := range cursors {
+		cur.mu.Lock()
+		cur.closed = true
+		cur.mu.Unlock()
+	}
+}
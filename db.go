@@ -15,7 +15,7 @@ import (
 // and maintains its own connection pool.
 func Connect(opt *Options) *DB {
 	opt.init()
-	return newDB(
+	db := newDB(
 		context.Background(),
 		&baseDB{
 			opt:   opt,
@@ -23,6 +23,13 @@ func Connect(opt *Options) *DB {
 			fmter: orm.NewFormatter(),
 		},
 	)
+	if len(opt.ReplicaAddrs) > 0 {
+		db.replicas = newReplicaSet(db.baseDB)
+	}
+	if opt.Debug {
+		db.AddQueryHook(newDebugHook(opt))
+	}
+	return db
 }
 
 func newDB(ctx context.Context, baseDB *baseDB) *DB {
@@ -76,10 +83,18 @@ func (db *DB) WithParam(param string, value interface{}) *DB {
 
 // Listen listens for notifications sent with NOTIFY command.
 func (db *DB) Listen(ctx context.Context, channels ...string) *Listener {
+	return db.ListenWithOptions(ctx, nil, channels...)
+}
+
+// ListenWithOptions is like Listen, but configures how the Listener
+// buffers notifications received with Channel.
+func (db *DB) ListenWithOptions(
+	ctx context.Context, opt *ListenOptions, channels ...string,
+) *Listener {
 	ln := &Listener{
 		db: db,
 	}
-	ln.init()
+	ln.init(opt)
 	_ = ln.Listen(ctx, channels...)
 	return ln
 }
@@ -95,6 +110,8 @@ func (db *DB) Listen(ctx context.Context, channels ...string) *Listener {
 type Conn struct {
 	*baseDB
 	ctx context.Context
+
+	netConnInfo *NetConnInfo
 }
 
 var _ orm.DB = (*Conn)(nil)
@@ -108,6 +125,25 @@ func (db *DB) Conn() *Conn {
 	return newConn(db.ctx, db.baseDB.withPool(pool.NewStickyConnPool(db.pool)))
 }
 
+// Session is like Conn, but the returned Conn uses the ctx. Queries,
+// prepared statements, transactions, temporary tables, and SET commands
+// that run on the Session share a single connection, so the session state
+// is visible to all of them.
+//
+// The connection is returned to the pool on Close. Session state such as
+// temporary tables and settings is not reset; run DISCARD ALL before Close
+// when it must not be visible to the next user of the connection.
+func (db *DB) Session(ctx context.Context) *Conn {
+	return newConn(ctx, db.baseDB.withPool(pool.NewStickyConnPool(db.pool)))
+}
+
+// NetConnInfo returns the network connection info of the Conn passed
+// to the OnConnect hook. It returns nil for other Conns, which don't
+// have a network connection until they run a query.
+func (db *Conn) NetConnInfo() *NetConnInfo {
+	return db.netConnInfo
+}
+
 func newConn(ctx context.Context, baseDB *baseDB) *Conn {
 	conn := &Conn{
 		baseDB: baseDB,
@@ -5,12 +5,15 @@ import (
 	"context"
 	"crypto/tls"
 	"database/sql"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"net"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -257,6 +260,34 @@ var _ = Describe("OnConnect", func() {
 		Expect(err).To(MatchError("ERROR #57014 canceling statement due to user request"))
 	})
 
+	It("exposes the network connection info", func() {
+		var remoteAddr, localAddr string
+		var usesTLS bool
+
+		opt := pgOptions()
+		opt.OnConnect = func(ctx context.Context, conn *pg.Conn) error {
+			info := conn.NetConnInfo()
+			remoteAddr = info.RemoteAddr().String()
+			localAddr = info.LocalAddr().String()
+			_, usesTLS = info.ConnectionState()
+			return nil
+		}
+
+		db := pg.Connect(opt)
+		defer db.Close()
+
+		_, err := db.Exec("SELECT 1")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(remoteAddr).To(HaveSuffix(":5432"))
+		Expect(localAddr).NotTo(BeEmpty())
+		Expect(usesTLS).To(Equal(opt.TLSConfig != nil))
+
+		conn := db.Conn()
+		defer conn.Close()
+		Expect(conn.NetConnInfo()).To(BeNil())
+	})
+
 	It("does not panic with RunInTransaction", func() {
 		opt := pgOptions()
 		opt.OnConnect = func(ctx context.Context, conn *pg.Conn) error {
@@ -275,6 +306,322 @@ var _ = Describe("OnConnect", func() {
 	})
 })
 
+var _ = Describe("StatementTimeout", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		opt := pgOptions()
+		opt.PoolSize = 1
+		opt.StatementTimeout = time.Second
+		opt.LockTimeout = 500 * time.Millisecond
+		db = pg.Connect(opt)
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("sets timeouts on connect", func() {
+		var statementTimeout, lockTimeout string
+		_, err := db.QueryOne(pg.Scan(&statementTimeout), "SHOW statement_timeout")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statementTimeout).To(Equal("1s"))
+
+		_, err = db.QueryOne(pg.Scan(&lockTimeout), "SHOW lock_timeout")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lockTimeout).To(Equal("500ms"))
+	})
+
+	It("cancels slow queries", func() {
+		_, err := db.Exec("SELECT pg_sleep(2)")
+		Expect(err).To(HaveOccurred())
+		Expect(err.(pg.Error).Field('C')).To(Equal("57014"))
+	})
+
+	It("overrides statement timeout in a transaction", func() {
+		err := db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+			err := tx.WithStatementTimeout(ctx, 10*time.Millisecond)
+			Expect(err).NotTo(HaveOccurred())
+
+			var timeout string
+			_, err = tx.QueryOne(pg.Scan(&timeout), "SHOW statement_timeout")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timeout).To(Equal("10ms"))
+
+			_, err = tx.Exec("SELECT pg_sleep(1)")
+			return err
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.(pg.Error).Field('C')).To(Equal("57014"))
+
+		var timeout string
+		_, err = db.QueryOne(pg.Scan(&timeout), "SHOW statement_timeout")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timeout).To(Equal("1s"))
+	})
+})
+
+var _ = Describe("timestamp and timestamptz", func() {
+	type TimeItem struct {
+		ID      int
+		Instant time.Time
+		Wall    time.Time `pg:"type:timestamp"`
+	}
+
+	var db *pg.DB
+	var loc *time.Location
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		var err error
+		loc, err = time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = db.Model((*TimeItem)(nil)).CreateTable(&orm.CreateTableOptions{
+			Temp: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("round-trips times across DST transitions", func() {
+		tms := []time.Time{
+			// Before and after spring forward.
+			time.Date(2021, time.March, 14, 1, 59, 59, 0, loc),
+			time.Date(2021, time.March, 14, 3, 0, 0, 0, loc),
+			// 01:30 EDT and 01:30 EST one hour later.
+			time.Date(2021, time.November, 7, 5, 30, 0, 0, time.UTC).In(loc),
+			time.Date(2021, time.November, 7, 6, 30, 0, 0, time.UTC).In(loc),
+		}
+
+		items := make([]TimeItem, len(tms))
+		for i, tm := range tms {
+			items[i] = TimeItem{ID: i + 1, Instant: tm, Wall: tm}
+		}
+		_, err := db.Model(&items).Insert()
+		Expect(err).NotTo(HaveOccurred())
+
+		var got []TimeItem
+		err = db.Model(&got).Order("id").Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(HaveLen(len(tms)))
+
+		for i, tm := range tms {
+			// timestamptz keeps the instant.
+			Expect(got[i].Instant.Equal(tm)).To(BeTrue())
+			// timestamp keeps the wall clock, but not the location.
+			Expect(got[i].Wall.Location()).To(Equal(time.UTC))
+			Expect(got[i].Wall.Format("2006-01-02 15:04:05")).
+				To(Equal(tm.Format("2006-01-02 15:04:05")))
+		}
+
+		// Both fall back times have the same wall clock.
+		Expect(got[2].Wall).To(Equal(got[3].Wall))
+		Expect(got[2].Instant.Equal(got[3].Instant)).To(BeFalse())
+	})
+
+	It("finds timestamp rows with pg.Timestamp params", func() {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		Expect(err).NotTo(HaveOccurred())
+		tm := time.Date(2021, time.June, 1, 10, 0, 0, 0, berlin)
+
+		_, err = db.Model(&TimeItem{ID: 1, Instant: tm, Wall: tm}).Insert()
+		Expect(err).NotTo(HaveOccurred())
+
+		var item TimeItem
+		err = db.Model(&item).Where("wall = ?", pg.Timestamp(tm)).Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(item.ID).To(Equal(1))
+	})
+})
+
+var _ = Describe("ProtocolTrace", func() {
+	It("traces protocol messages without credentials", func() {
+		var buf bytes.Buffer
+		var mu sync.Mutex
+
+		opt := pgOptions()
+		opt.User = "postgres"
+		opt.Password = "postgres"
+		opt.PoolSize = 1
+		opt.ProtocolTrace = writerFunc(func(b []byte) (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return buf.Write(b)
+		})
+		db := pg.Connect(opt)
+		defer db.Close()
+
+		_, err := db.Exec("SELECT 1")
+		Expect(err).NotTo(HaveOccurred())
+
+		mu.Lock()
+		defer mu.Unlock()
+		trace := buf.String()
+		Expect(trace).To(ContainSubstring(" -> StartupMessage len="))
+		Expect(trace).To(ContainSubstring(" <- ParameterStatus len="))
+		Expect(trace).To(ContainSubstring(" -> Query len=13\n"))
+		Expect(trace).To(ContainSubstring(" <- DataRow len=11\n"))
+		Expect(trace).To(ContainSubstring(" <- ReadyForQuery len=5\n"))
+		Expect(trace).NotTo(ContainSubstring("postgres"))
+	})
+})
+
+type writerFunc func([]byte) (int, error)
+
+func (fn writerFunc) Write(b []byte) (int, error) {
+	return fn(b)
+}
+
+var _ = Describe("Inspect", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		qs := []string{
+			`DROP TABLE IF EXISTS inspect_children, inspect_parents`,
+			`CREATE TABLE inspect_parents (id bigserial PRIMARY KEY)`,
+			`CREATE TABLE inspect_children (
+				id bigserial PRIMARY KEY,
+				parent_id bigint NOT NULL REFERENCES inspect_parents (id),
+				name text DEFAULT 'hello',
+				UNIQUE (parent_id, name)
+			)`,
+		}
+		for _, q := range qs {
+			_, err := db.Exec(q)
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	AfterEach(func() {
+		_, err := db.Exec(`DROP TABLE inspect_children, inspect_parents`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("returns table schema", func() {
+		t, err := db.Inspect(ctx, "inspect_children")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Schema).To(Equal("public"))
+		Expect(t.Name).To(Equal("inspect_children"))
+
+		Expect(t.Columns).To(HaveLen(3))
+		Expect(t.Columns[0].Name).To(Equal("id"))
+		Expect(t.Columns[0].DataType).To(Equal("bigint"))
+		Expect(t.Columns[0].IsPrimaryKey).To(BeTrue())
+		Expect(t.Columns[1].IsNullable).To(BeFalse())
+		Expect(t.Columns[2].IsNullable).To(BeTrue())
+		Expect(t.Columns[2].Default).To(Equal("'hello'::text"))
+
+		Expect(t.Indexes).To(HaveLen(2))
+
+		Expect(t.Constraints).To(HaveLen(3))
+		fk := t.Constraints[0]
+		Expect(fk.Type).To(Equal("FOREIGN KEY"))
+		Expect(fk.Columns).To(Equal([]string{"parent_id"}))
+		Expect(fk.ForeignTable).To(Equal("inspect_parents"))
+		Expect(fk.ForeignColumns).To(Equal([]string{"id"}))
+	})
+
+	It("supports schema-qualified names", func() {
+		t, err := db.Inspect(ctx, "public.inspect_parents")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Columns).To(HaveLen(1))
+		Expect(t.Constraints[0].Type).To(Equal("PRIMARY KEY"))
+	})
+})
+
+var _ = Describe("StatStatements", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		_, err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_stat_statements")
+		if err != nil {
+			Skip(err.Error())
+		}
+		_, err = db.Exec("SELECT pg_stat_statements_reset()")
+		if err != nil {
+			// The module is not loaded via shared_preload_libraries.
+			Skip(err.Error())
+		}
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("returns statements ordered by calls", func() {
+		for i := 0; i < 3; i++ {
+			_, err := db.Exec("SELECT pg_sleep(0)")
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		stmts, err := db.StatStatements(ctx, &pg.StatStatementsOptions{
+			OrderBy:  "calls",
+			MinCalls: 3,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stmts).NotTo(BeEmpty())
+		Expect(stmts[0].Query).To(ContainSubstring("pg_sleep"))
+		Expect(stmts[0].Calls).To(BeNumerically(">=", 3))
+		Expect(stmts[0].TotalTime).To(BeNumerically(">=", stmts[0].MeanTime))
+	})
+
+	It("rejects unknown OrderBy", func() {
+		_, err := db.StatStatements(ctx, &pg.StatStatementsOptions{
+			OrderBy: "rows",
+		})
+		Expect(err).To(MatchError(`pg: StatStatements does not support OrderBy="rows"`))
+	})
+})
+
+var _ = Describe("GetPassword", func() {
+	It("is called for every new connection", func() {
+		opt := pgOptions()
+		password := opt.Password
+		opt.Password = "invalid"
+		opt.PoolSize = 1
+		opt.MaxConnAge = time.Millisecond
+
+		var calls int32
+		opt.GetPassword = func(ctx context.Context) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			return password, nil
+		}
+
+		db := pg.Connect(opt)
+		defer db.Close()
+
+		for i := 0; i < 2; i++ {
+			_, err := db.Exec("SELECT 1")
+			Expect(err).NotTo(HaveOccurred())
+			time.Sleep(10 * time.Millisecond)
+		}
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+	})
+
+	It("returns an error", func() {
+		opt := pgOptions()
+		opt.GetPassword = func(ctx context.Context) (string, error) {
+			return "", errors.New("can't get password")
+		}
+
+		db := pg.Connect(opt)
+		defer db.Close()
+
+		_, err := db.Exec("SELECT 1")
+		Expect(err).To(MatchError("can't get password"))
+	})
+})
+
 var _ = Describe("DB", func() {
 	var db *pg.DB
 	var tx *pg.Tx
@@ -399,6 +746,86 @@ var _ = Describe("DB", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(time.Since(start)).To(BeNumerically("~", time.Second, 100*time.Millisecond))
 		})
+
+		It("reuses the connection after the query is canceled", func() {
+			_, err := db.Exec("SELECT 1")
+			Expect(err).NotTo(HaveOccurred())
+			before := db.PoolStats()
+
+			c, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			_, err = db.ExecContext(c, "SELECT pg_sleep(5)")
+			Expect(err).To(MatchError("ERROR #57014 canceling statement due to user request"))
+
+			var n int
+			_, err = db.QueryOne(pg.Scan(&n), "SELECT 1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(1))
+
+			st := db.PoolStats()
+			Expect(st.TotalConns).To(Equal(before.TotalConns))
+			Expect(st.Misses).To(Equal(before.Misses))
+		})
+	})
+})
+
+var _ = Describe("DisablePreparedStatements", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		opt := pgOptions()
+		opt.DisablePreparedStatements = true
+		db = pg.Connect(opt)
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("inlines statement parameters", func() {
+		stmt, err := db.Prepare(`SELECT $1::text || '$2', $$ $2 $$, $2::int`)
+		Expect(err).NotTo(HaveOccurred())
+		defer stmt.Close()
+
+		var s1, s2 string
+		var n int
+		_, err = stmt.QueryOne(pg.Scan(&s1, &s2, &n), "it's", 42)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s1).To(Equal("it's$2"))
+		Expect(s2).To(Equal(" $2 "))
+		Expect(n).To(Equal(42))
+
+		var count int
+		_, err = stmt.QueryOne(pg.Scan(&s1, &s2, &n), "hello", 1)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM pg_prepared_statements")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(0))
+	})
+
+	It("does not inline parameters in comments and escape strings", func() {
+		stmt, err := db.Prepare("SELECT $1::text -- $2\n" +
+			", E'it\\'s $2' /* $2 /* $2 */ */, $2::int")
+		Expect(err).NotTo(HaveOccurred())
+		defer stmt.Close()
+
+		var s1, s2 string
+		var n int
+		_, err = stmt.QueryOne(pg.Scan(&s1, &s2, &n), "hello", 42)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s1).To(Equal("hello"))
+		Expect(s2).To(Equal("it's $2"))
+		Expect(n).To(Equal(42))
+	})
+
+	It("returns an error for missing parameters", func() {
+		stmt, err := db.Prepare("SELECT $1, $2")
+		Expect(err).NotTo(HaveOccurred())
+		defer stmt.Close()
+
+		_, err = stmt.Exec(1)
+		Expect(err).To(MatchError("pg: no value for parameter $2"))
 	})
 })
 
@@ -440,6 +867,46 @@ var _ = Describe("DB.Conn", func() {
 		Expect(stats.IdleConns).To(Equal(uint32(1)))
 	})
 
+	It("runs queries of a Session on the same connection", func() {
+		sess := db.Session(ctx)
+		Expect(sess.Context()).To(Equal(ctx))
+
+		_, err := sess.Exec("CREATE TEMP TABLE session_items (id int)")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = sess.Exec("SET application_name = 'session_test'")
+		Expect(err).NotTo(HaveOccurred())
+
+		stmt, err := sess.Prepare("INSERT INTO session_items VALUES ($1)")
+		Expect(err).NotTo(HaveOccurred())
+		for i := 1; i <= 3; i++ {
+			_, err = stmt.Exec(i)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(stmt.Close()).NotTo(HaveOccurred())
+
+		var ids []int
+		err = sess.Model().Table("session_items").Column("id").Order("id").Select(&ids)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]int{1, 2, 3}))
+
+		var appName string
+		_, err = sess.QueryOne(pg.Scan(&appName), "SHOW application_name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(appName).To(Equal("session_test"))
+
+		_, err = sess.Exec("DISCARD ALL")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sess.Close()).NotTo(HaveOccurred())
+
+		stats := db.PoolStats()
+		Expect(stats.TotalConns).To(Equal(uint32(1)))
+		Expect(stats.IdleConns).To(Equal(uint32(1)))
+
+		_, err = sess.Exec("SELECT 1")
+		Expect(err).To(MatchError("pg: database is closed"))
+	})
+
 	It("supports Tx", func() {
 		conn := db.Conn()
 
@@ -571,33 +1038,117 @@ var _ = Describe("array model", func() {
 	})
 })
 
-var _ = Describe("slice model", func() {
-	type value struct {
-		Id int
+var _ = Describe("composite array model", func() {
+	type Address struct {
+		tableName struct{} `pg:"address"`
+
+		Street string
+		City   string
+	}
+
+	type Customer struct {
+		tableName struct{} `pg:"customers_composite"`
+
+		ID        int
+		Addresses []Address                `pg:"composite:address"`
+		Optional  []*Address               `pg:"composite:address"`
+		Metadata  []map[string]interface{} `pg:"type:jsonb[]"`
 	}
 
 	var db *pg.DB
 
 	BeforeEach(func() {
 		db = pg.Connect(pgOptions())
-	})
 
-	AfterEach(func() {
-		Expect(db.Close()).NotTo(HaveOccurred())
-	})
+		err := db.Model((*Customer)(nil)).DropTable(&orm.DropTableOptions{
+			IfExists: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
 
-	It("does not error when there are no rows", func() {
-		ints := make([]int, 1)
-		_, err := db.Query(&ints, "SELECT generate_series(1, 0)")
+		err = db.Model((*Address)(nil)).DropComposite(&orm.DropCompositeOptions{
+			IfExists: true,
+		})
 		Expect(err).NotTo(HaveOccurred())
-		Expect(ints).To(BeEmpty())
-	})
 
-	It("does not error when there are no rows", func() {
-		slice := make([]value, 1)
-		_, err := db.Query(&slice, "SELECT generate_series(1, 0)")
+		err = db.Model((*Address)(nil)).CreateComposite(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = db.Model((*Customer)(nil)).CreateTable(nil)
 		Expect(err).NotTo(HaveOccurred())
-		Expect(slice).To(BeEmpty())
+	})
+
+	AfterEach(func() {
+		err := db.Model((*Customer)(nil)).DropTable(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = db.Model((*Address)(nil)).DropComposite(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("inserts and selects composite arrays", func() {
+		customers := []Customer{{
+			ID: 1,
+			Addresses: []Address{
+				{Street: "1 Main St", City: "O'Fallon"},
+				{Street: `"Quoted", (street)`, City: `back\slash`},
+			},
+			Optional: []*Address{nil, {Street: "x"}},
+			Metadata: []map[string]interface{}{
+				{"hello": "world"},
+				{"quote": `"'`},
+			},
+		}, {
+			ID:        2,
+			Addresses: []Address{},
+		}}
+		_, err := db.Model(&customers).Insert()
+		Expect(err).NotTo(HaveOccurred())
+
+		var got []Customer
+		err = db.Model(&got).Order("id").Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(HaveLen(2))
+
+		Expect(got[0].Addresses).To(Equal(customers[0].Addresses))
+		Expect(got[0].Optional).To(Equal(customers[0].Optional))
+		Expect(got[0].Metadata).To(Equal(customers[0].Metadata))
+
+		Expect(got[1].Addresses).NotTo(BeNil())
+		Expect(got[1].Addresses).To(BeEmpty())
+		Expect(got[1].Optional).To(BeNil())
+		Expect(got[1].Metadata).To(BeNil())
+	})
+})
+
+var _ = Describe("slice model", func() {
+	type value struct {
+		Id int
+	}
+
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("does not error when there are no rows", func() {
+		ints := make([]int, 1)
+		_, err := db.Query(&ints, "SELECT generate_series(1, 0)")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ints).To(BeEmpty())
+	})
+
+	It("does not error when there are no rows", func() {
+		slice := make([]value, 1)
+		_, err := db.Query(&slice, "SELECT generate_series(1, 0)")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(slice).To(BeEmpty())
 	})
 
 	It("does not error when there are no rows", func() {
@@ -660,141 +1211,1200 @@ var _ = Describe("slice model", func() {
 	})
 })
 
-var _ = Describe("read/write timeout", func() {
+var _ = Describe("ScanPositional", func() {
+	type stat struct {
+		Name  string
+		Total int
+		Ratio float64 `pg:"-"`
+		Avg   *float64
+	}
+
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("scans a row into struct fields by position", func() {
+		var dst stat
+		_, err := db.QueryOne(pg.ScanPositional(&dst), `SELECT upper('foo'), 1 + 2, NULL::float8`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dst).To(Equal(stat{Name: "FOO", Total: 3}))
+	})
+
+	It("scans rows into a slice of structs", func() {
+		var dst []*stat
+		_, err := db.Query(pg.ScanPositional(&dst), `
+			SELECT 'n' || i, i * 10, i / 2.0
+			FROM generate_series(1, 2) AS i
+		`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dst).To(HaveLen(2))
+		Expect(dst[0].Name).To(Equal("n1"))
+		Expect(dst[0].Total).To(Equal(10))
+		Expect(*dst[0].Avg).To(Equal(0.5))
+		Expect(dst[1].Name).To(Equal("n2"))
+		Expect(dst[1].Total).To(Equal(20))
+		Expect(*dst[1].Avg).To(Equal(1.0))
+	})
+
+	It("returns an error when there are too few columns", func() {
+		var dst stat
+		_, err := db.QueryOne(pg.ScanPositional(&dst), `SELECT 'foo', 1`)
+		Expect(err).To(MatchError("pg: ScanPositional got 2 columns for 3 fields of pg_test.stat"))
+	})
+
+	It("returns an error when there are too many columns", func() {
+		var dst []stat
+		_, err := db.Query(pg.ScanPositional(&dst), `SELECT 'foo', 1, 1.5, 'bar'`)
+		Expect(err).To(MatchError("pg: ScanPositional got 4 columns for 3 fields of pg_test.stat"))
+		Expect(dst).To(BeEmpty())
+	})
+
+	It("returns an error for unsupported values", func() {
+		var n int
+		_, err := db.QueryOne(pg.ScanPositional(&n), `SELECT 1`)
+		Expect(err).To(MatchError("pg: ScanPositional(unsupported *int)"))
+	})
+})
+
+var _ = Describe("read/write timeout", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		opt := pgOptions()
+		opt.ReadTimeout = time.Millisecond
+		db = pg.Connect(opt)
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("slow query timeouts", func() {
+		_, err := db.Exec(`SELECT pg_sleep(1)`)
+		Expect(err.(net.Error).Timeout()).To(BeTrue())
+	})
+
+	Context("WithTimeout", func() {
+		It("slow query passes", func() {
+			_, err := db.WithTimeout(time.Minute).Exec(`SELECT pg_sleep(1)`)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("CopyFrom/CopyTo", func() {
+	const n = 1000000
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		qs := []string{
+			"CREATE TEMP TABLE copy_src(n int)",
+			"CREATE TEMP TABLE copy_dst(n int)",
+			fmt.Sprintf("INSERT INTO copy_src SELECT generate_series(1, %d)", n),
+		}
+		for _, q := range qs {
+			_, err := db.Exec(q)
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	AfterEach(func() {
+		err := db.Close()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("copies data from a table and to a table", func() {
+		var buf bytes.Buffer
+		res, err := db.CopyTo(&buf, "COPY copy_src TO STDOUT")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(n))
+
+		res, err = db.CopyFrom(&buf, "COPY copy_dst FROM STDIN")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(n))
+
+		st := db.PoolStats()
+		Expect(st.Hits).To(Equal(uint32(4)))
+		Expect(st.Misses).To(Equal(uint32(1)))
+		Expect(st.Timeouts).To(Equal(uint32(0)))
+		Expect(st.TotalConns).To(Equal(uint32(1)))
+		Expect(st.IdleConns).To(Equal(uint32(1)))
+
+		var count int
+		_, err = db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM copy_dst")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(n))
+	})
+
+	It("returns the number of copied rows", func() {
+		buf := bytes.NewBufferString("1\n2\n3")
+		res, err := db.CopyFrom(buf, "COPY copy_dst FROM STDIN")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(3))
+
+		var out bytes.Buffer
+		res, err = db.CopyTo(&out, "COPY (SELECT * FROM copy_dst WHERE n > 1) TO STDOUT WITH CSV")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(2))
+		Expect(out.String()).To(Equal("2\n3\n"))
+
+		res, err = db.CopyFrom(&out, "COPY copy_dst FROM STDIN WITH CSV")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(2))
+	})
+
+	It("copies corrupted data to a table", func() {
+		buf := bytes.NewBufferString("corrupted,data\nrow,two\r\nrow three")
+		res, err := db.CopyFrom(buf, "COPY copy_dst FROM STDIN WITH FORMAT csv")
+		Expect(err).To(MatchError(`ERROR #42601 syntax error at or near "FORMAT"`))
+		Expect(res).To(BeNil())
+
+		st := db.Pool().Stats()
+		Expect(st.Hits).To(Equal(uint32(3)))
+		Expect(st.Misses).To(Equal(uint32(1)))
+		Expect(st.Timeouts).To(Equal(uint32(0)))
+		Expect(st.TotalConns).To(Equal(uint32(1)))
+		Expect(st.IdleConns).To(Equal(uint32(1)))
+
+		var count int
+		_, err = db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM copy_dst")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(0))
+	})
+})
+
+var _ = Describe("ImportCSV/ImportJSONLines", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		_, err := db.Exec("CREATE TEMP TABLE import_dst(id int, name text, tags jsonb)")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := db.Close()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("imports CSV with a header", func() {
+		r := strings.NewReader("ID,Name\n1,\"hello, world\"\n2,\n")
+		res, err := db.ImportCSV(ctx, "import_dst", r, &pg.ImportOptions{
+			Header:    true,
+			ColumnMap: map[string]string{"ID": "id", "Name": "name"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(2))
+
+		var names []*string
+		_, err = db.Query(&names, "SELECT name FROM import_dst ORDER BY id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(HaveLen(2))
+		Expect(*names[0]).To(Equal("hello, world"))
+		Expect(names[1]).To(BeNil())
+	})
+
+	It("imports JSON lines", func() {
+		r := strings.NewReader(`{"id": 1, "name": "a", "tags": ["x", "y"]}` + "\n" + `{"id": 2}` + "\n")
+		res, err := db.ImportJSONLines(ctx, "import_dst", r, &pg.ImportOptions{
+			Columns: []string{"id", "name", "tags"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(2))
+
+		var tags []string
+		_, err = db.QueryOne(pg.Scan(pg.Array(&tags)),
+			"SELECT array(SELECT jsonb_array_elements_text(tags)) FROM import_dst WHERE id = 1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tags).To(Equal([]string{"x", "y"}))
+	})
+
+	It("aborts the import on a malformed line", func() {
+		r := strings.NewReader(`{"id": 1}` + "\n" + `{"id": 2` + "\n")
+		_, err := db.ImportJSONLines(ctx, "import_dst", r, &pg.ImportOptions{
+			Columns: []string{"id"},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*pg.ImportError).Line).To(Equal(2))
+
+		st := db.Pool().Stats()
+		Expect(st.TotalConns).To(Equal(uint32(1)))
+		Expect(st.IdleConns).To(Equal(uint32(1)))
+
+		var count int
+		_, err = db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM import_dst")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(0))
+	})
+})
+
+var _ = Describe("CopyToQuery", func() {
+	type CopyModel struct {
+		tableName struct{} `pg:"copy_models"`
+
+		ID        int
+		Name      string
+		Price     float64
+		Tags      []byte
+		CreatedAt time.Time
+	}
+
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		err := db.Model((*CopyModel)(nil)).CreateTable(&orm.CreateTableOptions{
+			Temp: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = db.Exec(`
+			INSERT INTO copy_models (id, name, price, tags, created_at)
+			SELECT n, 'name ' || n, n * 1.5, NULL, '2020-01-01 00:00:00+00'
+			FROM generate_series(1, 1000) AS n`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("copies selected rows into the model", func() {
+		var models []CopyModel
+		q := db.Model((*CopyModel)(nil)).Where("id <= ?", 100).Order("id")
+		res, err := db.CopyToQuery(context.Background(), &models, q)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(100))
+		Expect(res.RowsReturned()).To(Equal(100))
+
+		Expect(models).To(HaveLen(100))
+		Expect(models[9].ID).To(Equal(10))
+		Expect(models[9].Name).To(Equal("name 10"))
+		Expect(models[9].Price).To(Equal(15.0))
+		Expect(models[9].Tags).To(BeNil())
+		Expect(models[9].CreatedAt.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))).To(BeTrue())
+	})
+
+	It("returns an error for unsupported column types", func() {
+		var models []CopyModel
+		q := db.Model((*CopyModel)(nil)).ColumnExpr("ARRAY[id] AS ids")
+		_, err := db.CopyToQuery(context.Background(), &models, q)
+		Expect(err).To(MatchError(`pg: CopyToQuery does not support column="ids" with type oid=1007`))
+
+		var n int
+		_, err = db.QueryOne(pg.Scan(&n), "SELECT 1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+	})
+
+	It("returns an error for bind parameters", func() {
+		var models []CopyModel
+		q := db.Model((*CopyModel)(nil)).Where("id = $1")
+		_, err := db.CopyToQuery(context.Background(), &models, q)
+		Expect(err).To(MatchError("pg: CopyToQuery does not support bind parameters like $1"))
+	})
+})
+
+var _ = Describe("CountEstimate", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("works", func() {
+		count, err := db.Model().
+			TableExpr("generate_series(1, 10)").
+			CountEstimate(1000)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(10))
+	})
+
+	It("works when there are no results", func() {
+		count, err := db.Model().
+			TableExpr("generate_series(1, 0)").
+			CountEstimate(1000)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(0))
+	})
+
+	It("works with GROUP", func() {
+		count, err := db.Model().
+			TableExpr("generate_series(1, 10)").
+			Group("generate_series").
+			CountEstimate(1000)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(10))
+	})
+
+	It("works with GROUP when there are no results", func() {
+		count, err := db.Model().
+			TableExpr("generate_series(1, 0)").
+			Group("generate_series").
+			CountEstimate(1000)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(0))
+	})
+})
+
+var _ = Describe("aggregates into struct", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	type Stat struct {
+		tableName struct{} `pg:",loose_columns"`
+
+		Category   string
+		Count      int
+		AvgPrice   float64
+		TotalPrice int
+	}
+
+	It("maps aliases to fields", func() {
+		var stats []Stat
+		err := db.Model().
+			TableExpr("(VALUES ('a', 10), ('a', 20), ('b', 5)) AS products (category, price)").
+			ColumnExpr("category").
+			ColumnExpr("count(*)").
+			ColumnExpr("avg(price) AS avgPrice").
+			ColumnExpr(`sum(price) AS "TotalPrice"`).
+			Group("category").
+			Order("category").
+			Select(&stats)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats).To(Equal([]Stat{
+			{Category: "a", Count: 2, AvgPrice: 15, TotalPrice: 30},
+			{Category: "b", Count: 1, AvgPrice: 5, TotalPrice: 5},
+		}))
+	})
+})
+
+var _ = Describe("GenerateSeries", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("generates a daily date spine", func() {
+		start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+		stop := start.AddDate(0, 0, 2)
+
+		var days []time.Time
+		err := db.Model().
+			ColumnExpr("day").
+			TableExpr("?", pg.GenerateSeries(start, stop, 24*time.Hour).As("day")).
+			Order("day").
+			Select(&days)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(days).To(HaveLen(3))
+		for i, day := range days {
+			Expect(day.Equal(start.AddDate(0, 0, i))).To(BeTrue())
+		}
+	})
+
+	It("fills gaps in a joined integer series", func() {
+		var counts []int
+		err := db.Model().
+			ColumnExpr("count(t.n)").
+			TableExpr("?", pg.GenerateSeries(1, 4, nil).As("n")).
+			Join("LEFT JOIN (VALUES (1), (1), (3)) AS t (n) ON t.n = n.n").
+			Group("n.n").
+			Order("n.n").
+			Select(&counts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counts).To(Equal([]int{2, 0, 1, 0}))
+	})
+})
+
+var _ = Describe("Unnest", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("scans elements with their positions", func() {
+		type tagPos struct {
+			Tag        string
+			Ordinality int
+		}
+
+		var tags []tagPos
+		err := db.Model().
+			ColumnExpr("tag, ordinality").
+			TableExpr("?", pg.Unnest([]string{"c", "a", "b"}).As("tag").WithOrdinality()).
+			Order("ordinality").
+			Select(&tags)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tags).To(Equal([]tagPos{
+			{Tag: "c", Ordinality: 1},
+			{Tag: "a", Ordinality: 2},
+			{Tag: "b", Ordinality: 3},
+		}))
+	})
+
+	It("preserves array order in joins", func() {
+		var ids []int
+		err := db.Model().
+			ColumnExpr("n.n").
+			TableExpr("?", pg.GenerateSeries(1, 5, nil).As("n")).
+			Join("JOIN ? ON ids.ids = n.n", pg.Unnest([]int{4, 2, 5}).As("ids").WithOrdinality()).
+			Order("ids.ordinality").
+			Select(&ids)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]int{4, 2, 5}))
+	})
+})
+var _ = Describe("Hstore", func() {
+	type HstoreItem struct {
+		ID    int
+		Attrs map[string]*string `pg:",hstore"`
+	}
+
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		_, err := db.Exec("CREATE EXTENSION IF NOT EXISTS hstore")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = db.Model((*HstoreItem)(nil)).CreateTable(&orm.CreateTableOptions{
+			Temp: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		red := "red"
+		_, err = db.Model(&[]HstoreItem{
+			{ID: 1, Attrs: map[string]*string{"color": &red, "size": nil}},
+			{ID: 2, Attrs: map[string]*string{"color": &red}},
+		}).Insert()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("distinguishes NULL values from missing keys", func() {
+		item := new(HstoreItem)
+		err := db.Model(item).Where("id = 1").Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(item.Attrs).To(HaveLen(2))
+		Expect(*item.Attrs["color"]).To(Equal("red"))
+		Expect(item.Attrs).To(HaveKey("size"))
+		Expect(item.Attrs["size"]).To(BeNil())
+	})
+
+	It("supports hstore operators", func() {
+		var ids []int
+		err := db.Model((*HstoreItem)(nil)).
+			Column("id").
+			WhereHstoreHasKey("attrs", "size").
+			WhereHstoreContains("attrs", map[string]string{"color": "red"}).
+			Select(&ids)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]int{1}))
+
+		var colors []string
+		err = db.Model((*HstoreItem)(nil)).
+			ColumnExpr("?", pg.HstoreGet("attrs", "color")).
+			Order("id").
+			Select(&colors)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(colors).To(Equal([]string{"red", "red"}))
+	})
+})
+
+var _ = Describe("AddColumnsIfMissing", func() {
+	type AddColumnsBook struct {
+		ID    int
+		Title string
+		Isbn  string
+		Pages int `pg:",notnull,default:0"`
+	}
+
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		_, err := db.Exec("DROP TABLE IF EXISTS add_columns_books")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.Exec("CREATE TABLE add_columns_books (id bigserial PRIMARY KEY, title varchar(10))")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.Exec("INSERT INTO add_columns_books (title) VALUES ('book 1')")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_, err := db.Exec("DROP TABLE add_columns_books")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("adds only missing columns", func() {
+		columns, err := db.Model((*AddColumnsBook)(nil)).AddColumnsIfMissing()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(columns).To(Equal([]string{"isbn", "pages"}))
+
+		columns, err = db.Model((*AddColumnsBook)(nil)).AddColumnsIfMissing()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(columns).To(BeEmpty())
+
+		table, err := db.Inspect(ctx, "add_columns_books")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(table.Columns).To(HaveLen(4))
+		Expect(table.Column("title").DataType).To(Equal("character varying(10)"))
+		Expect(table.Column("isbn").DataType).To(Equal("text"))
+		Expect(table.Column("pages").IsNullable).To(BeFalse())
+
+		book := new(AddColumnsBook)
+		err = db.Model(book).Where("id = 1").Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(book).To(Equal(&AddColumnsBook{ID: 1, Title: "book 1"}))
+	})
+
+	It("returns an error when the table does not exist", func() {
+		_, err := db.Exec("DROP TABLE add_columns_books")
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			_, err := db.Exec("CREATE TABLE add_columns_books (id bigserial)")
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		_, err = db.Model((*AddColumnsBook)(nil)).AddColumnsIfMissing()
+		Expect(err).To(MatchError(`ERROR #42P01 relation "add_columns_books" does not exist`))
+	})
+})
+
+var _ = Describe("Merge", func() {
+	type MergeItem struct {
+		ID    int
+		Value string
+	}
+
+	var db *pg.DB
+	var version int
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		err := db.Model((*MergeItem)(nil)).CreateTable(&orm.CreateTableOptions{
+			Temp: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = db.Model(&[]MergeItem{{ID: 1, Value: "a"}, {ID: 2, Value: "b"}}).Insert()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = db.QueryOne(pg.Scan(&version), "SHOW server_version_num")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("upserts and deletes rows", func() {
+		rows := []MergeItem{{ID: 1, Value: ""}, {ID: 2, Value: "B"}, {ID: 3, Value: "c"}}
+		_, err := db.Model((*MergeItem)(nil)).
+			MergeUsing(pg.Values(rows), "s", "merge_item.id = s.id").
+			WhenMatched("AND s.value = '' THEN DELETE").
+			WhenMatched("THEN UPDATE SET value = s.value").
+			WhenNotMatched("THEN INSERT (id, value) VALUES (s.id, s.value)").
+			Merge()
+		if version < 150000 {
+			Expect(err.Error()).To(HavePrefix("pg: MERGE requires PostgreSQL 15 or later: "))
+			_, ok := err.(interface{ Unwrap() error }).Unwrap().(pg.Error)
+			Expect(ok).To(BeTrue())
+			return
+		}
+		Expect(err).NotTo(HaveOccurred())
+
+		var items []MergeItem
+		err = db.Model(&items).Order("id").Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items).To(Equal([]MergeItem{{ID: 2, Value: "B"}, {ID: 3, Value: "c"}}))
+	})
+})
+
+var _ = Describe("ReplicaAddrs", func() {
+	It("routes reads to the replica", func() {
+		opt := pgOptions()
+		opt.ReplicaAddrs = []string{"localhost:5432"}
+		db := pg.Connect(opt)
+		defer db.Close()
+
+		var inRecovery bool
+		err := db.Model().ColumnExpr("pg_is_in_recovery()").Select(pg.Scan(&inRecovery))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inRecovery).To(BeFalse())
+
+		var n int
+		_, err = db.Replica().QueryOne(pg.Scan(&n), "SELECT 1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+
+		err = db.Model().ColumnExpr("1").UsePrimary().Select(pg.Scan(&n))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+	})
+
+	It("falls back to the primary when the replica is down", func() {
+		opt := pgOptions()
+		opt.ReplicaAddrs = []string{"localhost:1"}
+		opt.ReplicaHealthCheckFrequency = -1
+		opt.DialTimeout = time.Second
+		db := pg.Connect(opt)
+		defer db.Close()
+
+		var n int
+		err := db.Model().ColumnExpr("1").Select(pg.Scan(&n))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+
+		Expect(db.Replica()).To(BeIdenticalTo(db))
+	})
+})
+
+var _ = Describe("MaxRows", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		opt := pgOptions()
+		opt.MaxRows = 2
+		opt.PoolSize = 1
+		db = pg.Connect(opt)
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("returns ErrTooManyRows when Select returns more rows", func() {
+		var ids []int
+		err := db.Model().
+			TableExpr("generate_series(1, 1000) AS id").
+			Column("id").
+			Select(&ids)
+		Expect(err).To(Equal(pg.ErrTooManyRows))
+		Expect(ids).To(Equal([]int{1, 2}))
+
+		// The connection is still usable.
+		var n int
+		_, err = db.QueryOne(pg.Scan(&n), "SELECT 1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+	})
+
+	It("is overridden by Query.MaxRows", func() {
+		var ids []int
+		err := db.Model().
+			TableExpr("generate_series(1, 3) AS id").
+			Column("id").
+			MaxRows(0).
+			Select(&ids)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(HaveLen(3))
+
+		err = db.Model().
+			TableExpr("generate_series(1, 3) AS id").
+			Column("id").
+			MaxRows(1).
+			Select(&ids)
+		Expect(err).To(Equal(pg.ErrTooManyRows))
+	})
+
+	It("does not apply to raw queries", func() {
+		var ids []int
+		_, err := db.Query(&ids, "SELECT generate_series(1, 3)")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(HaveLen(3))
+	})
+})
+
+type IdentityBook struct {
+	ID    int64 `pg:",identity:always"`
+	Title string
+}
+
+var _ = Describe("OverridingSystemValue", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		err := db.Model((*IdentityBook)(nil)).DropTable(&orm.DropTableOptions{IfExists: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = db.Model((*IdentityBook)(nil)).CreateTable(nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := db.Model((*IdentityBook)(nil)).DropTable(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("imports rows with explicit identity values", func() {
+		books := []IdentityBook{{ID: 10, Title: "book 10"}, {ID: 20, Title: "book 20"}}
+
+		_, err := db.Model(&books).Insert()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(pg.Error).Field('C')).To(Equal("428C9")) // generated_always
+
+		_, err = db.Model(&books).OverridingSystemValue().Insert()
+		Expect(err).NotTo(HaveOccurred())
+
+		book := &IdentityBook{Title: "generated"}
+		_, err = db.Model(book).Insert()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(book.ID).To(Equal(int64(1)))
+
+		var ids []int64
+		err = db.Model((*IdentityBook)(nil)).Column("id").Order("id").Select(&ids)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]int64{1, 10, 20}))
+	})
+})
+
+type Calendar struct {
+	ID     int
+	During string           `pg:"type:daterange"`
+	Events []*CalendarEvent `pg:"rel:has-many,join:calendar_event.during && parent.during"`
+}
+
+type CalendarEvent struct {
+	ID     int
+	Title  string
+	During string `pg:"type:daterange"`
+}
+
+var _ = Describe("has many with join condition", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		for _, model := range []interface{}{(*Calendar)(nil), (*CalendarEvent)(nil)} {
+			err := db.Model(model).DropTable(&orm.DropTableOptions{IfExists: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = db.Model(model).CreateTable(nil)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		calendars := []Calendar{
+			{ID: 1, During: "[2020-01-01,2020-02-01)"},
+			{ID: 2, During: "[2020-02-01,2020-03-01)"},
+			{ID: 3, During: "[2020-04-01,2020-05-01)"},
+		}
+		_, err := db.Model(&calendars).Insert()
+		Expect(err).NotTo(HaveOccurred())
+
+		events := []CalendarEvent{
+			{ID: 1, Title: "january", During: "[2020-01-10,2020-01-12)"},
+			{ID: 2, Title: "new month", During: "[2020-01-30,2020-02-03)"},
+			{ID: 3, Title: "march", During: "[2020-03-05,2020-03-06)"},
+		}
+		_, err = db.Model(&events).Insert()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		for _, model := range []interface{}{(*Calendar)(nil), (*CalendarEvent)(nil)} {
+			err := db.Model(model).DropTable(nil)
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("loads overlapping rows", func() {
+		var calendars []Calendar
+		err := db.Model(&calendars).
+			Relation("Events", func(q *orm.Query) (*orm.Query, error) {
+				return q.Order("calendar_event.id"), nil
+			}).
+			Order("id").
+			Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calendars).To(HaveLen(3))
+
+		titles := func(events []*CalendarEvent) []string {
+			var ss []string
+			for _, e := range events {
+				ss = append(ss, e.Title)
+			}
+			return ss
+		}
+		Expect(titles(calendars[0].Events)).To(Equal([]string{"january", "new month"}))
+		Expect(titles(calendars[1].Events)).To(Equal([]string{"new month"}))
+		Expect(calendars[2].Events).To(BeEmpty())
+		Expect(calendars[0].Events[1]).NotTo(BeIdenticalTo(calendars[1].Events[0]))
+	})
+
+	It("limits overlapping rows per parent", func() {
+		calendar := &Calendar{ID: 1}
+		err := db.Model(calendar).
+			Relation("Events", func(q *orm.Query) (*orm.Query, error) {
+				return q.Order("calendar_event.id DESC").Limit(1), nil
+			}).
+			WherePK().
+			Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calendar.Events).To(HaveLen(1))
+		Expect(calendar.Events[0].Title).To(Equal("new month"))
+	})
+})
+
+type TenantDoc struct {
+	TenantID int `pg:",pk"`
+	ID       int `pg:",pk"`
+	Title    string
+}
+
+var _ = Describe("composite primary key", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		err := db.Model((*TenantDoc)(nil)).DropTable(&orm.DropTableOptions{IfExists: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = db.Model((*TenantDoc)(nil)).CreateTable(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		docs := []TenantDoc{
+			{TenantID: 1, ID: 1, Title: "1/1"},
+			{TenantID: 1, ID: 2, Title: "1/2"},
+			{TenantID: 2, ID: 1, Title: "2/1"},
+		}
+		_, err = db.Model(&docs).Insert()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := db.Model((*TenantDoc)(nil)).DropTable(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("selects, updates, and deletes by all primary key columns", func() {
+		doc := &TenantDoc{TenantID: 2, ID: 1}
+		err := db.Model(doc).WherePK().Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Title).To(Equal("2/1"))
+
+		doc.Title = "updated"
+		res, err := db.Model(doc).WherePK().Update()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(1))
+
+		docs := []TenantDoc{{TenantID: 1, ID: 2}, {TenantID: 2, ID: 1}}
+		err = db.Model(&docs).WherePK().Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(docs[0].Title).To(Equal("1/2"))
+		Expect(docs[1].Title).To(Equal("updated"))
+
+		res, err = db.Model(&docs).WherePK().Delete()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(2))
+
+		var left []TenantDoc
+		err = db.Model(&left).Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(left).To(Equal([]TenantDoc{{TenantID: 1, ID: 1, Title: "1/1"}}))
+	})
+})
+
+type PlaylistItem struct {
+	ID       int
+	Position int `pg:"unique:position:deferrable"`
+}
+
+var _ = Describe("deferrable unique constraint", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		err := db.Model((*PlaylistItem)(nil)).DropTable(&orm.DropTableOptions{IfExists: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = db.Model((*PlaylistItem)(nil)).CreateTable(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		items := []PlaylistItem{{ID: 1, Position: 1}, {ID: 2, Position: 2}}
+		_, err = db.Model(&items).Insert()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := db.Model((*PlaylistItem)(nil)).DropTable(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	swap := func(tx *pg.Tx) error {
+		if _, err := tx.Model((*PlaylistItem)(nil)).
+			Set("position = 2").Where("id = 1").Update(); err != nil {
+			return err
+		}
+		_, err := tx.Model((*PlaylistItem)(nil)).
+			Set("position = 1").Where("id = 2").Update()
+		return err
+	}
+
+	It("is checked immediately by default", func() {
+		err := db.RunInTransaction(ctx, swap)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(pg.Error).IntegrityViolation()).To(BeTrue())
+	})
+
+	It("swaps values when the constraint is deferred", func() {
+		err := db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+			if err := tx.SetConstraints(ctx, "DEFERRED", "playlist_items_position_key"); err != nil {
+				return err
+			}
+			return swap(tx)
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		var items []PlaylistItem
+		err = db.Model(&items).Order("id").Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items).To(Equal([]PlaylistItem{{ID: 1, Position: 2}, {ID: 2, Position: 1}}))
+	})
+})
+
+type FKParent struct {
+	ID int
+}
+
+type FKChild struct {
+	ID         int
+	FKParentID int       `pg:"fk_parent_id,on_delete:RESTRICT"`
+	FKParent   *FKParent `pg:"rel:has-one,on_delete:cascade,on_update:SET NULL"`
+	OtherID    int       `pg:"other_id,on_update:CASCADE"`
+	Other      *FKParent `pg:"rel:has-one,fk:other_id"`
+}
+
+var _ = Describe("FKConstraints", func() {
 	var db *pg.DB
 
 	BeforeEach(func() {
-		opt := pgOptions()
-		opt.ReadTimeout = time.Millisecond
-		db = pg.Connect(opt)
+		db = pg.Connect(pgOptions())
+
+		_, err := db.Exec("DROP TABLE IF EXISTS fk_children, fk_parents")
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, model := range []interface{}{(*FKParent)(nil), (*FKChild)(nil)} {
+			err := db.Model(model).CreateTable(&orm.CreateTableOptions{
+				FKConstraints: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
 	})
 
 	AfterEach(func() {
+		_, err := db.Exec("DROP TABLE IF EXISTS fk_children, fk_parents")
+		Expect(err).NotTo(HaveOccurred())
 		Expect(db.Close()).NotTo(HaveOccurred())
 	})
 
-	It("slow query timeouts", func() {
-		_, err := db.Exec(`SELECT pg_sleep(1)`)
-		Expect(err.(net.Error).Timeout()).To(BeTrue())
-	})
+	It("creates referential actions from relation and foreign key tags", func() {
+		var rules []struct {
+			ColumnName string
+			DeleteRule string
+			UpdateRule string
+		}
+		_, err := db.Query(&rules, `
+			SELECT kcu.column_name, rc.delete_rule, rc.update_rule
+			FROM information_schema.referential_constraints AS rc
+			JOIN information_schema.key_column_usage AS kcu
+				ON kcu.constraint_name = rc.constraint_name
+				AND kcu.constraint_schema = rc.constraint_schema
+			WHERE kcu.table_name = 'fk_children'
+			ORDER BY kcu.column_name
+		`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(HaveLen(2))
 
-	Context("WithTimeout", func() {
-		It("slow query passes", func() {
-			_, err := db.WithTimeout(time.Minute).Exec(`SELECT pg_sleep(1)`)
-			Expect(err).NotTo(HaveOccurred())
-		})
+		Expect(rules[0].ColumnName).To(Equal("fk_parent_id"))
+		Expect(rules[0].DeleteRule).To(Equal("CASCADE"))
+		Expect(rules[0].UpdateRule).To(Equal("SET NULL"))
+
+		Expect(rules[1].ColumnName).To(Equal("other_id"))
+		Expect(rules[1].DeleteRule).To(Equal("NO ACTION"))
+		Expect(rules[1].UpdateRule).To(Equal("CASCADE"))
 	})
 })
 
-var _ = Describe("CopyFrom/CopyTo", func() {
-	const n = 1000000
+var _ = Describe("uuid", func() {
+	type UUIDModel struct {
+		tableName struct{} `pg:"uuid_models"`
+
+		ID    string   `pg:",uuid,generate"`
+		Ref   [16]byte `pg:",uuid,generate"`
+		Value string
+	}
+
 	var db *pg.DB
 
 	BeforeEach(func() {
 		db = pg.Connect(pgOptions())
 
-		qs := []string{
-			"CREATE TEMP TABLE copy_src(n int)",
-			"CREATE TEMP TABLE copy_dst(n int)",
-			fmt.Sprintf("INSERT INTO copy_src SELECT generate_series(1, %d)", n),
-		}
-		for _, q := range qs {
-			_, err := db.Exec(q)
-			Expect(err).NotTo(HaveOccurred())
-		}
+		err := db.Model((*UUIDModel)(nil)).CreateTable(&orm.CreateTableOptions{
+			Temp: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
 	})
 
 	AfterEach(func() {
-		err := db.Close()
-		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Close()).NotTo(HaveOccurred())
 	})
 
-	It("copies data from a table and to a table", func() {
-		var buf bytes.Buffer
-		res, err := db.CopyTo(&buf, "COPY copy_src TO STDOUT")
+	It("generates uuids on insert", func() {
+		models := []UUIDModel{
+			{Value: "generated"},
+			{ID: "123e4567-e89b-12d3-a456-426614174000", Value: "explicit"},
+		}
+		_, err := db.Model(&models).Insert()
 		Expect(err).NotTo(HaveOccurred())
-		Expect(res.RowsAffected()).To(Equal(n))
+		Expect(models[0].ID).NotTo(BeEmpty())
+		Expect(models[0].Ref).NotTo(Equal([16]byte{}))
+		Expect(models[1].ID).To(Equal("123e4567-e89b-12d3-a456-426614174000"))
 
-		res, err = db.CopyFrom(&buf, "COPY copy_dst FROM STDIN")
+		var version int
+		_, err = db.QueryOne(pg.Scan(&version),
+			"SELECT substr(id::text, 15, 1)::int FROM uuid_models WHERE value = 'generated'")
 		Expect(err).NotTo(HaveOccurred())
-		Expect(res.RowsAffected()).To(Equal(n))
-
-		st := db.PoolStats()
-		Expect(st.Hits).To(Equal(uint32(4)))
-		Expect(st.Misses).To(Equal(uint32(1)))
-		Expect(st.Timeouts).To(Equal(uint32(0)))
-		Expect(st.TotalConns).To(Equal(uint32(1)))
-		Expect(st.IdleConns).To(Equal(uint32(1)))
+		Expect(version).To(Equal(4))
 
-		var count int
-		_, err = db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM copy_dst")
+		got := &UUIDModel{ID: models[0].ID}
+		err = db.Model(got).WherePK().Select()
 		Expect(err).NotTo(HaveOccurred())
-		Expect(count).To(Equal(n))
+		Expect(*got).To(Equal(models[0]))
 	})
+})
 
-	It("copies corrupted data to a table", func() {
-		buf := bytes.NewBufferString("corrupted,data\nrow,two\r\nrow three")
-		res, err := db.CopyFrom(buf, "COPY copy_dst FROM STDIN WITH FORMAT csv")
-		Expect(err).To(MatchError(`ERROR #42601 syntax error at or near "FORMAT"`))
-		Expect(res).To(BeNil())
-
-		st := db.Pool().Stats()
-		Expect(st.Hits).To(Equal(uint32(3)))
-		Expect(st.Misses).To(Equal(uint32(1)))
-		Expect(st.Timeouts).To(Equal(uint32(0)))
-		Expect(st.TotalConns).To(Equal(uint32(1)))
-		Expect(st.IdleConns).To(Equal(uint32(1)))
+var _ = Describe("bool_type", func() {
+	type LegacyFlags struct {
+		tableName struct{} `pg:"legacy_flags"`
 
-		var count int
-		_, err = db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM copy_dst")
-		Expect(err).NotTo(HaveOccurred())
-		Expect(count).To(Equal(0))
-	})
-})
+		ID      int
+		Active  bool  `pg:",bool_type:char"`
+		Enabled bool  `pg:",bool_type:smallint"`
+		Visible *bool `pg:",bool_type:int"`
+	}
 
-var _ = Describe("CountEstimate", func() {
 	var db *pg.DB
 
 	BeforeEach(func() {
 		db = pg.Connect(pgOptions())
+
+		err := db.Model((*LegacyFlags)(nil)).CreateTable(&orm.CreateTableOptions{
+			Temp: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
 	})
 
 	AfterEach(func() {
 		Expect(db.Close()).NotTo(HaveOccurred())
 	})
 
-	It("works", func() {
-		count, err := db.Model().
-			TableExpr("generate_series(1, 10)").
-			CountEstimate(1000)
+	It("stores bools using the column representation", func() {
+		yes := true
+		flags := []LegacyFlags{
+			{ID: 1, Active: true, Enabled: true, Visible: &yes},
+			{ID: 2},
+		}
+		_, err := db.Model(&flags).Insert()
 		Expect(err).NotTo(HaveOccurred())
-		Expect(count).To(Equal(10))
-	})
 
-	It("works when there are no results", func() {
-		count, err := db.Model().
-			TableExpr("generate_series(1, 0)").
-			CountEstimate(1000)
+		var active string
+		var enabled, visible *int
+		_, err = db.QueryOne(pg.Scan(&active, &enabled, &visible),
+			"SELECT active, enabled, visible FROM legacy_flags WHERE id = 1")
 		Expect(err).NotTo(HaveOccurred())
-		Expect(count).To(Equal(0))
-	})
+		Expect(active).To(Equal("Y"))
+		Expect(*enabled).To(Equal(1))
+		Expect(*visible).To(Equal(1))
 
-	It("works with GROUP", func() {
-		count, err := db.Model().
-			TableExpr("generate_series(1, 10)").
-			Group("generate_series").
-			CountEstimate(1000)
+		_, err = db.QueryOne(pg.Scan(&active, &enabled, &visible),
+			"SELECT active, enabled, visible FROM legacy_flags WHERE id = 2")
 		Expect(err).NotTo(HaveOccurred())
-		Expect(count).To(Equal(10))
+		Expect(active).To(Equal("N"))
+		Expect(*enabled).To(Equal(0))
+		Expect(visible).To(BeNil())
+
+		var got []LegacyFlags
+		err = db.Model(&got).Order("id").Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(flags))
 	})
 
-	It("works with GROUP when there are no results", func() {
-		count, err := db.Model().
-			TableExpr("generate_series(1, 0)").
-			Group("generate_series").
-			CountEstimate(1000)
+	It("uses the column representation in filters", func() {
+		_, err := db.Exec(`INSERT INTO legacy_flags VALUES (1, 'Y', 0, 1), (2, 'N', 1, 0)`)
 		Expect(err).NotTo(HaveOccurred())
-		Expect(count).To(Equal(0))
+
+		filter := &LegacyFlags{Active: true, Enabled: true}
+
+		var ids []int
+		err = db.Model(filter).
+			Column("id").
+			Where("active = ?active").
+			Select(&ids)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]int{1}))
+
+		err = db.Model(filter).
+			Column("id").
+			Where("enabled = ?enabled").
+			Select(&ids)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]int{2}))
+
+		flag := &LegacyFlags{ID: 2, Active: true}
+		_, err = db.Model(flag).Column("active").WherePK().Update()
+		Expect(err).NotTo(HaveOccurred())
+
+		var active string
+		_, err = db.QueryOne(pg.Scan(&active), "SELECT active FROM legacy_flags WHERE id = 2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(active).To(Equal("Y"))
 	})
 })
 
@@ -854,6 +2464,62 @@ var _ = Describe("DB nulls", func() {
 		})
 	})
 
+	Context("IS DISTINCT FROM", func() {
+		type Test struct {
+			ID    int
+			Value *int
+		}
+
+		BeforeEach(func() {
+			two := 2
+			_, err := db.Model(&[]Test{{ID: 1}, {ID: 2, Value: &two}}).Insert()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("compares NULL values", func() {
+			var ids []int
+			err := db.Model((*Test)(nil)).
+				Column("id").
+				WhereDistinctFrom("value", nil).
+				Select(&ids)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids).To(Equal([]int{2}))
+
+			ids = nil
+			err = db.Model((*Test)(nil)).
+				Column("id").
+				WhereNotDistinctFrom("value", nil).
+				Select(&ids)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids).To(Equal([]int{1}))
+
+			ids = nil
+			err = db.Model((*Test)(nil)).
+				Column("id").
+				WhereDistinctFrom("value", 2).
+				Select(&ids)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids).To(Equal([]int{1}))
+		})
+	})
+
+	Describe("default_on_null", func() {
+		type Test struct {
+			ID    int
+			Value int `pg:",default_on_null:-1"`
+		}
+
+		It("scans the default for NULL values", func() {
+			_, err := db.Exec("INSERT INTO tests VALUES (1, NULL), (2, 2)")
+			Expect(err).NotTo(HaveOccurred())
+
+			var tests []Test
+			err = db.Model(&tests).Order("id").Select()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tests).To(Equal([]Test{{ID: 1, Value: -1}, {ID: 2, Value: 2}}))
+		})
+	})
+
 	Context("nil ptr", func() {
 		type Test struct {
 			ID    int
@@ -921,6 +2587,49 @@ var _ = Describe("DB.Select", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("streams bytea", func() {
+		_, err := db.Exec(`CREATE TEMP TABLE tests (col bytea)`)
+		Expect(err).NotTo(HaveOccurred())
+
+		data := bytes.Repeat([]byte("bytes"), 1<<20)
+		_, err = db.Exec(`INSERT INTO tests VALUES (?)`, pg.ByteaReader(bytes.NewReader(data)))
+		Expect(err).NotTo(HaveOccurred())
+
+		var buf bytes.Buffer
+		err = db.Model().Table("tests").Column("col").Select(pg.Scan(pg.ByteaWriter(&buf)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf.Bytes()).To(Equal(data))
+	})
+
+	It("skips rows that can't be scanned with SelectLenient", func() {
+		type LenientItem struct {
+			ID int
+		}
+
+		qs := []string{
+			`DROP TABLE IF EXISTS lenient_items`,
+			`CREATE TABLE lenient_items (id text)`,
+			`INSERT INTO lenient_items VALUES ('1'), ('foo'), ('3')`,
+		}
+		for _, q := range qs {
+			_, err := db.Exec(q)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		defer db.Exec(`DROP TABLE lenient_items`)
+
+		var items []LenientItem
+		rowErrs, err := db.Model(&items).Order("id").SelectLenient()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items).To(Equal([]LenientItem{{ID: 1}, {ID: 3}}))
+		Expect(rowErrs).To(HaveLen(1))
+		Expect(rowErrs[0].Row).To(Equal(2))
+
+		var n int
+		_, err = db.QueryOne(pg.Scan(&n), "SELECT 1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+	})
+
 	It("selects into embedded struct pointer", func() {
 		type One struct {
 			ID int
@@ -975,6 +2684,45 @@ var _ = Describe("DB.Insert", func() {
 		_, err := db.Model(&v).Insert()
 		Expect(err).To(MatchError("pg: Model(unsupported *int)"))
 	})
+
+	It("upserts using a partial unique index", func() {
+		type PartialUser struct {
+			ID        int
+			Email     string
+			Name      string
+			DeletedAt time.Time
+		}
+
+		qs := []string{
+			"DROP TABLE IF EXISTS partial_users",
+			"CREATE TABLE partial_users (id serial PRIMARY KEY, email text, name text, deleted_at timestamptz)",
+			"CREATE UNIQUE INDEX partial_users_email_idx ON partial_users (email) WHERE deleted_at IS NULL",
+			"INSERT INTO partial_users (email, name, deleted_at) VALUES ('a@example.com', 'deleted', now())",
+			"INSERT INTO partial_users (email, name) VALUES ('a@example.com', 'old')",
+		}
+		for _, q := range qs {
+			_, err := db.Exec(q)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		user := &PartialUser{Email: "a@example.com", Name: "new"}
+		_, err := db.Model(user).
+			Column("email", "name").
+			OnConflictColumns("email").
+			OnConflictWhere("deleted_at IS NULL").
+			OnConflict("DO UPDATE").
+			Set("name = EXCLUDED.name").
+			Insert()
+		Expect(err).NotTo(HaveOccurred())
+
+		var names []string
+		_, err = db.Query(&names, "SELECT name FROM partial_users ORDER BY id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"deleted", "new"}))
+
+		_, err = db.Exec("DROP TABLE partial_users")
+		Expect(err).NotTo(HaveOccurred())
+	})
 })
 
 var _ = Describe("DB.Update", func() {
@@ -999,6 +2747,82 @@ var _ = Describe("DB.Update", func() {
 		_, err := db.Model(&test).WherePK().Update()
 		Expect(err).To(MatchError(`pg: model=Test does not have primary keys`))
 	})
+
+	It("returns ErrStaleObject when version does not match", func() {
+		type VersionedDocument struct {
+			ID      int
+			Body    string
+			Version int `pg:",version"`
+		}
+
+		err := db.Model((*VersionedDocument)(nil)).DropTable(&orm.DropTableOptions{
+			IfExists: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = db.Model((*VersionedDocument)(nil)).CreateTable(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		doc := &VersionedDocument{ID: 1, Body: "draft"}
+		_, err = db.Model(doc).Insert()
+		Expect(err).NotTo(HaveOccurred())
+
+		stale := *doc
+
+		doc.Body = "first edit"
+		_, err = db.Model(doc).WherePK().Update()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Version).To(Equal(1))
+
+		stale.Body = "concurrent edit"
+		_, err = db.Model(&stale).WherePK().Update()
+		Expect(err).To(Equal(pg.ErrStaleObject))
+		Expect(stale.Version).To(Equal(0))
+
+		_, err = db.Model(&stale).WherePK().Returning("*").Update()
+		Expect(err).To(Equal(pg.ErrStaleObject))
+
+		got := &VersionedDocument{ID: 1}
+		err = db.Model(got).WherePK().Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(doc))
+	})
+
+	It("sets auto_now and auto_now_add timestamps", func() {
+		type StampedDocument struct {
+			ID        int
+			Body      string
+			CreatedAt time.Time `pg:",auto_now_add"`
+			UpdatedAt time.Time `pg:",auto_now"`
+		}
+
+		err := db.Model((*StampedDocument)(nil)).DropTable(&orm.DropTableOptions{
+			IfExists: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = db.Model((*StampedDocument)(nil)).CreateTable(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		doc := &StampedDocument{ID: 1, Body: "draft"}
+		_, err = db.Model(doc).Insert()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.CreatedAt).To(BeTemporally("~", time.Now(), time.Second))
+		Expect(doc.UpdatedAt).To(BeTemporally("~", time.Now(), time.Second))
+
+		createdAt := doc.CreatedAt
+		doc.Body = "edit"
+		_, err = db.Model(doc).Column("body").WherePK().Update()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.CreatedAt).To(Equal(createdAt))
+
+		got := &StampedDocument{ID: 1}
+		err = db.Model(got).WherePK().Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.Body).To(Equal("edit"))
+		Expect(got.CreatedAt.Unix()).To(Equal(createdAt.Unix()))
+		Expect(got.UpdatedAt).To(BeTemporally(">=", createdAt.Truncate(time.Microsecond)))
+	})
 })
 
 var _ = Describe("DB.Delete", func() {
@@ -1148,6 +2972,16 @@ type BookWithCommentCount struct {
 	CommentCount int
 }
 
+// BookWithTitleLength has a computed TitleLength field that is scanned only
+// when the query selects the title_length column.
+type BookWithTitleLength struct {
+	tableName struct{} `pg:"books,alias:book"`
+
+	ID          int
+	Title       string
+	TitleLength int `pg:",scanonly"`
+}
+
 type Translation struct {
 	tableName struct{} `pg:",alias:tr"` // custom table alias
 
@@ -1581,6 +3415,36 @@ var _ = Describe("ORM", func() {
 	})
 
 	Describe("slice model", func() {
+		It("selects books in the order of a VALUES list", func() {
+			type lookup struct {
+				ID       int
+				Position int
+			}
+			rows := []lookup{{ID: 102, Position: 1}, {ID: 100, Position: 2}, {ID: 999, Position: 3}}
+
+			var books []Book
+			err := db.Model(&books).
+				Join("JOIN ? ON v.id = book.id", pg.Values(rows).As("v")).
+				OrderExpr("v.position").
+				Select()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(books).To(HaveLen(2))
+			Expect(books[0].ID).To(Equal(102))
+			Expect(books[1].ID).To(Equal(100))
+		})
+
+		It("includes rows tied at the limit boundary", func() {
+			var books []Book
+			err := db.Model(&books).
+				Order("author_id ASC").
+				LimitWithTies(1).
+				Select()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(books).To(HaveLen(2))
+			Expect(books[0].AuthorID).To(Equal(10))
+			Expect(books[1].AuthorID).To(Equal(10))
+		})
+
 		It("fetches Book relations", func() {
 			var books []Book
 			err := db.Model(&books).
@@ -1931,9 +3795,50 @@ var _ = Describe("ORM", func() {
 				ID:   222,
 				Path: "222.jpg",
 			}}
-			_, err := db.Model(&books).Insert()
+			_, err := db.Model(&books).Insert()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(books)).NotTo(BeZero())
+		})
+
+		It("splits inserts with too many values", func() {
+			images := make([]Image, 40000)
+			for i := range images {
+				images[i].Path = fmt.Sprintf("%d.jpg", i)
+			}
+
+			res, err := db.Model(&images).Insert()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RowsAffected()).To(Equal(len(images)))
+			Expect(images[0].ID).NotTo(BeZero())
+			Expect(images[len(images)-1].ID).NotTo(BeZero())
+		})
+
+		It("reports upsert actions using ReturningAction", func() {
+			books := []Book{{
+				ID:       101,
+				Title:    "updated book 2",
+				AuthorID: 10,
+			}, {
+				ID:       200,
+				Title:    "new book",
+				AuthorID: 11,
+			}, {
+				ID:       100,
+				Title:    "updated book 1",
+				AuthorID: 10,
+			}}
+			var actions []orm.UpsertAction
+			res, err := db.Model(&books).
+				OnConflict("(id) DO UPDATE").
+				Set("title = EXCLUDED.title").
+				ReturningAction(&actions).
+				Insert()
 			Expect(err).NotTo(HaveOccurred())
-			Expect(len(books)).NotTo(BeZero())
+			Expect(res.RowsAffected()).To(Equal(3))
+			Expect(actions).To(Equal([]orm.UpsertAction{
+				orm.UpsertUpdated, orm.UpsertInserted, orm.UpsertUpdated,
+			}))
+			Expect(books[1].Title).To(Equal("new book"))
 		})
 	})
 
@@ -1944,6 +3849,27 @@ var _ = Describe("ORM", func() {
 			Expect(err).To(MatchError("pg: can't bulk-update empty slice []pg_test.Book"))
 		})
 
+		It("updates books from authors using UpdateFrom", func() {
+			authors := db.Model((*Author)(nil)).
+				ColumnExpr("id, name AS title").
+				Where("name != ?", "author 2")
+			res, err := db.Model((*Book)(nil)).
+				UpdateFrom(authors, "a").
+				SetFrom("a", "title").
+				Where("book.author_id = a.id").
+				Update()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RowsAffected()).To(Equal(2))
+
+			var books []Book
+			err = db.Model(&books).Order("id").Select()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(books).To(HaveLen(3))
+			Expect(books[0].Title).To(Equal("author 1"))
+			Expect(books[1].Title).To(Equal("author 1"))
+			Expect(books[2].Title).To(Equal("book 3"))
+		})
+
 		It("updates books using Set", func() {
 			var books []Book
 			err := db.Model(&books).Order("id").Select()
@@ -1995,6 +3921,33 @@ var _ = Describe("ORM", func() {
 			}}))
 		})
 
+		It("scans RETURNING rows into books by primary key", func() {
+			books := []Book{{
+				ID:    102,
+				Title: " (3)",
+			}, {
+				ID:    999,
+				Title: " (missing)",
+			}, {
+				ID:    100,
+				Title: " (1)",
+			}}
+			res, err := db.Model(&books).
+				Set("title = book.title || _data.title").
+				Returning("*").
+				Update()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RowsAffected()).To(Equal(2))
+
+			Expect(books).To(HaveLen(3))
+			Expect(books[0].Title).To(Equal("book 3 (3)"))
+			Expect(books[0].AuthorID).To(Equal(11))
+			Expect(books[1].Title).To(Equal(" (missing)"))
+			Expect(books[1].AuthorID).To(BeZero())
+			Expect(books[2].Title).To(Equal("book 1 (1)"))
+			Expect(books[2].AuthorID).To(Equal(10))
+		})
+
 		It("updates books using Column", func() {
 			var books []Book
 			err := db.Model(&books).Order("id").Select()
@@ -2019,6 +3972,59 @@ var _ = Describe("ORM", func() {
 	})
 
 	Describe("bulk delete", func() {
+		It("deletes orphaned translations using DeleteUsing", func() {
+			_, err := db.Model((*Book)(nil)).Where("id = ?", 101).Delete()
+			Expect(err).NotTo(HaveOccurred())
+
+			orphans := db.Model((*Translation)(nil)).
+				Column("tr.id").
+				Join("LEFT JOIN books AS b ON b.id = tr.book_id").
+				Where("b.id IS NULL")
+			res, err := db.Model((*Translation)(nil)).
+				DeleteUsing(orphans, "o").
+				Where("tr.id = o.id").
+				Delete()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RowsAffected()).To(Equal(1))
+
+			var translations []Translation
+			err = db.Model(&translations).Order("id").Select()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(translations).To(HaveLen(2))
+			Expect(translations[0].ID).To(Equal(1000))
+			Expect(translations[1].ID).To(Equal(1001))
+		})
+
+		It("archives deleted books using WithDelete", func() {
+			_, err := db.Exec("DROP TABLE IF EXISTS book_archive")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = db.Exec("CREATE TABLE book_archive (id int, title text)")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() {
+				_, err := db.Exec("DROP TABLE book_archive")
+				Expect(err).NotTo(HaveOccurred())
+			}()
+
+			deleted := db.Model((*Book)(nil)).
+				Where("?TableAlias.author_id = ?", 10).
+				Returning("id, title")
+			res, err := db.Model().
+				WithDelete("deleted", deleted).
+				Table("book_archive", "deleted").
+				Insert()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RowsAffected()).To(Equal(2))
+
+			var titles []string
+			_, err = db.Query(&titles, "SELECT title FROM book_archive ORDER BY id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles).To(Equal([]string{"book 1", "book 2"}))
+
+			n, err := db.Model((*Book)(nil)).Count()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(1))
+		})
+
 		It("returns an error when slice is empty", func() {
 			var books []Book
 			_, err := db.Model(&books).Delete()
@@ -2106,6 +4112,31 @@ var _ = Describe("ORM", func() {
 		}))
 	})
 
+	It("scans computed scanonly columns", func() {
+		book := &BookWithTitleLength{ID: 100}
+		err := db.Model(book).
+			ColumnExpr("?TableColumns").
+			ColumnExpr("length(book.title) AS title_length").
+			WherePK().
+			Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(book).To(Equal(&BookWithTitleLength{
+			ID:          100,
+			Title:       "book 1",
+			TitleLength: 6,
+		}))
+
+		book = &BookWithTitleLength{ID: 100}
+		err = db.Model(book).WherePK().Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(book.TitleLength).To(BeZero())
+
+		book.Title = "new title"
+		book.TitleLength = 123
+		_, err = db.Model(book).WherePK().Update()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
 	It("deletes book returning title", func() {
 		book := &Book{
 			ID: 100,
@@ -2127,6 +4158,85 @@ var _ = Describe("ORM", func() {
 		Expect(ids).To(Equal([]int{100, 101, 102}))
 	})
 
+	It("deletes books returning ids and titles into slices", func() {
+		var ids []int
+		var titles []string
+		res, err := db.Model((*Book)(nil)).
+			Where("author_id = ?", 10).
+			Returning("id, title").
+			Delete(&ids, &titles)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(2))
+		Expect(ids).To(Equal([]int{100, 101}))
+		Expect(titles).To(Equal([]string{"book 1", "book 2"}))
+
+		res, err = db.Model((*Book)(nil)).
+			Where("author_id = ?", 10).
+			Returning("id, title").
+			Delete(&ids, &titles)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(0))
+		Expect(ids).To(BeEmpty())
+		Expect(titles).To(BeEmpty())
+	})
+
+	It("updates a book returning columns into several values", func() {
+		var id int
+		var title string
+		_, err := db.Model((*Book)(nil)).
+			Set("title = upper(title)").
+			Where("id = ?", 100).
+			Returning("id, title").
+			Update(&id, &title)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(100))
+		Expect(title).To(Equal("BOOK 1"))
+	})
+
+	It("deletes books returning deleted rows into the model slice", func() {
+		var books []Book
+		res, err := db.Model(&books).
+			Where("author_id = ?", 10).
+			Returning("id, title").
+			Delete()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(2))
+		Expect(books).To(Equal([]Book{
+			{ID: 100, Title: "book 1"},
+			{ID: 101, Title: "book 2"},
+		}))
+	})
+
+	It("filters authors by the number of books", func() {
+		var ids []int
+		err := db.Model((*Author)(nil)).
+			Column("id").
+			WhereRelationCount("Books", ">", 1).
+			Select(&ids)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]int{10}))
+
+		err = db.Model((*Author)(nil)).
+			Column("id").
+			WhereRelationCount("Books", "=", 0).
+			Order("id").
+			Select(&ids)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]int{12}))
+	})
+
+	It("shows the query comment in pg_stat_activity", func() {
+		var query string
+		err := db.Model().
+			Comment("checkout-finalize").
+			Table("pg_stat_activity").
+			Column("query").
+			Where("pid = pg_backend_pid()").
+			Select(pg.Scan(&query))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(query).To(HavePrefix("/* checkout-finalize */ SELECT"))
+	})
+
 	It("supports Exec & Query", func() {
 		_, err := db.Model((*Book)(nil)).Exec("DROP TABLE ?TableName CASCADE")
 		Expect(err).NotTo(HaveOccurred())
@@ -2232,6 +4342,38 @@ var _ = Describe("ORM", func() {
 		})
 	})
 
+	Describe("SelectColumns", func() {
+		It("returns typed column slices", func() {
+			cols, err := db.Model((*Book)(nil)).
+				ColumnExpr("id, title, author_id::int AS author_id, editor_id IS NULL AS no_editor").
+				Order("id ASC").
+				SelectColumns()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cols).To(Equal(map[string]interface{}{
+				"id":        []int64{100, 101, 102},
+				"title":     []string{"book 1", "book 2", "book 3"},
+				"author_id": []int32{10, 10, 11},
+				"no_editor": []bool{false, false, false},
+			}))
+		})
+
+		It("returns empty map without rows", func() {
+			cols, err := db.Model((*Book)(nil)).
+				Column("id").
+				Where("false").
+				SelectColumns()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cols).To(BeEmpty())
+		})
+
+		It("returns an error for duplicate columns", func() {
+			_, err := db.Model((*Book)(nil)).
+				ColumnExpr("id, id").
+				SelectColumns()
+			Expect(err).To(MatchError(`pg: SelectColumns got duplicate column "id"`))
+		})
+	})
+
 	Describe("SelectAndCount", func() {
 		It("selects and counts books", func() {
 			var books []Book
@@ -2241,6 +4383,27 @@ var _ = Describe("ORM", func() {
 			Expect(books).To(HaveLen(3))
 		})
 
+		It("binds HAVING params and counts filtered groups", func() {
+			var authorIDs []int
+			count, err := db.Model((*Book)(nil)).
+				Column("author_id").
+				Where("title <> ?", "").
+				Group("author_id").
+				Having("count(*) > ?", 1).
+				Having("min(id) >= ?", 100).
+				SelectAndCount(&authorIDs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+			Expect(authorIDs).To(Equal([]int{10}))
+
+			count, err = db.Model((*Book)(nil)).
+				ColumnExpr("count(*)").
+				Having("count(*) > ?", 10).
+				Count()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(0))
+		})
+
 		It("works with Limit=-1", func() {
 			var books []Book
 			count, err := db.Model(&books).Limit(-1).SelectAndCount()
@@ -2250,6 +4413,241 @@ var _ = Describe("ORM", func() {
 		})
 	})
 
+	Describe("ForOf", func() {
+		It("locks only the book and not the joined author", func() {
+			tx, err := db.Begin()
+			Expect(err).NotTo(HaveOccurred())
+			defer tx.Rollback()
+
+			book := new(Book)
+			err = tx.Model(book).
+				Relation("Author").
+				Where("book.id = ?", 100).
+				ForOf("UPDATE", "book").
+				Select()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(book.Author.ID).To(Equal(10))
+
+			tx2, err := db.Begin()
+			Expect(err).NotTo(HaveOccurred())
+			defer tx2.Rollback()
+
+			_, err = tx2.Exec("SELECT 1 FROM authors WHERE id = 10 FOR UPDATE NOWAIT")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = tx2.Exec("SELECT 1 FROM books WHERE id = 100 FOR UPDATE NOWAIT")
+			Expect(err).To(HaveOccurred())
+			Expect(err.(pg.Error).Field('C')).To(Equal("55P03"))
+		})
+	})
+
+	Describe("has-many relation with limit", func() {
+		It("limits the relation per parent", func() {
+			var authors []Author
+			err := db.Model(&authors).
+				Relation("Books", func(q *orm.Query) (*orm.Query, error) {
+					return q.Order("book.id DESC").Limit(1), nil
+				}).
+				Order("author.id").
+				Select()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authors).To(HaveLen(3))
+			Expect(authors[0].Books).To(HaveLen(1))
+			Expect(authors[0].Books[0].ID).To(Equal(101))
+			Expect(authors[1].Books).To(HaveLen(1))
+			Expect(authors[1].Books[0].ID).To(Equal(102))
+			Expect(authors[2].Books).To(HaveLen(0))
+		})
+
+		It("offsets the relation per parent", func() {
+			var authors []Author
+			err := db.Model(&authors).
+				Relation("Books", func(q *orm.Query) (*orm.Query, error) {
+					return q.Order("book.id DESC").Offset(1), nil
+				}).
+				Order("author.id").
+				Select()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authors).To(HaveLen(3))
+			Expect(authors[0].Books).To(HaveLen(1))
+			Expect(authors[0].Books[0].ID).To(Equal(100))
+			Expect(authors[1].Books).To(HaveLen(0))
+			Expect(authors[2].Books).To(HaveLen(0))
+		})
+	})
+
+	Describe("LimitOffsetParams", func() {
+		It("selects pages using a prepared statement", func() {
+			q := db.Model((*Book)(nil)).Column("id").Order("id").LimitOffsetParams(1, 2)
+			query, err := q.AppendPreparedQuery(db.Formatter(), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(query)).To(HaveSuffix(`ORDER BY "id" LIMIT $1 OFFSET $2`))
+
+			stmt, err := db.Prepare(string(query))
+			Expect(err).NotTo(HaveOccurred())
+			defer stmt.Close()
+
+			var page []Book
+			_, err = stmt.Query(&page, 2, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page).To(HaveLen(2))
+			Expect(page[0].ID).To(Equal(100))
+			Expect(page[1].ID).To(Equal(101))
+
+			_, err = stmt.Query(&page, 2, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page).To(HaveLen(1))
+			Expect(page[0].ID).To(Equal(102))
+		})
+
+		It("returns an error when the query is not prepared", func() {
+			var books []Book
+			err := db.Model(&books).Order("id").LimitOffsetParams(1, 2).Select()
+			Expect(err).To(MatchError("pg: LimitOffsetParams requires a prepared statement " +
+				"(use AppendPreparedQuery and DB.Prepare)"))
+		})
+	})
+
+	Describe("SelectByIDsOrdered", func() {
+		It("returns books in the order of ids", func() {
+			var books []*Book
+			err := db.Model(&books).
+				Relation("Author").
+				SelectByIDsOrdered([]int64{102, 999, 100, 102})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(books).To(HaveLen(4))
+			Expect(books[0].ID).To(Equal(102))
+			Expect(books[0].Author.ID).To(Equal(11))
+			Expect(books[1]).To(BeNil())
+			Expect(books[2].ID).To(Equal(100))
+			Expect(books[2].Author.ID).To(Equal(10))
+			Expect(books[3]).To(BeIdenticalTo(books[0]))
+		})
+
+		It("returns books in a map", func() {
+			var books []*Book
+			var byID map[int]*Book
+			err := db.Model(&books).SelectByIDsMap([]int{101, 999}, &byID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(byID).To(HaveLen(1))
+			Expect(byID[101].Title).To(Equal("book 2"))
+		})
+
+		It("does not query without ids", func() {
+			books := []*Book{{ID: 1}}
+			err := db.Model(&books).SelectByIDsOrdered([]int{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(books).To(BeEmpty())
+		})
+
+		It("requires a slice of pointers", func() {
+			var books []Book
+			err := db.Model(&books).SelectByIDsOrdered([]int{100})
+			Expect(err).To(MatchError(
+				"pg: SelectByIDsOrdered(unsupported []pg_test.Book, wanted a slice of pointers)"))
+		})
+	})
+
+	Describe("LastInsertId", func() {
+		It("returns the serial id of the inserted book", func() {
+			book := &Book{Title: "new book", AuthorID: 10, EditorID: 11}
+			q := db.Model(book)
+			res, err := q.Insert()
+			Expect(err).NotTo(HaveOccurred())
+
+			id, err := q.LastInsertId(res)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).NotTo(BeZero())
+			Expect(id).To(Equal(int64(book.ID)))
+		})
+
+		It("is not available for bulk inserts", func() {
+			books := []Book{{Title: "book 1", AuthorID: 10}, {Title: "book 2", AuthorID: 10}}
+			q := db.Model(&books)
+			res, err := q.Insert()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = q.LastInsertId(res)
+			Expect(err).To(Equal(pg.ErrNoLastInsertId))
+		})
+	})
+
+	Describe("Save", func() {
+		It("inserts a new book", func() {
+			book := &Book{Title: "new book", AuthorID: 10, EditorID: 11}
+			err := db.Model(book).Save()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(book.ID).NotTo(BeZero())
+
+			count, err := db.Model((*Book)(nil)).Count()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(4))
+		})
+
+		It("updates an existing book", func() {
+			book := &Book{ID: 100}
+			err := db.Model(book).WherePK().Select()
+			Expect(err).NotTo(HaveOccurred())
+
+			book.Title = "updated"
+			err = db.Model(book).Save()
+			Expect(err).NotTo(HaveOccurred())
+
+			book = &Book{ID: 100}
+			err = db.Model(book).WherePK().Select()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(book.Title).To(Equal("updated"))
+		})
+
+		It("inserts and updates books in a slice", func() {
+			var books []Book
+			err := db.Model(&books).Where("id = 100").Select()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(books).To(HaveLen(1))
+
+			books[0].Title = "updated"
+			books = append(books, Book{Title: "new book", AuthorID: 10, EditorID: 11})
+
+			err = db.Model(&books).Save()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(books[1].ID).NotTo(BeZero())
+
+			var titles []string
+			err = db.Model((*Book)(nil)).
+				Column("title").
+				WhereIn("id IN (?)", []int{100, books[1].ID}).
+				Order("title ASC").
+				Select(&titles)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles).To(Equal([]string{"new book", "updated"}))
+		})
+	})
+
+	Describe("SelectScalar", func() {
+		It("selects an aggregate", func() {
+			var sum int
+			err := db.Model((*Book)(nil)).ColumnExpr("sum(id)").SelectScalar(&sum)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sum).To(Equal(303))
+		})
+
+		It("supports SelectInt and SelectFloat", func() {
+			n, err := db.Model((*Book)(nil)).ColumnExpr("max(id)").SelectInt()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(102))
+
+			f, err := db.Model((*Book)(nil)).ColumnExpr("avg(id)").SelectFloat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f).To(Equal(101.0))
+		})
+
+		It("returns an error without columns", func() {
+			var sum int
+			err := db.Model((*Book)(nil)).SelectScalar(&sum)
+			Expect(err).To(MatchError("pg: SelectScalar requires a column (try ColumnExpr)"))
+		})
+	})
+
 	Describe("Exists", func() {
 		It("returns true for existing rows", func() {
 			var books []Book
@@ -2467,6 +4865,132 @@ var _ = Describe("soft delete with int column", func() {
 	})
 })
 
+type SoftDeleteAuthor struct {
+	ID        int
+	Name      string
+	Books     []*SoftDeleteBook `pg:"rel:has-many"`
+	DeletedAt time.Time         `pg:",soft_delete"`
+}
+
+type SoftDeleteBook struct {
+	ID                 int
+	Title              string
+	SoftDeleteAuthorID int
+	SoftDeleteAuthor   *SoftDeleteAuthor `pg:"rel:has-one"`
+	DeletedAt          time.Time         `pg:",soft_delete"`
+}
+
+var _ = Describe("soft delete with relations", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = testDB()
+
+		for _, model := range []interface{}{
+			(*SoftDeleteAuthor)(nil),
+			(*SoftDeleteBook)(nil),
+		} {
+			err := db.Model(model).CreateTable(&orm.CreateTableOptions{
+				Temp: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		author := &SoftDeleteAuthor{ID: 1, Name: "author 1"}
+		_, err := db.Model(author).Insert()
+		Expect(err).NotTo(HaveOccurred())
+
+		books := []*SoftDeleteBook{
+			{ID: 100, Title: "book 100", SoftDeleteAuthorID: 1},
+			{ID: 101, Title: "book 101", SoftDeleteAuthorID: 1},
+		}
+		_, err = db.Model(&books).Insert()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = db.Model(author).WherePK().Delete()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = db.Model(books[1]).WherePK().Delete()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		for _, model := range []interface{}{
+			(*SoftDeleteAuthor)(nil),
+			(*SoftDeleteBook)(nil),
+		} {
+			err := db.Model(model).DropTable(nil)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("filters out deleted rows by default", func() {
+		var authors []SoftDeleteAuthor
+		err := db.Model(&authors).Relation("Books").Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(authors).To(HaveLen(0))
+
+		var books []SoftDeleteBook
+		err = db.Model(&books).Relation("SoftDeleteAuthor").Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(books).To(HaveLen(1))
+		Expect(books[0].ID).To(Equal(100))
+	})
+
+	It("AllWithDeleted includes deleted relations", func() {
+		var authors []SoftDeleteAuthor
+		err := db.Model(&authors).Relation("Books").AllWithDeleted().Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(authors).To(HaveLen(1))
+		Expect(authors[0].Books).To(HaveLen(2))
+	})
+
+	It("WithAllDeleted includes deleted rows and relations", func() {
+		ctx := pg.WithAllDeleted(ctx)
+
+		var authors []SoftDeleteAuthor
+		err := db.ModelContext(ctx, &authors).Relation("Books").Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(authors).To(HaveLen(1))
+		Expect(authors[0].Books).To(HaveLen(2))
+
+		var books []SoftDeleteBook
+		err = db.ModelContext(ctx, &books).Relation("SoftDeleteAuthor").Order("id").Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(books).To(HaveLen(2))
+		for _, book := range books {
+			Expect(book.SoftDeleteAuthor).NotTo(BeNil())
+			Expect(book.SoftDeleteAuthor.ID).To(Equal(1))
+		}
+	})
+
+	It("Deleted takes precedence over WithAllDeleted", func() {
+		ctx := pg.WithAllDeleted(ctx)
+
+		var books []SoftDeleteBook
+		err := db.ModelContext(ctx, &books).Deleted().Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(books).To(HaveLen(1))
+		Expect(books[0].ID).To(Equal(101))
+	})
+
+	It("relations can filter deleted rows explicitly", func() {
+		ctx := pg.WithAllDeleted(ctx)
+
+		var authors []SoftDeleteAuthor
+		err := db.ModelContext(ctx, &authors).
+			Relation("Books", func(q *orm.Query) (*orm.Query, error) {
+				return q.Where("soft_delete_book.deleted_at IS NULL"), nil
+			}).
+			Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(authors).To(HaveLen(1))
+		Expect(authors[0].Books).To(HaveLen(1))
+		Expect(authors[0].Books[0].ID).To(Equal(100))
+	})
+})
+
 type Recipe struct {
 	tableName   struct{} `pg:"?tenant.recipes"`
 	Id          int
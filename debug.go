@@ -0,0 +1,57 @@
+package pg
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/go-pg/pg/v10/internal"
+)
+
+// debugHook logs queries and their durations when Options.Debug is set.
+type debugHook struct {
+	logger     *log.Logger
+	omitParams bool
+}
+
+var _ QueryHook = (*debugHook)(nil)
+
+func newDebugHook(opt *Options) *debugHook {
+	return &debugHook{
+		logger:     opt.DebugLogger,
+		omitParams: opt.DebugOmitParams,
+	}
+}
+
+func (h *debugHook) BeforeQuery(ctx context.Context, _ *QueryEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *debugHook) AfterQuery(ctx context.Context, evt *QueryEvent) error {
+	var query []byte
+	var err error
+	if h.omitParams {
+		query, err = evt.UnformattedQuery()
+	} else {
+		query, err = evt.FormattedQuery()
+	}
+	if err != nil {
+		query = []byte(err.Error())
+	}
+
+	dur := time.Since(evt.StartTime)
+	if evt.Err != nil {
+		h.printf(ctx, "%s %s: %s", dur, query, evt.Err)
+	} else {
+		h.printf(ctx, "%s %s", dur, query)
+	}
+	return nil
+}
+
+func (h *debugHook) printf(ctx context.Context, format string, v ...interface{}) {
+	if h.logger != nil {
+		h.logger.Printf(format, v...)
+		return
+	}
+	internal.Logger.Printf(ctx, format, v...)
+}
@@ -0,0 +1,48 @@
+package pg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugHook(t *testing.T) {
+	var buf bytes.Buffer
+	hook := newDebugHook(&Options{
+		DebugLogger: log.New(&buf, "", 0),
+	})
+
+	evt := &QueryEvent{
+		StartTime:  time.Now(),
+		Query:      "SELECT ?",
+		Params:     []interface{}{"secret"},
+		fmtedQuery: []byte("SELECT 'secret'"),
+	}
+	if err := hook.AfterQuery(context.Background(), evt); err != nil {
+		t.Fatal(err)
+	}
+
+	got := buf.String()
+	if !strings.HasSuffix(got, " SELECT 'secret'\n") {
+		t.Fatalf("got %q, wanted formatted query", got)
+	}
+
+	buf.Reset()
+	hook.omitParams = true
+	evt.Err = errors.New("boom")
+	if err := hook.AfterQuery(context.Background(), evt); err != nil {
+		t.Fatal(err)
+	}
+
+	got = buf.String()
+	if strings.Contains(got, "secret") {
+		t.Fatalf("got %q, wanted query without params", got)
+	}
+	if !strings.HasSuffix(got, " SELECT ?: boom\n") {
+		t.Fatalf("got %q, wanted query with error", got)
+	}
+}
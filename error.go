@@ -14,6 +14,20 @@ var ErrNoRows = internal.ErrNoRows
 // multiple rows but exactly one row is expected.
 var ErrMultiRows = internal.ErrMultiRows
 
+// ErrTooManyRows is returned when a query returned more rows than
+// allowed by Options.MaxRows or Query.MaxRows.
+var ErrTooManyRows = internal.ErrTooManyRows
+
+// ErrStaleObject is returned by Update when the model has a field tagged
+// with pg:",version" and the row with the current version does not exist,
+// i.e. the row was updated or deleted concurrently. Reload the model
+// and retry the update.
+var ErrStaleObject = internal.ErrStaleObject
+
+// ErrNoLastInsertId is returned by Query.LastInsertId when the query did not
+// insert a single model row with an integer primary key.
+var ErrNoLastInsertId = internal.ErrNoLastInsertId
+
 // Error represents an error returned by PostgreSQL server
 // using PostgreSQL ErrorResponse protocol.
 //
@@ -42,6 +56,10 @@ func isBadConn(err error, allowTimeout bool) bool {
 	if _, ok := err.(internal.Error); ok {
 		return false
 	}
+	if _, ok := err.(*ImportError); ok {
+		// COPY is aborted with CopyFail and the connection is usable.
+		return false
+	}
 	if pgErr, ok := err.(Error); ok {
 		switch pgErr.Field('V') {
 		case "FATAL", "PANIC":
@@ -62,6 +80,13 @@ func isBadConn(err error, allowTimeout bool) bool {
 	return true
 }
 
+// isQueryCanceled reports whether the server canceled the query
+// in response to a cancel request.
+func isQueryCanceled(err error) bool {
+	pgErr, ok := err.(Error)
+	return ok && pgErr.Field('C') == "57014" && pgErr.Field('V') != "FATAL"
+}
+
 //------------------------------------------------------------------------------
 
 type timeoutError interface {
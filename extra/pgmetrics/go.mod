@@ -0,0 +1,7 @@
+module github.com/go-pg/pg/extra/pgmetrics/v10
+
+go 1.15
+
+replace github.com/go-pg/pg/v10 => ../..
+
+require github.com/go-pg/pg/v10 v10.10.6
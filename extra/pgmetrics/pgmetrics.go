@@ -0,0 +1,162 @@
+package pgmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// OtherOp is the operation of queries that are not built with orm.Query,
+// e.g. db.Exec("VACUUM").
+const OtherOp orm.QueryOp = "OTHER"
+
+type queryOperation interface {
+	Operation() orm.QueryOp
+}
+
+// OpMetrics holds counters for a single operation.
+type OpMetrics struct {
+	Queries uint64 `json:"queries"`
+	Errors  uint64 `json:"errors"`
+}
+
+type opCounters struct {
+	queries uint64
+	errors  uint64
+}
+
+// MetricsHook is a pg.QueryHook that counts queries and errors per operation,
+// e.g. SELECT, INSERT, UPDATE and DELETE. Operations are taken from the
+// queries built with orm.Query and other queries are counted as OTHER.
+// pg.ErrNoRows and pg.ErrMultiRows are not counted as errors.
+//
+// It can be installed with:
+//
+//   hook := pgmetrics.NewMetricsHook()
+//   db.AddQueryHook(hook)
+//
+// MetricsHook implements expvar.Var, so the counters can be exported with:
+//
+//   expvar.Publish("pg", hook)
+type MetricsHook struct {
+	mu  sync.RWMutex
+	ops map[orm.QueryOp]*opCounters
+}
+
+func NewMetricsHook() *MetricsHook {
+	return &MetricsHook{
+		ops: make(map[orm.QueryOp]*opCounters),
+	}
+}
+
+var _ pg.QueryHook = (*MetricsHook)(nil)
+
+func (h *MetricsHook) BeforeQuery(ctx context.Context, _ *pg.QueryEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *MetricsHook) AfterQuery(ctx context.Context, evt *pg.QueryEvent) error {
+	op := OtherOp
+	if v, ok := evt.Query.(queryOperation); ok {
+		op = v.Operation()
+	}
+
+	c := h.counters(op)
+	atomic.AddUint64(&c.queries, 1)
+
+	switch evt.Err {
+	case nil, pg.ErrNoRows, pg.ErrMultiRows:
+	default:
+		atomic.AddUint64(&c.errors, 1)
+	}
+
+	return nil
+}
+
+func (h *MetricsHook) counters(op orm.QueryOp) *opCounters {
+	h.mu.RLock()
+	c, ok := h.ops[op]
+	h.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c, ok = h.ops[op]
+	if !ok {
+		c = new(opCounters)
+		h.ops[op] = c
+	}
+	return c
+}
+
+// Metrics returns a snapshot of the counters keyed by operation.
+func (h *MetricsHook) Metrics() map[orm.QueryOp]OpMetrics {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	m := make(map[orm.QueryOp]OpMetrics, len(h.ops))
+	for op, c := range h.ops {
+		m[op] = OpMetrics{
+			Queries: atomic.LoadUint64(&c.queries),
+			Errors:  atomic.LoadUint64(&c.errors),
+		}
+	}
+	return m
+}
+
+// String returns the counters as JSON and implements expvar.Var.
+func (h *MetricsHook) String() string {
+	b, err := json.Marshal(h.Metrics())
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// WritePrometheus writes the counters in the Prometheus text format:
+//
+//   # TYPE pg_queries_total counter
+//   pg_queries_total{operation="SELECT"} 10
+//   # TYPE pg_query_errors_total counter
+//   pg_query_errors_total{operation="SELECT"} 1
+func (h *MetricsHook) WritePrometheus(w io.Writer) error {
+	m := h.Metrics()
+
+	ops := make([]string, 0, len(m))
+	for op := range m {
+		ops = append(ops, string(op))
+	}
+	sort.Strings(ops)
+
+	if _, err := io.WriteString(w, "# TYPE pg_queries_total counter\n"); err != nil {
+		return err
+	}
+	for _, op := range ops {
+		_, err := fmt.Fprintf(w, "pg_queries_total{operation=%q} %d\n", op, m[orm.QueryOp(op)].Queries)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.WriteString(w, "# TYPE pg_query_errors_total counter\n"); err != nil {
+		return err
+	}
+	for _, op := range ops {
+		_, err := fmt.Fprintf(w, "pg_query_errors_total{operation=%q} %d\n", op, m[orm.QueryOp(op)].Errors)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package pgmetrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+func TestMetricsHook(t *testing.T) {
+	hook := NewMetricsHook()
+	ctx := context.Background()
+
+	q := orm.NewQuery(nil)
+	events := []*pg.QueryEvent{
+		{Query: orm.NewSelectQuery(q)},
+		{Query: orm.NewSelectQuery(q), Err: pg.ErrNoRows},
+		{Query: orm.NewSelectQuery(q), Err: pg.ErrMultiRows},
+		{Query: orm.NewInsertQuery(q), Err: errors.New("pg: duplicate key")},
+		{Query: "VACUUM"},
+	}
+	for _, evt := range events {
+		var err error
+		ctx, err = hook.BeforeQuery(ctx, evt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := hook.AfterQuery(ctx, evt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wanted := map[orm.QueryOp]OpMetrics{
+		orm.SelectOp: {Queries: 3},
+		orm.InsertOp: {Queries: 1, Errors: 1},
+		OtherOp:      {Queries: 1},
+	}
+	if got := hook.Metrics(); !reflect.DeepEqual(got, wanted) {
+		t.Fatalf("got %v, wanted %v", got, wanted)
+	}
+
+	wantedJSON := `{"INSERT":{"queries":1,"errors":1},"OTHER":{"queries":1,"errors":0},"SELECT":{"queries":3,"errors":0}}`
+	if got := hook.String(); got != wantedJSON {
+		t.Fatalf("got %s, wanted %s", got, wantedJSON)
+	}
+
+	var buf bytes.Buffer
+	if err := hook.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	wantedText := `# TYPE pg_queries_total counter
+pg_queries_total{operation="INSERT"} 1
+pg_queries_total{operation="OTHER"} 1
+pg_queries_total{operation="SELECT"} 3
+# TYPE pg_query_errors_total counter
+pg_query_errors_total{operation="INSERT"} 1
+pg_query_errors_total{operation="OTHER"} 0
+pg_query_errors_total{operation="SELECT"} 0
+`
+	if got := buf.String(); got != wantedText {
+		t.Fatalf("got\n%s\nwanted\n%s", got, wantedText)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/go-pg/pg/extra/pgproto/v10
+
+go 1.15
+
+replace github.com/go-pg/pg/v10 => ../..
+
+require (
+	github.com/go-pg/pg/v10 v10.10.6
+	google.golang.org/protobuf v1.25.0
+)
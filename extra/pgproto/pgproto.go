@@ -0,0 +1,373 @@
+package pgproto
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-pg/pg/v10/types"
+)
+
+const (
+	timestampName protoreflect.FullName = "google.protobuf.Timestamp"
+	wrappersPkg   protoreflect.FullName = "google.protobuf"
+)
+
+var protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+// Scan returns a model that scans a single row into the protobuf message.
+// Columns are matched with fields by the protobuf field name, e.g.
+// created_at, or by the JSON name, e.g. createdAt:
+//
+//    user := new(pb.User)
+//    _, err := db.QueryOne(pgproto.Scan(user), "SELECT * FROM users WHERE id = ?", id)
+//
+// NULL clears the field. Timestamp fields are scanned from timestamp and
+// timestamptz columns, wrapper fields (e.g. StringValue) from the wrapped
+// type, other message fields from json or jsonb columns, and repeated fields
+// of scalars from arrays. Columns starting with an underscore are ignored.
+func Scan(msg proto.Message) orm.HooklessModel {
+	return &messageModel{
+		msg: msg,
+	}
+}
+
+// ScanSlice is like Scan, but accepts a pointer to a slice of messages,
+// e.g. *[]*pb.User, and appends a message for every row.
+func ScanSlice(slice interface{}) orm.HooklessModel {
+	return &sliceModel{
+		v: reflect.ValueOf(slice),
+	}
+}
+
+type messageModel struct {
+	orm.Discard
+	msg proto.Message
+}
+
+var _ orm.HooklessModel = (*messageModel)(nil)
+
+func (m *messageModel) Init() error {
+	proto.Reset(m.msg)
+	return nil
+}
+
+func (m *messageModel) NextColumnScanner() orm.ColumnScanner {
+	return messageScanner{m: m.msg.ProtoReflect()}
+}
+
+func (m *messageModel) AddColumnScanner(orm.ColumnScanner) error {
+	return nil
+}
+
+type sliceModel struct {
+	orm.Discard
+	v     reflect.Value
+	slice reflect.Value
+}
+
+var _ orm.HooklessModel = (*sliceModel)(nil)
+
+func (m *sliceModel) Init() error {
+	if !m.v.IsValid() {
+		return fmt.Errorf("pgproto: ScanSlice(nil)")
+	}
+	if m.v.Kind() != reflect.Ptr {
+		return fmt.Errorf("pgproto: ScanSlice(non-pointer %s)", m.v.Type())
+	}
+	m.slice = m.v.Elem()
+	if m.slice.Kind() != reflect.Slice || !m.slice.Type().Elem().Implements(protoMessageType) ||
+		m.slice.Type().Elem().Kind() != reflect.Ptr {
+		return fmt.Errorf("pgproto: ScanSlice(unsupported %s)", m.v.Type())
+	}
+
+	if m.slice.Len() > 0 {
+		m.slice.Set(m.slice.Slice(0, 0))
+	}
+	return nil
+}
+
+func (m *sliceModel) NextColumnScanner() orm.ColumnScanner {
+	elem := reflect.New(m.slice.Type().Elem().Elem())
+	m.slice.Set(reflect.Append(m.slice, elem))
+	msg := elem.Interface().(proto.Message)
+	return messageScanner{m: msg.ProtoReflect()}
+}
+
+func (m *sliceModel) AddColumnScanner(orm.ColumnScanner) error {
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type messageScanner struct {
+	m protoreflect.Message
+}
+
+var _ orm.ColumnScanner = messageScanner{}
+
+func (s messageScanner) ScanColumn(col types.ColumnInfo, rd types.Reader, n int) error {
+	if len(col.Name) > 0 && col.Name[0] == '_' {
+		return nil
+	}
+
+	fd, ok := messageFields(s.m.Descriptor())[col.Name]
+	if !ok {
+		return fmt.Errorf("pgproto: can't find column=%s in message=%s",
+			col.Name, s.m.Descriptor().FullName())
+	}
+
+	if n == -1 {
+		s.m.Clear(fd)
+		return nil
+	}
+
+	switch {
+	case fd.IsMap():
+		return fmt.Errorf("pgproto: map field %s is not supported", fd.FullName())
+	case fd.IsList():
+		return scanList(s.m, fd, rd, n)
+	case fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind:
+		return scanMessage(s.m, fd, rd, n)
+	}
+
+	v, err := scanScalar(fd, rd, n)
+	if err != nil {
+		return err
+	}
+	s.m.Set(fd, v)
+	return nil
+}
+
+var fieldsCache sync.Map
+
+// messageFields returns fields of the message keyed by the protobuf name
+// and by the JSON name.
+func messageFields(md protoreflect.MessageDescriptor) map[string]protoreflect.FieldDescriptor {
+	if v, ok := fieldsCache.Load(md.FullName()); ok {
+		return v.(map[string]protoreflect.FieldDescriptor)
+	}
+
+	fds := md.Fields()
+	fields := make(map[string]protoreflect.FieldDescriptor, fds.Len())
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		fields[string(fd.Name())] = fd
+	}
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		if _, ok := fields[fd.JSONName()]; !ok {
+			fields[fd.JSONName()] = fd
+		}
+	}
+
+	fieldsCache.Store(md.FullName(), fields)
+	return fields
+}
+
+func scanMessage(m protoreflect.Message, fd protoreflect.FieldDescriptor, rd types.Reader, n int) error {
+	md := fd.Message()
+	switch {
+	case md.FullName() == timestampName:
+		var tm time.Time
+		if err := types.Scan(&tm, rd, n); err != nil {
+			return err
+		}
+		m.Set(fd, protoreflect.ValueOfMessage(timestamppb.New(tm).ProtoReflect()))
+		return nil
+	case isWrapper(md):
+		sub := m.NewField(fd).Message()
+		v, err := scanScalar(md.Fields().ByNumber(1), rd, n)
+		if err != nil {
+			return err
+		}
+		sub.Set(md.Fields().ByNumber(1), v)
+		m.Set(fd, protoreflect.ValueOfMessage(sub))
+		return nil
+	default:
+		b, err := rd.ReadFull()
+		if err != nil {
+			return err
+		}
+		sub := m.NewField(fd).Message()
+		if err := protojson.Unmarshal(b, sub.Interface()); err != nil {
+			return err
+		}
+		m.Set(fd, protoreflect.ValueOfMessage(sub))
+		return nil
+	}
+}
+
+func isWrapper(md protoreflect.MessageDescriptor) bool {
+	if md.FullName().Parent() != wrappersPkg {
+		return false
+	}
+	switch md.Name() {
+	case "DoubleValue", "FloatValue", "Int64Value", "UInt64Value",
+		"Int32Value", "UInt32Value", "BoolValue", "StringValue", "BytesValue":
+		return true
+	}
+	return false
+}
+
+func scanList(m protoreflect.Message, fd protoreflect.FieldDescriptor, rd types.Reader, n int) error {
+	list := m.NewField(fd).List()
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		var vs []bool
+		if err := types.NewArray(&vs).ScanValue(rd, n); err != nil {
+			return err
+		}
+		for _, v := range vs {
+			list.Append(protoreflect.ValueOfBool(v))
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var vs []int64
+		if err := types.NewArray(&vs).ScanValue(rd, n); err != nil {
+			return err
+		}
+		for _, v := range vs {
+			if v < math.MinInt32 || v > math.MaxInt32 {
+				return fmt.Errorf("pgproto: value %d overflows field %s", v, fd.FullName())
+			}
+			list.Append(protoreflect.ValueOfInt32(int32(v)))
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var vs []int64
+		if err := types.NewArray(&vs).ScanValue(rd, n); err != nil {
+			return err
+		}
+		for _, v := range vs {
+			list.Append(protoreflect.ValueOfInt64(v))
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var vs []uint64
+		if err := types.NewArray(&vs).ScanValue(rd, n); err != nil {
+			return err
+		}
+		for _, v := range vs {
+			if v > math.MaxUint32 {
+				return fmt.Errorf("pgproto: value %d overflows field %s", v, fd.FullName())
+			}
+			list.Append(protoreflect.ValueOfUint32(uint32(v)))
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var vs []uint64
+		if err := types.NewArray(&vs).ScanValue(rd, n); err != nil {
+			return err
+		}
+		for _, v := range vs {
+			list.Append(protoreflect.ValueOfUint64(v))
+		}
+	case protoreflect.FloatKind:
+		var vs []float64
+		if err := types.NewArray(&vs).ScanValue(rd, n); err != nil {
+			return err
+		}
+		for _, v := range vs {
+			list.Append(protoreflect.ValueOfFloat32(float32(v)))
+		}
+	case protoreflect.DoubleKind:
+		var vs []float64
+		if err := types.NewArray(&vs).ScanValue(rd, n); err != nil {
+			return err
+		}
+		for _, v := range vs {
+			list.Append(protoreflect.ValueOfFloat64(v))
+		}
+	case protoreflect.StringKind, protoreflect.EnumKind:
+		var vs []string
+		if err := types.NewArray(&vs).ScanValue(rd, n); err != nil {
+			return err
+		}
+		for _, s := range vs {
+			if fd.Kind() == protoreflect.StringKind {
+				list.Append(protoreflect.ValueOfString(s))
+				continue
+			}
+			v, err := parseEnum(fd, s)
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+	default:
+		return fmt.Errorf("pgproto: repeated field %s of kind %s is not supported",
+			fd.FullName(), fd.Kind())
+	}
+
+	m.Set(fd, protoreflect.ValueOfList(list))
+	return nil
+}
+
+func scanScalar(fd protoreflect.FieldDescriptor, rd types.Reader, n int) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		var v bool
+		err := types.Scan(&v, rd, n)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var v int32
+		err := types.Scan(&v, rd, n)
+		return protoreflect.ValueOfInt32(v), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var v int64
+		err := types.Scan(&v, rd, n)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var v uint32
+		err := types.Scan(&v, rd, n)
+		return protoreflect.ValueOfUint32(v), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var v uint64
+		err := types.Scan(&v, rd, n)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		var v float32
+		err := types.Scan(&v, rd, n)
+		return protoreflect.ValueOfFloat32(v), err
+	case protoreflect.DoubleKind:
+		var v float64
+		err := types.Scan(&v, rd, n)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		var v string
+		err := types.Scan(&v, rd, n)
+		return protoreflect.ValueOfString(v), err
+	case protoreflect.BytesKind:
+		var v []byte
+		err := types.Scan(&v, rd, n)
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		var s string
+		if err := types.Scan(&s, rd, n); err != nil {
+			return protoreflect.Value{}, err
+		}
+		return parseEnum(fd, s)
+	default:
+		return protoreflect.Value{}, fmt.Errorf(
+			"pgproto: field %s of kind %s is not supported", fd.FullName(), fd.Kind())
+	}
+}
+
+// parseEnum parses the enum value by name, e.g. STATUS_ACTIVE, or by number.
+func parseEnum(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+		return protoreflect.ValueOfEnum(ev.Number()), nil
+	}
+	num, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return protoreflect.Value{}, fmt.Errorf(
+			"pgproto: can't parse %q as enum %s", s, fd.Enum().FullName())
+	}
+	return protoreflect.ValueOfEnum(protoreflect.EnumNumber(num)), nil
+}
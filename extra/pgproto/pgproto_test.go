@@ -0,0 +1,343 @@
+package pgproto
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-pg/pg/v10/types"
+)
+
+// bytesReader is a types.Reader over a column value.
+type bytesReader struct {
+	s []byte
+	i int
+}
+
+var _ types.Reader = (*bytesReader)(nil)
+
+func (r *bytesReader) Buffered() int {
+	return len(r.s) - r.i
+}
+
+func (r *bytesReader) Bytes() []byte {
+	return r.s[r.i:]
+}
+
+func (r *bytesReader) Read(b []byte) (int, error) {
+	if r.i >= len(r.s) {
+		return 0, io.EOF
+	}
+	n := copy(b, r.s[r.i:])
+	r.i += n
+	return n, nil
+}
+
+func (r *bytesReader) ReadByte() (byte, error) {
+	if r.i >= len(r.s) {
+		return 0, io.EOF
+	}
+	c := r.s[r.i]
+	r.i++
+	return c, nil
+}
+
+func (r *bytesReader) UnreadByte() error {
+	if r.i <= 0 {
+		return errors.New("UnreadByte: at beginning of slice")
+	}
+	r.i--
+	return nil
+}
+
+func (r *bytesReader) ReadSlice(delim byte) ([]byte, error) {
+	if i := bytes.IndexByte(r.s[r.i:], delim); i >= 0 {
+		line := r.s[r.i : r.i+i+1]
+		r.i += i + 1
+		return line, nil
+	}
+	line := r.s[r.i:]
+	r.i = len(r.s)
+	return line, io.EOF
+}
+
+func (r *bytesReader) Discard(n int) (int, error) {
+	if n > r.Buffered() {
+		n = r.Buffered()
+	}
+	r.i += n
+	return n, nil
+}
+
+func (r *bytesReader) ReadFull() ([]byte, error) {
+	b := make([]byte, r.Buffered())
+	copy(b, r.s[r.i:])
+	r.i = len(r.s)
+	return b, nil
+}
+
+func (r *bytesReader) ReadFullTemp() ([]byte, error) {
+	b := r.s[r.i:]
+	r.i = len(r.s)
+	return b, nil
+}
+
+// scanRow scans the columns into the next message of the model.
+// Nil values are scanned as NULL.
+func scanRow(t *testing.T, model orm.HooklessModel, row map[string]interface{}) {
+	scanner := model.NextColumnScanner()
+	for name, value := range row {
+		col := types.ColumnInfo{Name: name}
+		var err error
+		if value == nil {
+			err = scanner.ScanColumn(col, &bytesReader{}, -1)
+		} else {
+			b := []byte(value.(string))
+			err = scanner.ScanColumn(col, &bytesReader{s: b}, len(b))
+		}
+		if err != nil {
+			t.Fatalf("column=%s: %s", name, err)
+		}
+	}
+}
+
+// userDescriptor describes the message:
+//
+//    message User {
+//      int64 id = 1;
+//      string name = 2;
+//      google.protobuf.Timestamp created_at = 3;
+//      google.protobuf.StringValue nickname = 4;
+//      repeated string tags = 5;
+//      repeated int32 scores = 6;
+//      repeated uint32 counts = 7;
+//      repeated uint64 totals = 8;
+//    }
+//
+// The descriptor is built once, because fields are cached by message name.
+func userDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	if userMD != nil {
+		return userMD
+	}
+
+	field := func(
+		name string, num int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string,
+	) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(num),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   typ.Enum(),
+		}
+		if typeName != "" {
+			fd.TypeName = proto.String(typeName)
+		}
+		return fd
+	}
+
+	tags := field("tags", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")
+	tags.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	scores := field("scores", 6, descriptorpb.FieldDescriptorProto_TYPE_INT32, "")
+	scores.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	counts := field("counts", 7, descriptorpb.FieldDescriptorProto_TYPE_UINT32, "")
+	counts.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	totals := field("totals", 8, descriptorpb.FieldDescriptorProto_TYPE_UINT64, "")
+	totals.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("pgproto_test.proto"),
+		Package: proto.String("pgproto.test"),
+		Syntax:  proto.String("proto3"),
+		Dependency: []string{
+			"google/protobuf/timestamp.proto",
+			"google/protobuf/wrappers.proto",
+		},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+				field("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("created_at", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+					".google.protobuf.Timestamp"),
+				field("nickname", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+					".google.protobuf.StringValue"),
+				tags,
+				scores,
+				counts,
+				totals,
+			},
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userMD = file.Messages().ByName("User")
+	return userMD
+}
+
+var userMD protoreflect.MessageDescriptor
+
+func TestScan(t *testing.T) {
+	md := userDescriptor(t)
+	fields := md.Fields()
+	msg := dynamicpb.NewMessage(md)
+
+	model := Scan(msg)
+	if err := model.Init(); err != nil {
+		t.Fatal(err)
+	}
+	scanRow(t, model, map[string]interface{}{
+		"id":        "1",
+		"name":      "alice",
+		"createdAt": "2020-01-02 03:04:05+00",
+		"nickname":  "al",
+		"tags":      "{a,b}",
+		"scores":    "{1,2,3}",
+		"counts":    "{4294967295}",
+		"totals":    "{18446744073709551615}",
+		"_rank":     "1",
+	})
+
+	if got := msg.Get(fields.ByName("id")).Int(); got != 1 {
+		t.Fatalf("got id=%d, wanted 1", got)
+	}
+	if got := msg.Get(fields.ByName("name")).String(); got != "alice" {
+		t.Fatalf("got name=%q, wanted alice", got)
+	}
+
+	createdAt := msg.Get(fields.ByName("created_at")).Message().Interface().(*timestamppb.Timestamp)
+	wantedTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if got := createdAt.AsTime(); !got.Equal(wantedTime) {
+		t.Fatalf("got created_at=%s, wanted %s", got, wantedTime)
+	}
+
+	nickname := msg.Get(fields.ByName("nickname")).Message()
+	if got := nickname.Get(nickname.Descriptor().Fields().ByName("value")).String(); got != "al" {
+		t.Fatalf("got nickname=%q, wanted al", got)
+	}
+
+	tags := msg.Get(fields.ByName("tags")).List()
+	if tags.Len() != 2 || tags.Get(0).String() != "a" || tags.Get(1).String() != "b" {
+		t.Fatalf("got %d tags, wanted [a b]", tags.Len())
+	}
+	scores := msg.Get(fields.ByName("scores")).List()
+	if scores.Len() != 3 || scores.Get(2).Int() != 3 {
+		t.Fatalf("got %d scores, wanted [1 2 3]", scores.Len())
+	}
+	counts := msg.Get(fields.ByName("counts")).List()
+	if counts.Len() != 1 || counts.Get(0).Uint() != math.MaxUint32 {
+		t.Fatalf("got %d counts, wanted [%d]", counts.Len(), uint32(math.MaxUint32))
+	}
+	totals := msg.Get(fields.ByName("totals")).List()
+	if totals.Len() != 1 || totals.Get(0).Uint() != math.MaxUint64 {
+		t.Fatalf("got %d totals, wanted [%d]", totals.Len(), uint64(math.MaxUint64))
+	}
+
+	// NULL clears the field set by the previous row.
+	scanRow(t, model, map[string]interface{}{
+		"name":       nil,
+		"created_at": nil,
+		"nickname":   nil,
+		"tags":       nil,
+	})
+	for _, name := range []protoreflect.Name{"name", "created_at", "nickname", "tags"} {
+		if msg.Has(fields.ByName(name)) {
+			t.Fatalf("%s is not cleared by NULL", name)
+		}
+	}
+	if !msg.Has(fields.ByName("id")) {
+		t.Fatalf("id is cleared")
+	}
+
+	// Init resets the message before the query is executed again.
+	if err := model.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Has(fields.ByName("id")) {
+		t.Fatalf("id is not reset by Init")
+	}
+}
+
+func TestScanListOverflow(t *testing.T) {
+	model := Scan(dynamicpb.NewMessage(userDescriptor(t)))
+	if err := model.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := model.NextColumnScanner()
+	for _, test := range []struct {
+		name   string
+		value  string
+		wanted string
+	}{
+		{"scores", "{1,2147483648}", "pgproto: value 2147483648 overflows field pgproto.test.User.scores"},
+		{"scores", "{-2147483649}", "pgproto: value -2147483649 overflows field pgproto.test.User.scores"},
+		{"counts", "{4294967296}", "pgproto: value 4294967296 overflows field pgproto.test.User.counts"},
+	} {
+		b := []byte(test.value)
+		err := scanner.ScanColumn(types.ColumnInfo{Name: test.name}, &bytesReader{s: b}, len(b))
+		if err == nil || err.Error() != test.wanted {
+			t.Fatalf("got %v, wanted %q", err, test.wanted)
+		}
+	}
+}
+
+func TestScanUnknownColumn(t *testing.T) {
+	model := Scan(dynamicpb.NewMessage(userDescriptor(t)))
+	if err := model.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := model.NextColumnScanner()
+	for _, name := range []string{"unknown", ""} {
+		err := scanner.ScanColumn(types.ColumnInfo{Name: name}, &bytesReader{}, 0)
+		wanted := "pgproto: can't find column=" + name + " in message=pgproto.test.User"
+		if err == nil || err.Error() != wanted {
+			t.Fatalf("got %v, wanted %q", err, wanted)
+		}
+	}
+}
+
+func TestScanSlice(t *testing.T) {
+	values := []*wrapperspb.StringValue{wrapperspb.String("old")}
+	model := ScanSlice(&values)
+	if err := model.Init(); err != nil {
+		t.Fatal(err)
+	}
+	scanRow(t, model, map[string]interface{}{"value": "a"})
+	scanRow(t, model, map[string]interface{}{"value": "b"})
+
+	if len(values) != 2 || values[0].GetValue() != "a" || values[1].GetValue() != "b" {
+		t.Fatalf("got %v, wanted [a b]", values)
+	}
+}
+
+func TestScanSliceUnsupported(t *testing.T) {
+	for _, test := range []struct {
+		slice  interface{}
+		wanted string
+	}{
+		{nil, "pgproto: ScanSlice(nil)"},
+		{[]*wrapperspb.StringValue{}, "pgproto: ScanSlice(non-pointer []*wrapperspb.StringValue)"},
+		{&[]string{}, "pgproto: ScanSlice(unsupported *[]string)"},
+		{(*[]*wrapperspb.StringValue)(nil), "pgproto: ScanSlice(unsupported *[]*wrapperspb.StringValue)"},
+	} {
+		err := ScanSlice(test.slice).Init()
+		if err == nil || err.Error() != test.wanted {
+			t.Fatalf("got %v, wanted %q", err, test.wanted)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package pg
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-pg/pg/v10/internal"
+)
+
+// addrList dials a list of addresses in order (primary-first) skipping
+// addresses that recently failed to connect.
+type addrList struct {
+	addrs    []string
+	cooldown time.Duration
+	dial     func(ctx context.Context, addr string) (net.Conn, error)
+
+	mu        sync.Mutex
+	downUntil []time.Time
+}
+
+func newAddrList(
+	addrs []string,
+	cooldown time.Duration,
+	dial func(ctx context.Context, addr string) (net.Conn, error),
+) *addrList {
+	return &addrList{
+		addrs:     addrs,
+		cooldown:  cooldown,
+		dial:      dial,
+		downUntil: make([]time.Time, len(addrs)),
+	}
+}
+
+// Dial tries addresses that are not marked as down first and then the rest
+// so connection is still attempted when all addresses are marked as down.
+func (l *addrList) Dial(ctx context.Context) (net.Conn, error) {
+	isDown := l.downAddrs()
+
+	var lastErr error
+	for _, down := range []bool{false, true} {
+		for i, addr := range l.addrs {
+			if isDown[i] != down {
+				continue
+			}
+
+			cn, err := l.dial(ctx, addr)
+			if err == nil {
+				l.markUp(i)
+				return &addrConn{Conn: cn, addr: addr}, nil
+			}
+
+			internal.Logger.Printf(ctx, "dialing %s failed: %s", addr, err)
+			l.markDown(i)
+			lastErr = err
+
+			if ctx.Err() != nil {
+				return nil, lastErr
+			}
+		}
+	}
+	return nil, lastErr
+}
+
+func (l *addrList) downAddrs() []bool {
+	now := time.Now()
+	isDown := make([]bool, len(l.addrs))
+
+	l.mu.Lock()
+	for i, until := range l.downUntil {
+		isDown[i] = now.Before(until)
+	}
+	l.mu.Unlock()
+
+	return isDown
+}
+
+func (l *addrList) markDown(i int) {
+	l.mu.Lock()
+	l.downUntil[i] = time.Now().Add(l.cooldown)
+	l.mu.Unlock()
+}
+
+func (l *addrList) markUp(i int) {
+	l.mu.Lock()
+	l.downUntil[i] = time.Time{}
+	l.mu.Unlock()
+}
+
+// addrConn remembers the address the connection was dialed to, e.g. to
+// verify the server name when TLS is enabled.
+type addrConn struct {
+	net.Conn
+	addr string
+}
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
 )
 
 type HookTest struct {
@@ -195,6 +196,69 @@ var _ = Describe("HookTest", func() {
 	})
 })
 
+type GlobalHookTest struct {
+	Id    int
+	Value string
+}
+
+var globalHookOps []string
+
+func init() {
+	orm.AddGlobalHook(func(c context.Context, evt *orm.GlobalHookEvent) (context.Context, error) {
+		if evt.Model.Table().TypeName != "GlobalHookTest" {
+			return c, nil
+		}
+		if evt.Op == orm.BeforeUpdateOp && !evt.Model.IsNil() {
+			evt.Model.Value().FieldByName("Value").SetString("stamped")
+		}
+		globalHookOps = append(globalHookOps, evt.Op.String())
+		return c, nil
+	})
+}
+
+var _ = Describe("GlobalHook", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+
+		_, err := db.Exec("CREATE TEMP TABLE global_hook_tests (id int, value text)")
+		Expect(err).NotTo(HaveOccurred())
+
+		globalHookOps = nil
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+	})
+
+	It("is called for insert, update and delete", func() {
+		model := &GlobalHookTest{Id: 1}
+
+		_, err := db.Model(model).Insert()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = db.Model(model).WherePK().Update()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Value).To(Equal("stamped"))
+
+		_, err = db.Model(model).WherePK().Delete()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(globalHookOps).To(Equal([]string{
+			"BeforeInsert", "AfterInsert",
+			"BeforeUpdate", "AfterUpdate",
+			"BeforeDelete", "AfterDelete",
+		}))
+	})
+
+	It("is called for nil models", func() {
+		_, err := db.Model((*GlobalHookTest)(nil)).Where("id = 123").Delete()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(globalHookOps).To(Equal([]string{"BeforeDelete", "AfterDelete"}))
+	})
+})
+
 type queryHookTest struct {
 	beforeQueryMethod func(context.Context, *pg.QueryEvent) (context.Context, error)
 	afterQueryMethod  func(context.Context, *pg.QueryEvent) error
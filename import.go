@@ -0,0 +1,420 @@
+package pg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/go-pg/pg/v10/internal/pool"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-pg/pg/v10/types"
+)
+
+// ImportOptions configures ImportCSV and ImportJSONLines.
+type ImportOptions struct {
+	// Columns are the table columns to import.
+	//
+	// CSV records without Header are mapped to Columns by position.
+	// With Header only the listed columns are imported. JSON lines require
+	// Columns or ColumnMap.
+	Columns []string
+	// ColumnMap maps CSV header names or JSON keys to table columns.
+	// Names missing from the map are skipped. By default names are used
+	// as column names.
+	ColumnMap map[string]string
+
+	// Header tells that the first CSV record contains field names.
+	Header bool
+	// Comma is the CSV field delimiter. Defaults to ','.
+	Comma rune
+	// Null is the CSV field value imported as NULL. Defaults to an empty
+	// string, i.e. empty fields are NULL like in PostgreSQL CSV format.
+	Null string
+}
+
+// ImportError is returned when an imported record is malformed.
+// Line is the 1-based line number in the input.
+type ImportError struct {
+	Line int
+	Err  error
+}
+
+func (err *ImportError) Error() string {
+	return fmt.Sprintf("pg: import failed on line %d: %s", err.Line, err.Err)
+}
+
+// ImportCSV streams CSV records from the reader into the table using
+// COPY FROM STDIN. Records are converted one by one so the input is never
+// buffered as a whole. The import is aborted on the first malformed record
+// and nothing is inserted.
+func (db *baseDB) ImportCSV(
+	c context.Context, table string, r io.Reader, opt *ImportOptions,
+) (res Result, err error) {
+	src, err := newCSVImportSource(r, opt)
+	if err != nil {
+		return nil, err
+	}
+	err = db.withConn(c, func(c context.Context, cn *pool.Conn) error {
+		res, err = db.importFrom(c, cn, table, src)
+		return err
+	})
+	return res, err
+}
+
+// ImportJSONLines is like ImportCSV, but reads one JSON object per line.
+// Keys that don't map to a column are ignored and missing keys are
+// imported as NULL. Strings are imported as is and other values, including
+// objects and arrays, using their JSON representation.
+func (db *baseDB) ImportJSONLines(
+	c context.Context, table string, r io.Reader, opt *ImportOptions,
+) (res Result, err error) {
+	src, err := newJSONImportSource(r, opt)
+	if err != nil {
+		return nil, err
+	}
+	err = db.withConn(c, func(c context.Context, cn *pool.Conn) error {
+		res, err = db.importFrom(c, cn, table, src)
+		return err
+	})
+	return res, err
+}
+
+func (db *baseDB) importFrom(
+	c context.Context, cn *pool.Conn, table string, src importSource,
+) (Result, error) {
+	q := &importQuery{
+		table:   table,
+		columns: src.columns(),
+	}
+	return db.copyFrom(c, cn, &importReader{src: src}, q)
+}
+
+//------------------------------------------------------------------------------
+
+type importQuery struct {
+	table   string
+	columns []string
+}
+
+var _ orm.QueryAppender = (*importQuery)(nil)
+
+func (q *importQuery) AppendQuery(_ orm.QueryFormatter, b []byte) ([]byte, error) {
+	b = append(b, "COPY "...)
+	b = types.AppendIdent(b, q.table, 1)
+	b = append(b, " ("...)
+	for i, col := range q.columns {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = types.AppendIdent(b, col, 1)
+	}
+	b = append(b, ") FROM STDIN"...)
+	return b, nil
+}
+
+//------------------------------------------------------------------------------
+
+type importSource interface {
+	columns() []string
+	// next appends the next row in COPY text format.
+	next(b []byte) ([]byte, error)
+}
+
+// importReader encodes rows from the source on demand.
+type importReader struct {
+	src importSource
+	buf []byte
+	off int
+	err error
+}
+
+func (r *importReader) Read(p []byte) (int, error) {
+	if r.off == len(r.buf) {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		r.buf, r.off = r.buf[:0], 0
+		for len(r.buf) < len(p) {
+			var err error
+			r.buf, err = r.src.next(r.buf)
+			if err == io.EOF {
+				r.err = err
+				break
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+		if len(r.buf) == 0 {
+			return 0, r.err
+		}
+	}
+
+	n := copy(p, r.buf[r.off:])
+	r.off += n
+	return n, nil
+}
+
+func appendCopyText(b []byte, s []byte) []byte {
+	for _, c := range s {
+		switch c {
+		case '\\':
+			b = append(b, '\\', '\\')
+		case '\t':
+			b = append(b, '\\', 't')
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+func appendCopyNull(b []byte) []byte {
+	return append(b, '\\', 'N')
+}
+
+//------------------------------------------------------------------------------
+
+type csvImportSource struct {
+	rd    *csv.Reader
+	cols  []string
+	index []int // record field index of every column
+	null  string
+}
+
+func newCSVImportSource(r io.Reader, opt *ImportOptions) (*csvImportSource, error) {
+	if opt == nil {
+		opt = new(ImportOptions)
+	}
+
+	src := &csvImportSource{
+		rd:   csv.NewReader(r),
+		null: opt.Null,
+	}
+	if opt.Comma != 0 {
+		src.rd.Comma = opt.Comma
+	}
+
+	if !opt.Header {
+		if len(opt.Columns) == 0 {
+			return nil, errors.New("pg: ImportCSV without Header requires Columns")
+		}
+		src.cols = opt.Columns
+		src.index = make([]int, len(opt.Columns))
+		for i := range src.index {
+			src.index[i] = i
+		}
+		src.rd.FieldsPerRecord = len(opt.Columns)
+		return src, nil
+	}
+
+	header, err := src.rd.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("pg: CSV header is missing")
+		}
+		return nil, csvImportError(err)
+	}
+
+	for i, name := range header {
+		col := name
+		if opt.ColumnMap != nil {
+			var ok bool
+			col, ok = opt.ColumnMap[name]
+			if !ok {
+				continue
+			}
+		}
+		if len(opt.Columns) > 0 && !stringsContain(opt.Columns, col) {
+			continue
+		}
+		src.cols = append(src.cols, col)
+		src.index = append(src.index, i)
+	}
+
+	for _, col := range opt.Columns {
+		if !stringsContain(src.cols, col) {
+			return nil, fmt.Errorf("pg: column %q is missing in CSV header", col)
+		}
+	}
+	if len(src.cols) == 0 {
+		return nil, errors.New("pg: CSV header does not have columns to import")
+	}
+
+	return src, nil
+}
+
+func (src *csvImportSource) columns() []string {
+	return src.cols
+}
+
+func (src *csvImportSource) next(b []byte) ([]byte, error) {
+	record, err := src.rd.Read()
+	if err != nil {
+		if err == io.EOF {
+			return b, err
+		}
+		return b, csvImportError(err)
+	}
+
+	for i, idx := range src.index {
+		if i > 0 {
+			b = append(b, '\t')
+		}
+		if field := record[idx]; field == src.null {
+			b = appendCopyNull(b)
+		} else {
+			b = appendCopyText(b, []byte(field))
+		}
+	}
+	b = append(b, '\n')
+
+	return b, nil
+}
+
+func csvImportError(err error) error {
+	if parseErr, ok := err.(*csv.ParseError); ok {
+		return &ImportError{
+			Line: parseErr.Line,
+			Err:  parseErr.Err,
+		}
+	}
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+type jsonImportSource struct {
+	rd        *bufio.Reader
+	line      int
+	cols      []string
+	colIndex  map[string]int
+	columnMap map[string]string
+	values    []json.RawMessage
+}
+
+func newJSONImportSource(r io.Reader, opt *ImportOptions) (*jsonImportSource, error) {
+	if opt == nil {
+		opt = new(ImportOptions)
+	}
+
+	src := &jsonImportSource{
+		rd:        bufio.NewReader(r),
+		cols:      opt.Columns,
+		columnMap: opt.ColumnMap,
+	}
+
+	if len(src.cols) == 0 {
+		for _, col := range opt.ColumnMap {
+			if !stringsContain(src.cols, col) {
+				src.cols = append(src.cols, col)
+			}
+		}
+		sort.Strings(src.cols)
+	}
+	if len(src.cols) == 0 {
+		return nil, errors.New("pg: ImportJSONLines requires Columns or ColumnMap")
+	}
+
+	src.colIndex = make(map[string]int, len(src.cols))
+	for i, col := range src.cols {
+		src.colIndex[col] = i
+	}
+	src.values = make([]json.RawMessage, len(src.cols))
+
+	return src, nil
+}
+
+func (src *jsonImportSource) columns() []string {
+	return src.cols
+}
+
+func (src *jsonImportSource) next(b []byte) ([]byte, error) {
+	var line []byte
+	for {
+		var err error
+		line, err = src.rd.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return b, err
+		}
+		src.line++
+
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			break
+		}
+		if err == io.EOF {
+			return b, err
+		}
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(line, &m); err != nil {
+		return b, &ImportError{
+			Line: src.line,
+			Err:  err,
+		}
+	}
+
+	for i := range src.values {
+		src.values[i] = nil
+	}
+	for key, value := range m {
+		col := key
+		if src.columnMap != nil {
+			var ok bool
+			col, ok = src.columnMap[key]
+			if !ok {
+				continue
+			}
+		}
+		if idx, ok := src.colIndex[col]; ok {
+			src.values[idx] = value
+		}
+	}
+
+	for i, value := range src.values {
+		if i > 0 {
+			b = append(b, '\t')
+		}
+
+		switch {
+		case value == nil || string(value) == "null":
+			b = appendCopyNull(b)
+		case value[0] == '"':
+			var s string
+			if err := json.Unmarshal(value, &s); err != nil {
+				return b, &ImportError{
+					Line: src.line,
+					Err:  err,
+				}
+			}
+			b = appendCopyText(b, []byte(s))
+		default:
+			b = appendCopyText(b, value)
+		}
+	}
+	b = append(b, '\n')
+
+	return b, nil
+}
+
+func stringsContain(ss []string, s string) bool {
+	for _, str := range ss {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
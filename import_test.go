@@ -0,0 +1,117 @@
+package pg
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func readImportSource(t *testing.T, src importSource) (string, error) {
+	t.Helper()
+	b, err := ioutil.ReadAll(&importReader{src: src})
+	return string(b), err
+}
+
+func TestImportCSV(t *testing.T) {
+	input := "name,id,skip\n" +
+		"\"tab\there\",1,x\n" +
+		"\"multi\nline\",2,x\n" +
+		",3,x\n" +
+		"back\\slash,4,x\n"
+
+	src, err := newCSVImportSource(strings.NewReader(input), &ImportOptions{
+		Header:    true,
+		ColumnMap: map[string]string{"id": "id", "name": "title"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := (&importQuery{table: "books", columns: src.columns()}).AppendQuery(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(q); got != `COPY "books" ("title", "id") FROM STDIN` {
+		t.Fatalf("got %q", got)
+	}
+
+	got, err := readImportSource(t, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := "tab\\there\t1\n" +
+		"multi\\nline\t2\n" +
+		"\\N\t3\n" +
+		"back\\\\slash\t4\n"
+	if got != wanted {
+		t.Fatalf("got %q, wanted %q", got, wanted)
+	}
+}
+
+func TestImportCSVErrors(t *testing.T) {
+	_, err := newCSVImportSource(strings.NewReader("1,2\n"), nil)
+	if err == nil || err.Error() != "pg: ImportCSV without Header requires Columns" {
+		t.Fatalf("got %v", err)
+	}
+
+	_, err = newCSVImportSource(strings.NewReader("a,b\n"), &ImportOptions{
+		Header:  true,
+		Columns: []string{"c"},
+	})
+	if err == nil || err.Error() != `pg: column "c" is missing in CSV header` {
+		t.Fatalf("got %v", err)
+	}
+
+	src, err := newCSVImportSource(strings.NewReader("1,a\n2,b,c\n"), &ImportOptions{
+		Columns: []string{"id", "name"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = readImportSource(t, src)
+	importErr, ok := err.(*ImportError)
+	if !ok || importErr.Line != 2 {
+		t.Fatalf("got %#v, wanted ImportError on line 2", err)
+	}
+}
+
+func TestImportJSONLines(t *testing.T) {
+	input := `{"id": 1, "name": "a\tb", "tags": ["x"], "extra": true}` + "\n" +
+		"\n" +
+		`{"id": 2, "name": null}` + "\r\n" +
+		`{"id": 3, "active": false}`
+
+	src, err := newJSONImportSource(strings.NewReader(input), &ImportOptions{
+		Columns: []string{"id", "name", "tags", "active"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readImportSource(t, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := "1\ta\\tb\t[\"x\"]\t\\N\n" +
+		"2\t\\N\t\\N\t\\N\n" +
+		"3\t\\N\t\\N\tfalse\n"
+	if got != wanted {
+		t.Fatalf("got %q, wanted %q", got, wanted)
+	}
+
+	src, err = newJSONImportSource(strings.NewReader("{\"id\": 1}\n\n{\"id\": \n"), &ImportOptions{
+		ColumnMap: map[string]string{"id": "book_id"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cols := src.columns(); len(cols) != 1 || cols[0] != "book_id" {
+		t.Fatalf("got %q", cols)
+	}
+
+	_, err = readImportSource(t, src)
+	importErr, ok := err.(*ImportError)
+	if !ok || importErr.Line != 3 {
+		t.Fatalf("got %#v, wanted ImportError on line 3", err)
+	}
+}
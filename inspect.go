@@ -0,0 +1,154 @@
+package pg
+
+import (
+	"context"
+	"strings"
+)
+
+// TableSchema describes a table as reported by the database.
+type TableSchema struct {
+	Schema      string
+	Name        string
+	Columns     []ColumnSchema
+	Indexes     []IndexSchema
+	Constraints []ConstraintSchema
+}
+
+// Column returns the column with the given name or nil.
+func (t *TableSchema) Column(name string) *ColumnSchema {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// ColumnSchema describes a table column.
+type ColumnSchema struct {
+	Name string
+	// DataType is the SQL type of the column, e.g. "bigint" or "text[]".
+	DataType   string
+	IsNullable bool
+	// Default is the column default expression or an empty string.
+	Default      string
+	IsPrimaryKey bool
+}
+
+// IndexSchema describes a table index.
+type IndexSchema struct {
+	Name      string
+	IsUnique  bool
+	IsPrimary bool
+	// Definition is the CREATE INDEX statement for the index.
+	Definition string
+}
+
+// ConstraintSchema describes a table constraint.
+type ConstraintSchema struct {
+	Name string
+	// Type is one of PRIMARY KEY, FOREIGN KEY, UNIQUE, CHECK, or EXCLUDE.
+	Type    string
+	Columns []string `pg:",array"`
+	// ForeignTable and ForeignColumns are only set for foreign keys.
+	ForeignTable   string
+	ForeignColumns []string `pg:",array"`
+	Definition     string
+}
+
+const inspectColumnsQuery = `
+SELECT
+  a.attname AS name,
+  format_type(a.atttypid, a.atttypmod) AS data_type,
+  NOT a.attnotnull AS is_nullable,
+  coalesce(pg_get_expr(d.adbin, d.adrelid), '') AS default
+FROM pg_attribute AS a
+LEFT JOIN pg_attrdef AS d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE a.attrelid = ?::regclass AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attnum
+`
+
+const inspectIndexesQuery = `
+SELECT
+  c.relname AS name,
+  i.indisunique AS is_unique,
+  i.indisprimary AS is_primary,
+  pg_get_indexdef(i.indexrelid) AS definition
+FROM pg_index AS i
+JOIN pg_class AS c ON c.oid = i.indexrelid
+WHERE i.indrelid = ?::regclass
+ORDER BY c.relname
+`
+
+const inspectConstraintsQuery = `
+SELECT
+  c.conname AS name,
+  CASE c.contype
+    WHEN 'p' THEN 'PRIMARY KEY'
+    WHEN 'f' THEN 'FOREIGN KEY'
+    WHEN 'u' THEN 'UNIQUE'
+    WHEN 'c' THEN 'CHECK'
+    WHEN 'x' THEN 'EXCLUDE'
+    ELSE c.contype::text
+  END AS type,
+  ARRAY(
+    SELECT a.attname
+    FROM unnest(c.conkey) WITH ORDINALITY AS k (attnum, n)
+    JOIN pg_attribute AS a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+    ORDER BY k.n
+  )::text[] AS columns,
+  CASE WHEN c.confrelid = 0 THEN '' ELSE c.confrelid::regclass::text END AS foreign_table,
+  ARRAY(
+    SELECT a.attname
+    FROM unnest(c.confkey) WITH ORDINALITY AS k (attnum, n)
+    JOIN pg_attribute AS a ON a.attrelid = c.confrelid AND a.attnum = k.attnum
+    ORDER BY k.n
+  )::text[] AS foreign_columns,
+  pg_get_constraintdef(c.oid) AS definition
+FROM pg_constraint AS c
+WHERE c.conrelid = ?::regclass
+ORDER BY c.conname
+`
+
+// Inspect reads columns, indexes, and constraints of the table from
+// pg_catalog. Table name can be qualified with a schema name, e.g.
+// "public.books". Otherwise, the current schema is used.
+func (db *baseDB) Inspect(ctx context.Context, table string) (*TableSchema, error) {
+	t := new(TableSchema)
+	if i := strings.IndexByte(table, '.'); i >= 0 {
+		t.Schema = table[:i]
+		t.Name = table[i+1:]
+	} else {
+		_, err := db.QueryOneContext(ctx, Scan(&t.Schema), "SELECT current_schema()")
+		if err != nil {
+			return nil, err
+		}
+		t.Name = table
+	}
+
+	ident := Ident(t.Schema + "." + t.Name)
+	regclass := SafeQuery("?", ident).Value()
+
+	if _, err := db.QueryContext(ctx, &t.Columns, inspectColumnsQuery, string(regclass)); err != nil {
+		return nil, err
+	}
+	if _, err := db.QueryContext(ctx, &t.Indexes, inspectIndexesQuery, string(regclass)); err != nil {
+		return nil, err
+	}
+	if _, err := db.QueryContext(ctx, &t.Constraints, inspectConstraintsQuery, string(regclass)); err != nil {
+		return nil, err
+	}
+
+	for _, c := range t.Constraints {
+		if c.Type != "PRIMARY KEY" {
+			continue
+		}
+		for _, name := range c.Columns {
+			if col := t.Column(name); col != nil {
+				col.IsPrimaryKey = true
+			}
+		}
+	}
+
+	return t, nil
+}
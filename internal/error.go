@@ -7,6 +7,13 @@ import (
 var (
 	ErrNoRows    = Errorf("pg: no rows in result set")
 	ErrMultiRows = Errorf("pg: multiple rows in result set")
+
+	ErrTooManyRows = Errorf("pg: too many rows in result set")
+
+	ErrStaleObject = Errorf("pg: stale object (row was updated or deleted concurrently)")
+
+	ErrNoLastInsertId = Errorf("pg: LastInsertId is only available for single-row model inserts " +
+		"with an integer primary key")
 )
 
 type Error struct {
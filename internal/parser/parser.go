@@ -139,3 +139,120 @@ func isNum(c byte) bool {
 func isAlpha(c byte) bool {
 	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
 }
+
+// ReadLiteral reads a string literal, quoted identifier, dollar-quoted
+// string, or comment starting at the current position, so parameter
+// placeholders inside of them can be skipped. It returns false and does
+// not advance if there is none. Unterminated literals and comments are
+// read till the end.
+func (p *Parser) ReadLiteral() ([]byte, bool) {
+	start := p.i
+	end := p.literalEnd()
+	if end == start {
+		return nil, false
+	}
+	p.i = end
+	return p.b[start:end], true
+}
+
+func (p *Parser) literalEnd() int {
+	b, i := p.b, p.i
+	if i >= len(b) {
+		return i
+	}
+
+	switch c := b[i]; c {
+	case '\'', '"':
+		return quotedEnd(b, i+1, c, false)
+	case 'E', 'e':
+		if i+1 < len(b) && b[i+1] == '\'' && (i == 0 || !isIdent(b[i-1])) {
+			return quotedEnd(b, i+2, '\'', true)
+		}
+	case '-':
+		if i+1 < len(b) && b[i+1] == '-' {
+			if ind := bytes.IndexByte(b[i:], '\n'); ind != -1 {
+				return i + ind + 1
+			}
+			return len(b)
+		}
+	case '/':
+		if i+1 < len(b) && b[i+1] == '*' {
+			return blockCommentEnd(b, i+2)
+		}
+	case '$':
+		if i > 0 && isIdent(b[i-1]) {
+			return i
+		}
+		if tag := dollarQuoteTag(b[i:]); tag != nil {
+			start := i + len(tag)
+			if ind := bytes.Index(b[start:], tag); ind != -1 {
+				return start + ind + len(tag)
+			}
+			return len(b)
+		}
+	}
+	return i
+}
+
+// quotedEnd returns the position after the closing quote. Quotes are
+// escaped by doubling them and, in escape strings, with a backslash.
+func quotedEnd(b []byte, i int, quote byte, backslash bool) int {
+	for i < len(b) {
+		c := b[i]
+		i++
+		switch {
+		case backslash && c == '\\':
+			i++
+		case c == quote:
+			if i < len(b) && b[i] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(b)
+}
+
+// blockCommentEnd returns the position after the end of a block comment.
+// Block comments can be nested.
+func blockCommentEnd(b []byte, i int) int {
+	depth := 1
+	for i+1 < len(b) {
+		switch {
+		case b[i] == '/' && b[i+1] == '*':
+			depth++
+			i += 2
+		case b[i] == '*' && b[i+1] == '/':
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return len(b)
+}
+
+// dollarQuoteTag returns the opening tag of a dollar-quoted string,
+// e.g. $$ or $body$, or nil.
+func dollarQuoteTag(b []byte) []byte {
+	for i := 1; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case c == '$':
+			return b[:i+1]
+		case c == '_' || isAlpha(c):
+		case isNum(c) && i > 1:
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func isIdent(c byte) bool {
+	return isAlpha(c) || isNum(c) || c == '_'
+}
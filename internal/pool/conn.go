@@ -2,6 +2,7 @@ package pool
 
 import (
 	"context"
+	"io"
 	"net"
 	"strconv"
 	"sync/atomic"
@@ -13,6 +14,10 @@ var noDeadline = time.Time{}
 type Conn struct {
 	netConn net.Conn
 	rd      *ReaderContext
+	trace   *tracer
+
+	// Addr is the address the connection was dialed to.
+	Addr string
 
 	ProcessID int32
 	SecretKey int32
@@ -53,6 +58,15 @@ func (cn *Conn) SetNetConn(netConn net.Conn) {
 	}
 }
 
+// SetTrace makes the connection write the type and length of every
+// protocol message it sends and receives to w.
+func (cn *Conn) SetTrace(w io.Writer) {
+	cn.trace = &tracer{
+		w:    w,
+		conn: cn.netConn.LocalAddr().String(),
+	}
+}
+
 func (cn *Conn) LockReader() {
 	if cn.rd != nil {
 		panic("not reached")
@@ -86,6 +100,7 @@ func (cn *Conn) WithReader(
 	}
 
 	rd.bytesRead = 0
+	rd.trace = cn.trace
 
 	if err := fn(rd); err != nil {
 		return err
@@ -119,6 +134,9 @@ func (cn *Conn) writeBuffer(
 	if err := cn.netConn.SetWriteDeadline(cn.deadline(ctx, timeout)); err != nil {
 		return err
 	}
+	if cn.trace != nil {
+		cn.trace.frontend(wb.Bytes)
+	}
 	if _, err := cn.netConn.Write(wb.Bytes); err != nil {
 		return err
 	}
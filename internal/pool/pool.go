@@ -32,7 +32,7 @@ type Stats struct {
 
 	TotalConns uint32 // number of total connections in the pool
 	IdleConns  uint32 // number of idle connections in the pool
-	StaleConns uint32 // number of stale connections removed from the pool
+	StaleConns uint32 // number of idle or aged connections retired by the pool
 }
 
 type Pooler interface {
@@ -241,6 +241,7 @@ func (p *ConnPool) Get(ctx context.Context) (*Conn, error) {
 
 		if p.isStaleConn(cn) {
 			_ = p.CloseConn(cn)
+			atomic.AddUint32(&p.stats.StaleConns, 1)
 			continue
 		}
 
@@ -373,6 +373,58 @@ var _ = Describe("conns reaper", func() {
 	assert("aged")
 })
 
+var _ = Describe("MaxConnAge", func() {
+	const maxAge = time.Hour
+
+	ctx := context.Background()
+	var connPool *pool.ConnPool
+
+	BeforeEach(func() {
+		connPool = pool.NewConnPool(&pool.Options{
+			Dialer:             dummyDialer,
+			PoolSize:           10,
+			MaxConnAge:         maxAge,
+			PoolTimeout:        time.Second,
+			IdleCheckFrequency: time.Hour,
+		})
+	})
+
+	AfterEach(func() {
+		_ = connPool.Close()
+	})
+
+	It("replaces aged connection on Get", func() {
+		cn, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cn.SetCreatedAt(time.Now().Add(-2 * maxAge))
+		connPool.Put(ctx, cn)
+
+		newcn, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newcn).NotTo(BeIdenticalTo(cn))
+		connPool.Put(ctx, newcn)
+
+		Expect(connPool.Len()).To(Equal(1))
+		stats := connPool.Stats()
+		Expect(stats.StaleConns).To(Equal(uint32(1)))
+		Expect(stats.Misses).To(Equal(uint32(2)))
+	})
+
+	It("does not retire connection in use", func() {
+		cn, err := connPool.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cn.SetCreatedAt(time.Now().Add(-2 * maxAge))
+
+		n, err := connPool.ReapStaleConns()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(0))
+		Expect(connPool.Len()).To(Equal(1))
+
+		connPool.Put(ctx, cn)
+		Expect(connPool.IdleLen()).To(Equal(1))
+	})
+})
+
 var _ = Describe("race", func() {
 	ctx := context.Background()
 	var connPool *pool.ConnPool
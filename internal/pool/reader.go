@@ -53,6 +53,16 @@ func (c *ColumnAlloc) Columns() []ColumnInfo {
 type ReaderContext struct {
 	*BufReader
 	ColumnAlloc *ColumnAlloc
+
+	trace *tracer
+}
+
+// TraceMessage traces the received message when the connection
+// has a trace writer. N is the length of the message body.
+func (rd *ReaderContext) TraceMessage(c byte, n int) {
+	if rd.trace != nil {
+		rd.trace.backend(c, n)
+	}
 }
 
 func NewReaderContext() *ReaderContext {
@@ -76,5 +86,6 @@ func GetReaderContext() *ReaderContext {
 
 func PutReaderContext(rd *ReaderContext) {
 	rd.ColumnAlloc.Reset()
+	rd.trace = nil
 	readerPool.Put(rd)
 }
@@ -0,0 +1,107 @@
+package pool
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// tracer writes the type and length of every protocol message exchanged
+// on a connection. The length is the message length field, which
+// includes itself but not the type byte. Message contents are never written, so passwords and
+// startup parameters don't leak into the trace.
+type tracer struct {
+	w    io.Writer
+	conn string
+}
+
+func (t *tracer) frontend(b []byte) {
+	for len(b) >= 5 {
+		var name string
+		var msgLen, size int
+		if b[0] == 0 {
+			// Startup, SSL, and cancel requests don't have a type byte.
+			msgLen = int(binary.BigEndian.Uint32(b))
+			size = msgLen
+			name = untypedMessageName(b)
+		} else {
+			msgLen = int(binary.BigEndian.Uint32(b[1:]))
+			size = msgLen + 1
+			name = messageName(frontendMessages, b[0])
+		}
+		fmt.Fprintf(t.w, "pg: %s -> %s len=%d\n", t.conn, name, msgLen)
+		if size <= 0 || size > len(b) {
+			return
+		}
+		b = b[size:]
+	}
+}
+
+func (t *tracer) backend(c byte, n int) {
+	fmt.Fprintf(t.w, "pg: %s <- %s len=%d\n", t.conn, messageName(backendMessages, c), n+4)
+}
+
+func untypedMessageName(b []byte) string {
+	if len(b) < 8 {
+		return "Unknown"
+	}
+	switch binary.BigEndian.Uint32(b[4:]) {
+	case 196608:
+		return "StartupMessage"
+	case 80877102:
+		return "CancelRequest"
+	case 80877103:
+		return "SSLRequest"
+	case 80877104:
+		return "GSSENCRequest"
+	}
+	return "Unknown"
+}
+
+func messageName(names map[byte]string, c byte) string {
+	if name, ok := names[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown(%q)", c)
+}
+
+var frontendMessages = map[byte]string{
+	'B': "Bind",
+	'C': "Close",
+	'c': "CopyDone",
+	'd': "CopyData",
+	'D': "Describe",
+	'E': "Execute",
+	'f': "CopyFail",
+	'H': "Flush",
+	'P': "Parse",
+	'p': "PasswordMessage",
+	'Q': "Query",
+	'S': "Sync",
+	'X': "Terminate",
+}
+
+var backendMessages = map[byte]string{
+	'1': "ParseComplete",
+	'2': "BindComplete",
+	'3': "CloseComplete",
+	'A': "NotificationResponse",
+	'c': "CopyDone",
+	'C': "CommandComplete",
+	'd': "CopyData",
+	'D': "DataRow",
+	'E': "ErrorResponse",
+	'G': "CopyInResponse",
+	'H': "CopyOutResponse",
+	'I': "EmptyQueryResponse",
+	'K': "BackendKeyData",
+	'n': "NoData",
+	'N': "NoticeResponse",
+	'R': "Authentication",
+	's': "PortalSuspended",
+	'S': "ParameterStatus",
+	't': "ParameterDescription",
+	'T': "RowDescription",
+	'W': "CopyBothResponse",
+	'Z': "ReadyForQuery",
+}
@@ -0,0 +1,61 @@
+package pool_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+
+	"github.com/go-pg/pg/v10/internal/pool"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Conn.SetTrace", func() {
+	ctx := context.Background()
+
+	It("traces message types and lengths without contents", func() {
+		client, server := net.Pipe()
+		defer server.Close()
+		go func() {
+			_, _ = ioutil.ReadAll(server)
+		}()
+
+		var buf bytes.Buffer
+		cn := pool.NewConn(client)
+		defer cn.Close()
+		cn.SetTrace(&buf)
+
+		err := cn.WithWriter(ctx, 0, func(wb *pool.WriteBuffer) error {
+			wb.StartMessage(0)
+			wb.WriteInt32(196608)
+			wb.WriteString("user")
+			wb.WriteString("secret_user")
+			wb.WriteByte(0)
+			wb.FinishMessage()
+
+			wb.StartMessage('p')
+			wb.WriteString("secret_password")
+			wb.FinishMessage()
+
+			wb.StartMessage('Q')
+			wb.WriteString("SELECT 1")
+			wb.FinishMessage()
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = cn.WithReader(ctx, 0, func(rd *pool.ReaderContext) error {
+			rd.TraceMessage('Z', 1)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(buf.String()).To(Equal("" +
+			"pg: pipe -> StartupMessage len=26\n" +
+			"pg: pipe -> PasswordMessage len=20\n" +
+			"pg: pipe -> Query len=13\n" +
+			"pg: pipe <- ReadyForQuery len=5\n"))
+	})
+})
@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-pg/pg/v10/internal"
 	"github.com/go-pg/pg/v10/internal/pool"
+	"github.com/go-pg/pg/v10/pgjson"
 	"github.com/go-pg/pg/v10/types"
 )
 
@@ -26,11 +29,75 @@ type Notification struct {
 	Payload string
 }
 
+// TypedNotification is a notification with the JSON payload decoded
+// by Listener.ChannelTyped.
+type TypedNotification struct {
+	Channel string
+	Payload string
+	// Value is a pointer to a new value of the type passed to ChannelTyped
+	// with the decoded payload.
+	Value interface{}
+}
+
+// PayloadError is sent by Listener.ChannelTyped for notifications with
+// a payload that can't be decoded.
+type PayloadError struct {
+	Notification Notification
+	Err          error
+}
+
+func (e *PayloadError) Error() string {
+	return fmt.Sprintf("pg: can't decode payload of %s notification: %s",
+		e.Notification.Channel, e.Err)
+}
+
+func (e *PayloadError) Unwrap() error {
+	return e.Err
+}
+
+// OverflowPolicy tells the Listener what to do when the Channel buffer
+// is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest waits up to a minute for the consumer and then
+	// drops the received notification. It is the default policy.
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest buffered notification to make room
+	// for the received one, so the consumer always sees the latest ones.
+	OverflowDropOldest
+	// OverflowBlock stops reading notifications until the consumer catches
+	// up. Meanwhile notifications are queued by the server and the connection
+	// health is not checked, because pings can't be read either.
+	OverflowBlock
+)
+
+// ListenOptions configures the Listener created with DB.ListenWithOptions.
+type ListenOptions struct {
+	// ChannelSize is the buffer size of the Channel. Defaults to 100.
+	ChannelSize int
+	// Overflow is the policy applied when the Channel buffer is full.
+	Overflow OverflowPolicy
+	// MaxPayloadSize drops notifications with larger payloads before they
+	// are sent to the Channel. Zero means no limit.
+	MaxPayloadSize int
+}
+
+func (opt *ListenOptions) init() {
+	if opt.ChannelSize == 0 {
+		opt.ChannelSize = 100
+	}
+}
+
 // Listener listens for notifications sent with NOTIFY command.
 // It's NOT safe for concurrent use by multiple goroutines
 // except the Channel API.
 type Listener struct {
-	db *DB
+	dropped uint64 // atomic
+	blocked uint32 // atomic
+
+	db  *DB
+	opt ListenOptions
 
 	channels []string
 
@@ -51,7 +118,11 @@ func (ln *Listener) String() string {
 	return fmt.Sprintf("Listener(%s)", strings.Join(ln.channels, ", "))
 }
 
-func (ln *Listener) init() {
+func (ln *Listener) init(opt *ListenOptions) {
+	if opt != nil {
+		ln.opt = *opt
+	}
+	ln.opt.init()
 	ln.exit = make(chan struct{})
 }
 
@@ -240,13 +311,32 @@ func (ln *Listener) ReceiveTimeout(
 	return channel, payload, nil
 }
 
+// ReceiveNotification is like Receive, but returns the Notification.
+// It is meant for callers that run their own receive loop instead of
+// using Channel.
+func (ln *Listener) ReceiveNotification(ctx context.Context) (Notification, error) {
+	channel, payload, err := ln.Receive(ctx)
+	if err != nil {
+		return Notification{}, err
+	}
+	return Notification{Channel: channel, Payload: payload}, nil
+}
+
+// Dropped returns the number of notifications dropped by the Channel because
+// the buffer was full or the payload exceeded ListenOptions.MaxPayloadSize,
+// and of payload errors dropped by ChannelTyped.
+func (ln *Listener) Dropped() uint64 {
+	return atomic.LoadUint64(&ln.dropped)
+}
+
 // Channel returns a channel for concurrently receiving notifications.
 // It periodically sends Ping notification to test connection health.
+// The buffer size and the overflow policy are set with ListenOptions.
 //
 // The channel is closed with Listener. Receive* APIs can not be used
 // after channel is created.
 func (ln *Listener) Channel() <-chan Notification {
-	return ln.channel(100)
+	return ln.channel(ln.opt.ChannelSize)
 }
 
 // ChannelSize is like Channel, but creates a Go channel
@@ -255,6 +345,58 @@ func (ln *Listener) ChannelSize(size int) <-chan Notification {
 	return ln.channel(size)
 }
 
+// ChannelTyped is like Channel, but decodes JSON payloads into new values
+// of the type of proto, e.g. Event{} or (*Event)(nil), before delivery:
+//
+//    ch, errCh := ln.ChannelTyped(Event{})
+//    for n := range ch {
+//    	event := n.Value.(*Event)
+//    }
+//
+// Notifications with malformed payloads are not delivered; instead
+// a *PayloadError is sent to the error channel. The error is dropped when
+// the error channel buffer is full. Both channels are closed with Listener.
+//
+// ChannelTyped consumes Channel, so only one of them can be used.
+func (ln *Listener) ChannelTyped(proto interface{}) (<-chan TypedNotification, <-chan error) {
+	typ := reflect.TypeOf(proto)
+	if typ == nil {
+		panic(errors.New("pg: Listener.ChannelTyped(nil)"))
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	in := ln.Channel()
+	ch := make(chan TypedNotification, cap(in))
+	errCh := make(chan error, cap(in))
+
+	go func() {
+		defer close(ch)
+		defer close(errCh)
+
+		for n := range in {
+			v := reflect.New(typ).Interface()
+			if err := pgjson.Unmarshal([]byte(n.Payload), v); err != nil {
+				select {
+				case errCh <- &PayloadError{Notification: n, Err: err}:
+				default:
+					atomic.AddUint64(&ln.dropped, 1)
+				}
+				continue
+			}
+
+			select {
+			case ch <- TypedNotification{Channel: n.Channel, Payload: n.Payload, Value: v}:
+			case <-ln.exit:
+				return
+			}
+		}
+	}()
+
+	return ch, errCh
+}
+
 func (ln *Listener) channel(size int) <-chan Notification {
 	ln.chOnce.Do(func() {
 		ln.initChannel(size)
@@ -268,7 +410,6 @@ func (ln *Listener) channel(size int) <-chan Notification {
 
 func (ln *Listener) initChannel(size int) {
 	const pingTimeout = time.Second
-	const chanSendTimeout = time.Minute
 
 	ctx := ln.db.ctx
 	_ = ln.Listen(ctx, gopgChannel)
@@ -309,20 +450,17 @@ func (ln *Listener) initChannel(size int) {
 			case gopgChannel:
 				// ignore
 			default:
-				timer.Reset(chanSendTimeout)
-				select {
-				case ln.ch <- Notification{channel, payload}:
-					if !timer.Stop() {
-						<-timer.C
-					}
-				case <-timer.C:
+				if ln.opt.MaxPayloadSize > 0 && len(payload) > ln.opt.MaxPayloadSize {
+					atomic.AddUint64(&ln.dropped, 1)
 					internal.Logger.Printf(
 						ctx,
-						"pg: %s channel is full for %s (notification is dropped)",
+						"pg: %s notification payload exceeds %d bytes (notification is dropped)",
 						ln,
-						chanSendTimeout,
+						ln.opt.MaxPayloadSize,
 					)
+					continue
 				}
+				ln.send(ctx, timer, Notification{channel, payload})
 			}
 		}
 	}()
@@ -341,6 +479,13 @@ func (ln *Listener) initChannel(size int) {
 					<-timer.C
 				}
 			case <-timer.C:
+				if atomic.LoadUint32(&ln.blocked) == 1 {
+					// Receive goroutine waits for the consumer and does not
+					// read pings, so the connection can't be checked.
+					healthy = true
+					break
+				}
+
 				pingErr := ln.ping()
 				if healthy {
 					healthy = false
@@ -359,6 +504,61 @@ func (ln *Listener) initChannel(size int) {
 	}()
 }
 
+func (ln *Listener) send(ctx context.Context, timer *time.Timer, n Notification) {
+	const chanSendTimeout = time.Minute
+
+	switch ln.opt.Overflow {
+	case OverflowBlock:
+		select {
+		case ln.ch <- n:
+			return
+		default:
+		}
+
+		atomic.StoreUint32(&ln.blocked, 1)
+		select {
+		case ln.ch <- n:
+		case <-ln.exit:
+		}
+		atomic.StoreUint32(&ln.blocked, 0)
+	case OverflowDropOldest:
+		for {
+			select {
+			case ln.ch <- n:
+				return
+			default:
+			}
+
+			if cap(ln.ch) == 0 {
+				atomic.AddUint64(&ln.dropped, 1)
+				return
+			}
+
+			select {
+			case <-ln.ch:
+				atomic.AddUint64(&ln.dropped, 1)
+			default:
+			}
+		}
+	default:
+		timer.Reset(chanSendTimeout)
+		select {
+		case ln.ch <- n:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		case <-timer.C:
+			atomic.AddUint64(&ln.dropped, 1)
+			internal.Logger.Printf(
+				ctx,
+				"pg: %s channel is full for %s (notification is dropped)",
+				ln,
+				chanSendTimeout,
+			)
+		}
+	}
+}
+
 func (ln *Listener) ping() error {
 	_, err := ln.db.Exec("NOTIFY ?", pgChan(gopgChannel))
 	return err
@@ -2,6 +2,7 @@ package pg_test
 
 import (
 	"net"
+	"strings"
 	"time"
 
 	"github.com/go-pg/pg/v10"
@@ -224,4 +225,143 @@ var _ = Context("Listener", func() {
 			Fail("timeout")
 		}
 	})
+
+	It("returns notification with ReceiveNotification", func() {
+		_, err := db.Exec("NOTIFY test_channel, 'hello'")
+		Expect(err).NotTo(HaveOccurred())
+
+		n, err := ln.ReceiveNotification(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(pg.Notification{Channel: "test_channel", Payload: "hello"}))
+	})
+
+	It("receives notifications sent with Notify", func() {
+		err := db.Notify(ctx, "test_channel", "1", "2", "3")
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, payload := range []string{"1", "2", "3"} {
+			n, err := ln.ReceiveNotification(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(pg.Notification{Channel: "test_channel", Payload: payload}))
+		}
+	})
+
+	It("rejects large payloads in Notify", func() {
+		err := db.Notify(ctx, "test_channel", "ok", strings.Repeat("x", 8000))
+		Expect(err).To(MatchError(
+			"pg: notification payload #1 is 8000 bytes (must be shorter than 8000 bytes)"))
+	})
+})
+
+var _ = Context("Listener with options", func() {
+	var db *pg.DB
+
+	BeforeEach(func() {
+		db = pg.Connect(pgOptions())
+	})
+
+	_ = AfterEach(func() {
+		_ = db.Close()
+	})
+
+	It("drops the oldest notifications when the channel is full", func() {
+		ln := db.ListenWithOptions(ctx, &pg.ListenOptions{
+			ChannelSize: 2,
+			Overflow:    pg.OverflowDropOldest,
+		}, "test_channel")
+		defer ln.Close()
+
+		ch := ln.Channel()
+		for _, payload := range []string{"1", "2", "3", "4", "5"} {
+			_, err := db.Exec("NOTIFY test_channel, ?", payload)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Eventually(ln.Dropped, 3*time.Second).Should(Equal(uint64(3)))
+		Expect((<-ch).Payload).To(Equal("4"))
+		Expect((<-ch).Payload).To(Equal("5"))
+	})
+
+	It("does not reconnect while blocked by the consumer", func() {
+		ln := db.ListenWithOptions(ctx, &pg.ListenOptions{
+			ChannelSize: 1,
+			Overflow:    pg.OverflowBlock,
+		}, "test_channel")
+		defer ln.Close()
+
+		ch := ln.Channel()
+		for _, payload := range []string{"1", "2", "3"} {
+			_, err := db.Exec("NOTIFY test_channel, ?", payload)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		// Longer than two ping timeouts.
+		time.Sleep(3 * time.Second)
+
+		for _, payload := range []string{"1", "2", "3"} {
+			select {
+			case n := <-ch:
+				Expect(n.Payload).To(Equal(payload))
+			case <-time.After(3 * time.Second):
+				Fail("timeout")
+			}
+		}
+		Expect(ln.Dropped()).To(BeZero())
+	})
+
+	It("decodes payloads with ChannelTyped", func() {
+		type Event struct {
+			ID   int
+			Name string
+		}
+
+		ln := db.Listen(ctx, "test_channel")
+		defer ln.Close()
+
+		ch, errCh := ln.ChannelTyped(Event{})
+		err := db.Notify(ctx, "test_channel", `{"ID":1,"Name":"created"}`, "not json")
+		Expect(err).NotTo(HaveOccurred())
+
+		select {
+		case n := <-ch:
+			Expect(n.Channel).To(Equal("test_channel"))
+			Expect(n.Value).To(Equal(&Event{ID: 1, Name: "created"}))
+		case <-time.After(3 * time.Second):
+			Fail("timeout")
+		}
+
+		select {
+		case err := <-errCh:
+			payloadErr, ok := err.(*pg.PayloadError)
+			Expect(ok).To(BeTrue())
+			Expect(payloadErr.Notification.Payload).To(Equal("not json"))
+		case <-time.After(3 * time.Second):
+			Fail("timeout")
+		}
+
+		Expect(ln.Close()).NotTo(HaveOccurred())
+		Eventually(ch).Should(BeClosed())
+		Eventually(errCh).Should(BeClosed())
+	})
+
+	It("drops notifications with large payloads", func() {
+		ln := db.ListenWithOptions(ctx, &pg.ListenOptions{
+			MaxPayloadSize: 3,
+		}, "test_channel")
+		defer ln.Close()
+
+		ch := ln.Channel()
+		for _, payload := range []string{"long payload", "ok"} {
+			_, err := db.Exec("NOTIFY test_channel, ?", payload)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		select {
+		case n := <-ch:
+			Expect(n.Payload).To(Equal("ok"))
+		case <-time.After(3 * time.Second):
+			Fail("timeout")
+		}
+		Expect(ln.Dropped()).To(Equal(uint64(1)))
+	})
 })
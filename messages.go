@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"strings"
 
 	"mellium.im/sasl"
@@ -71,6 +72,7 @@ const (
 	copyOutResponseMsg = 'H'
 	copyDataMsg        = 'd'
 	copyDoneMsg        = 'c'
+	copyFailMsg        = 'f'
 )
 
 var errEmptyQuery = internal.Errorf("pg: query is empty")
@@ -162,10 +164,34 @@ func (db *baseDB) enableSSL(c context.Context, cn *pool.Conn, tlsConf *tls.Confi
 		return err
 	}
 
+	if tlsConf.ServerName == "" && !tlsConf.InsecureSkipVerify {
+		tlsConf = tlsConf.Clone()
+		tlsConf.ServerName = tlsServerName(db.opt, cn.NetConn())
+	}
+
 	cn.SetNetConn(tls.Client(cn.NetConn(), tlsConf))
 	return nil
 }
 
+// dialedAddr returns the address the connection was dialed to,
+// which is one of Options.Addrs when they are set.
+func dialedAddr(opt *Options, netConn net.Conn) string {
+	if cn, ok := netConn.(*addrConn); ok {
+		return cn.addr
+	}
+	return opt.Addr
+}
+
+// tlsServerName returns the host the connection was dialed to so the server
+// certificate is verified against it like with sslmode=verify-full.
+func tlsServerName(opt *Options, netConn net.Conn) string {
+	addr := dialedAddr(opt, netConn)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
 func (db *baseDB) auth(
 	c context.Context, cn *pool.Conn, rd *pool.ReaderContext, user, password string,
 ) error {
@@ -1145,7 +1171,8 @@ func readCopyOutResponse(rd *pool.ReaderContext) error {
 }
 
 func readCopyData(rd *pool.ReaderContext, w io.Writer) (*result, error) {
-	var res result
+	res := result{affected: -1}
+	var rows int
 	var firstErr error
 	for {
 		c, msgLen, err := readMessageType(rd)
@@ -1155,6 +1182,8 @@ func readCopyData(rd *pool.ReaderContext, w io.Writer) (*result, error) {
 
 		switch c {
 		case copyDataMsg:
+			// Server sends every row in a separate message.
+			rows++
 			for msgLen > 0 {
 				b, err := rd.ReadN(msgLen)
 				if err != nil && err != bufio.ErrBufferFull {
@@ -1189,13 +1218,16 @@ func readCopyData(rd *pool.ReaderContext, w io.Writer) (*result, error) {
 			if firstErr != nil {
 				return nil, firstErr
 			}
+			res.setCopyRows(rows)
 			return &res, nil
 		case errorResponseMsg:
 			e, err := readError(rd)
 			if err != nil {
 				return nil, err
 			}
-			return nil, e
+			if firstErr == nil {
+				firstErr = e
+			}
 		case noticeResponseMsg:
 			if err := logNotice(rd, msgLen); err != nil {
 				return nil, err
@@ -1222,6 +1254,12 @@ func writeCopyDone(buf *pool.WriteBuffer) {
 	buf.FinishMessage()
 }
 
+func writeCopyFail(buf *pool.WriteBuffer, reason string) {
+	buf.StartMessage(copyFailMsg)
+	buf.WriteString(reason)
+	buf.FinishMessage()
+}
+
 func readReadyForQuery(rd *pool.ReaderContext) (*result, error) {
 	var res result
 	var firstErr error
@@ -1391,5 +1429,6 @@ func readMessageType(rd *pool.ReaderContext) (byte, int, error) {
 	if err != nil {
 		return 0, 0, err
 	}
+	rd.TraceMessage(c, int(l)-4)
 	return c, int(l) - 4, nil
 }
@@ -0,0 +1,32 @@
+package pg
+
+import (
+	"crypto/tls"
+	"net"
+)
+
+// NetConnInfo describes the network connection of a Conn passed to the
+// OnConnect hook, e.g. to log which backend or route a connection uses.
+// It does not give access to the connection itself.
+type NetConnInfo struct {
+	netConn net.Conn
+}
+
+// LocalAddr returns the local network address.
+func (i *NetConnInfo) LocalAddr() net.Addr {
+	return i.netConn.LocalAddr()
+}
+
+// RemoteAddr returns the remote network address.
+func (i *NetConnInfo) RemoteAddr() net.Addr {
+	return i.netConn.RemoteAddr()
+}
+
+// ConnectionState returns the TLS connection state and true
+// when the connection uses TLS.
+func (i *NetConnInfo) ConnectionState() (tls.ConnectionState, bool) {
+	if cn, ok := i.netConn.(*tls.Conn); ok {
+		return cn.ConnectionState(), true
+	}
+	return tls.ConnectionState{}, false
+}
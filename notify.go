@@ -0,0 +1,46 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-pg/pg/v10/internal"
+)
+
+// notifyPayloadLimit is the default PostgreSQL limit for the payload size.
+// The payload must be shorter than the limit.
+const notifyPayloadLimit = 8000
+
+// Notify sends the payloads as notifications to the channel using
+// pg_notify calls batched in a single query, so the notifications are
+// sent in one round trip and delivered in order. Notify without payloads
+// sends a single notification with an empty payload.
+//
+// The notifications are sent in one transaction, so PostgreSQL delivers
+// duplicate payloads only once.
+//
+// Each payload must be shorter than 8000 bytes; otherwise nothing is sent.
+func (db *baseDB) Notify(ctx context.Context, channel string, payloads ...string) error {
+	if len(payloads) == 0 {
+		payloads = []string{""}
+	}
+
+	b := append([]byte(nil), "SELECT "...)
+	params := make([]interface{}, 0, 2*len(payloads))
+	for i, payload := range payloads {
+		if len(payload) >= notifyPayloadLimit {
+			return fmt.Errorf(
+				"pg: notification payload #%d is %d bytes (must be shorter than %d bytes)",
+				i, len(payload), notifyPayloadLimit)
+		}
+
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = append(b, "pg_notify(?, ?)"...)
+		params = append(params, channel, payload)
+	}
+
+	_, err := db.ExecContext(ctx, internal.BytesToString(b), params...)
+	return err
+}
@@ -0,0 +1,91 @@
+package orm
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+// boolType describes how a bool field is stored in a non-boolean column,
+// e.g. in legacy schemas. See the bool_type field tag option.
+type boolType struct {
+	sqlType     string
+	true, false string
+	quote       bool
+}
+
+var boolTypes = map[string]*boolType{
+	// 'Y' / 'N'
+	"char": {sqlType: "char(1)", true: "Y", false: "N", quote: true},
+	// 1 / 0
+	"smallint": {sqlType: pgTypeSmallint, true: "1", false: "0"},
+	"int":      {sqlType: pgTypeInteger, true: "1", false: "0"},
+}
+
+func getBoolType(typ reflect.Type, name string) (*boolType, error) {
+	if indirectType(typ).Kind() != reflect.Bool {
+		return nil, fmt.Errorf("bool_type is not supported for %s", typ)
+	}
+	bt, ok := boolTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown bool_type %q (supported: char, smallint, int)", name)
+	}
+	return bt, nil
+}
+
+func (bt *boolType) appender() types.AppenderFunc {
+	return func(b []byte, v reflect.Value, flags int) []byte {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return types.AppendNull(b, flags)
+			}
+			v = v.Elem()
+		}
+
+		s := bt.false
+		if v.Bool() {
+			s = bt.true
+		}
+		if bt.quote {
+			return types.AppendString(b, s, flags)
+		}
+		return append(b, s...)
+	}
+}
+
+func (bt *boolType) scanner() types.ScannerFunc {
+	return func(v reflect.Value, rd types.Reader, n int) error {
+		if v.Kind() == reflect.Ptr {
+			if n == -1 {
+				if !v.IsNil() {
+					v.Set(reflect.Zero(v.Type()))
+				}
+				return nil
+			}
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+
+		if n == -1 {
+			v.SetBool(false)
+			return nil
+		}
+
+		tmp, err := rd.ReadFullTemp()
+		if err != nil {
+			return err
+		}
+		v.SetBool(bt.parse(tmp))
+		return nil
+	}
+}
+
+func (bt *boolType) parse(b []byte) bool {
+	b = bytes.TrimRight(b, " ")
+	// Postgres booleans are accepted too, e.g. for default_on_null.
+	return bytes.EqualFold(b, []byte(bt.true)) || string(b) == "t"
+}
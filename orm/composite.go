@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/go-pg/pg/v10/internal"
 	"github.com/go-pg/pg/v10/internal/pool"
 	"github.com/go-pg/pg/v10/types"
 )
@@ -87,6 +88,10 @@ func compositeAppender(typ reflect.Type) types.AppenderFunc {
 			v = v.Elem()
 		}
 
+		if quote == 0 {
+			return appendCompositeText(b, table, v)
+		}
+
 		b = append(b, "ROW("...)
 		for i, f := range table.Fields {
 			if i > 0 {
@@ -98,3 +103,89 @@ func compositeAppender(typ reflect.Type) types.AppenderFunc {
 		return b
 	}
 }
+
+// appendCompositeText appends composite value in the text format,
+// for example, (1,"Main St"). The format is used for composite values
+// nested in arrays and other composites where ROW() can't be used.
+func appendCompositeText(b []byte, table *Table, strct reflect.Value) []byte {
+	b = append(b, '(')
+	for i, f := range table.Fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+
+		fv, ok := fieldByIndex(strct, f.Index)
+		if !ok || (f.NullZero() && f.isZero(fv)) || isNilValue(fv) {
+			continue // NULL
+		}
+		b = appendCompositeField(b, f.append(nil, fv, 0))
+	}
+	b = append(b, ')')
+	return b
+}
+
+func appendCompositeField(b, field []byte) []byte {
+	if !compositeFieldNeedsQuote(field) {
+		return append(b, field...)
+	}
+
+	b = append(b, '"')
+	for _, c := range field {
+		switch c {
+		case '"', '\\':
+			b = append(b, c, c)
+		default:
+			b = append(b, c)
+		}
+	}
+	b = append(b, '"')
+	return b
+}
+
+func compositeFieldNeedsQuote(field []byte) bool {
+	if len(field) == 0 {
+		return true
+	}
+	for _, c := range field {
+		switch c {
+		case '"', '\\', '(', ')', ',', ' ', '\t', '\n', '\r':
+			return true
+		}
+	}
+	return false
+}
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func compositeArrayScanner(typ reflect.Type) types.ScannerFunc {
+	return types.CustomArrayScanner(typ, compositeScanner(indirectType(typ).Elem()))
+}
+
+func compositeArrayAppender(typ reflect.Type) types.AppenderFunc {
+	elemType := indirectType(typ).Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	var table *Table
+	return types.CustomArrayAppender(typ, func(b []byte, v reflect.Value, flags int) []byte {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return types.AppendNull(b, flags)
+			}
+			v = v.Elem()
+		}
+
+		if table == nil {
+			table = GetTable(elemType)
+		}
+		text := appendCompositeText(nil, table, v)
+		return types.AppendString(b, internal.BytesToString(text), flags)
+	})
+}
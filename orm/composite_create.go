@@ -78,6 +78,8 @@ func (q *CreateCompositeQuery) AppendQuery(fmter QueryFormatter, b []byte) ([]by
 			b = append(b, "varchar("...)
 			b = strconv.AppendInt(b, int64(q.opt.Varchar), 10)
 			b = append(b, ")"...)
+		} else if field.UserSQLType != "" {
+			b = append(b, field.UserSQLType...)
 		} else {
 			b = append(b, field.SQLType...)
 		}
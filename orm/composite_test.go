@@ -0,0 +1,85 @@
+package orm
+
+import (
+	"reflect"
+
+	"github.com/go-pg/pg/v10/internal/pool"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type CompositeAddress struct {
+	Street string
+	City   string
+	Zip    *int
+}
+
+type CompositeArrayModel struct {
+	ID         int
+	Addresses  []CompositeAddress  `pg:"composite:address"`
+	AddressPtr []*CompositeAddress `pg:"composite:address[]"`
+}
+
+var _ = Describe("composite array", func() {
+	It("uses array type for slices", func() {
+		table := GetTable(reflect.TypeOf(CompositeArrayModel{}))
+		Expect(table.FieldsMap["addresses"].SQLType).To(Equal("address[]"))
+		Expect(table.FieldsMap["address_ptr"].SQLType).To(Equal("address[]"))
+	})
+
+	It("inserts composite arrays", func() {
+		zip := 12345
+		q := NewQuery(nil, &CompositeArrayModel{
+			ID: 1,
+			Addresses: []CompositeAddress{
+				{Street: "1 Main St", City: "O'Fallon", Zip: &zip},
+				{City: `"Quoted"`},
+			},
+			AddressPtr: []*CompositeAddress{nil, {Street: `back\slash`}},
+		})
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "composite_array_models" ("id", "addresses", "address_ptr") VALUES (1, '{"(\"1 Main St\",O''Fallon,12345)","(,\"\"\"Quoted\"\"\",)"}', '{NULL,"(\"back\\\\slash\",,)"}')`))
+	})
+
+	It("inserts empty and nil composite arrays", func() {
+		q := NewQuery(nil, &CompositeArrayModel{
+			ID:        1,
+			Addresses: []CompositeAddress{},
+		})
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "composite_array_models" ("id", "addresses", "address_ptr") VALUES (1, '{}', DEFAULT) RETURNING "address_ptr"`))
+	})
+
+	It("scans composite arrays", func() {
+		table := GetTable(reflect.TypeOf(CompositeArrayModel{}))
+		model := new(CompositeArrayModel)
+		strct := reflect.ValueOf(model).Elem()
+
+		b := []byte(`{"(\"1 Main St\",O'Fallon,12345)","(,\"\"\"Quoted\"\"\",)"}`)
+		err := table.FieldsMap["addresses"].ScanValue(strct, pool.NewBytesReader(b), len(b))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Addresses).To(HaveLen(2))
+		Expect(model.Addresses[0].Street).To(Equal("1 Main St"))
+		Expect(model.Addresses[0].City).To(Equal("O'Fallon"))
+		Expect(*model.Addresses[0].Zip).To(Equal(12345))
+		Expect(model.Addresses[1].Street).To(Equal(""))
+		Expect(model.Addresses[1].City).To(Equal(`"Quoted"`))
+		Expect(model.Addresses[1].Zip).To(BeNil())
+
+		b = []byte(`{NULL,"(x,y,)"}`)
+		err = table.FieldsMap["address_ptr"].ScanValue(strct, pool.NewBytesReader(b), len(b))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.AddressPtr).To(HaveLen(2))
+		Expect(model.AddressPtr[0]).To(BeNil())
+		Expect(model.AddressPtr[1]).To(Equal(&CompositeAddress{Street: "x", City: "y"}))
+
+		b = []byte(`{}`)
+		err = table.FieldsMap["addresses"].ScanValue(strct, pool.NewBytesReader(b), len(b))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Addresses).To(BeEmpty())
+		Expect(model.Addresses).NotTo(BeNil())
+	})
+})
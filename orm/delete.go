@@ -56,6 +56,8 @@ func (q *DeleteQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 		return nil, q.q.stickyErr
 	}
 
+	b = q.q.appendComment(b)
+
 	if len(q.q.with) > 0 {
 		b, err = q.q.appendWith(fmter, b)
 		if err != nil {
@@ -78,11 +80,10 @@ func (q *DeleteQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 	}
 
 	b = append(b, " WHERE "...)
-	value := q.q.tableModel.Value()
 
 	if q.q.isSliceModelWithData() {
 		if len(q.q.where) > 0 {
-			b, err = q.q.appendWhere(fmter, b)
+			b, err = q.withoutPKSliceJoin().appendWhere(fmter, b)
 			if err != nil {
 				return nil, err
 			}
@@ -93,7 +94,7 @@ func (q *DeleteQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 				return nil, err
 			}
 
-			b = appendColumnAndSliceValue(fmter, b, value, table.Alias, table.PKs)
+			b = appendColumnAndSliceValue(fmter, b, q.q.tableModel.Value(), table.Alias, table.PKs)
 		}
 	} else {
 		b, err = q.q.mustAppendWhere(fmter, b)
@@ -112,6 +113,29 @@ func (q *DeleteQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 	return b, q.q.stickyErr
 }
 
+// withoutPKSliceJoin returns the query with WherePK conditions of slice
+// models rewritten to IN, because DELETE ignores the join they rely on.
+func (q *DeleteQuery) withoutPKSliceJoin() *Query {
+	var where []queryWithSepAppender
+	for i, w := range q.q.where {
+		if _, ok := w.(wherePKSliceQuery); !ok {
+			continue
+		}
+		if where == nil {
+			where = make([]queryWithSepAppender, len(q.q.where))
+			copy(where, q.q.where)
+		}
+		where[i] = wherePKSliceInQuery{q: q.q}
+	}
+	if where == nil {
+		return q.q
+	}
+
+	cp := *q.q
+	cp.where = where
+	return &cp
+}
+
 func appendColumnAndSliceValue(
 	fmter QueryFormatter, b []byte, slice reflect.Value, alias types.Safe, fields []*Field,
 ) []byte {
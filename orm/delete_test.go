@@ -7,6 +7,12 @@ import (
 
 type DeleteTest struct{}
 
+type CompositePKTest struct {
+	TenantID int `pg:",pk"`
+	ID       int `pg:",pk"`
+	Name     string
+}
+
 var _ = Describe("Delete", func() {
 	It("supports WITH", func() {
 		q := NewQuery(nil, &DeleteTest{}).
@@ -18,6 +24,61 @@ var _ = Describe("Delete", func() {
 		s := deleteQueryString(q)
 		Expect(s).To(Equal(`WITH "wrapper" AS (SELECT  FROM "delete_tests" AS "delete_test") DELETE FROM "delete_tests" AS "delete_test" USING "wrapper" WHERE (delete_test.id = wrapper.id)`))
 	})
+
+	It("supports DeleteUsing with a table and a model", func() {
+		q := NewQuery(nil, (*DeleteTest)(nil)).
+			DeleteUsing("parents", "p").
+			DeleteUsing((*InsertTest)(nil), "t").
+			Where("delete_test.parent_id = p.id").
+			Where("t.value = ?", "foo")
+
+		s := deleteQueryString(q)
+		Expect(s).To(Equal(`DELETE FROM "delete_tests" AS "delete_test" USING "parents" AS "p", "insert_tests" AS "t" WHERE (delete_test.parent_id = p.id) AND (t.value = 'foo')`))
+	})
+
+	It("supports DeleteUsing with a subquery", func() {
+		src := NewQuery(nil, (*InsertTest)(nil)).Column("id").Where("value = ?", "bar")
+		q := NewQuery(nil, (*DeleteTest)(nil)).
+			DeleteUsing(src, "s").
+			Where("delete_test.id = s.id AND delete_test.id > ?", 10)
+
+		s := deleteQueryString(q)
+		Expect(s).To(Equal(`DELETE FROM "delete_tests" AS "delete_test" USING (SELECT "id" FROM "insert_tests" AS "insert_test" WHERE (value = 'bar')) AS "s" WHERE (delete_test.id = s.id AND delete_test.id > 10)`))
+	})
+
+	It("deletes a struct with composite primary key using WherePK", func() {
+		q := NewQuery(nil, &CompositePKTest{TenantID: 1, ID: 2}).WherePK()
+
+		s := deleteQueryString(q)
+		Expect(s).To(Equal(`DELETE FROM "composite_pk_tests" AS "composite_pk_test" WHERE "composite_pk_test"."tenant_id" = 1 AND "composite_pk_test"."id" = 2`))
+	})
+
+	It("deletes a slice with composite primary key", func() {
+		models := []CompositePKTest{{TenantID: 1, ID: 2}, {TenantID: 3, ID: 4}}
+
+		s := deleteQueryString(NewQuery(nil, &models))
+		Expect(s).To(Equal(`DELETE FROM "composite_pk_tests" AS "composite_pk_test" WHERE ("composite_pk_test"."tenant_id", "composite_pk_test"."id") IN ((1, 2), (3, 4))`))
+
+		s = deleteQueryString(NewQuery(nil, &models).WherePK())
+		Expect(s).To(Equal(`DELETE FROM "composite_pk_tests" AS "composite_pk_test" WHERE ("composite_pk_test"."tenant_id", "composite_pk_test"."id") IN ((1, 2), (3, 4))`))
+
+		s = deleteQueryString(NewQuery(nil, &models).Where("name = ?", "x").WherePK())
+		Expect(s).To(Equal(`DELETE FROM "composite_pk_tests" AS "composite_pk_test" WHERE (name = 'x') AND ("composite_pk_test"."tenant_id", "composite_pk_test"."id") IN ((1, 2), (3, 4))`))
+	})
+
+	It("deletes a slice with single primary key using WherePK", func() {
+		models := []InsertTest{{Id: 1}, {Id: 2}}
+
+		s := deleteQueryString(NewQuery(nil, &models).WherePK())
+		Expect(s).To(Equal(`DELETE FROM "insert_tests" AS "insert_test" WHERE "insert_test"."id" IN (1, 2)`))
+	})
+
+	It("returns an error for unsupported DeleteUsing source", func() {
+		q := NewQuery(nil, (*DeleteTest)(nil)).DeleteUsing(123, "s")
+
+		_, err := NewDeleteQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: DeleteUsing does not support int"))
+	})
 })
 
 func deleteQueryString(q *Query) string {
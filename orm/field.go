@@ -31,11 +31,16 @@ type Field struct {
 	Default     types.Safe
 	OnDelete    string
 	OnUpdate    string
+	Deferrable  string // e.g. DEFERRABLE INITIALLY DEFERRED
+	Identity    string // e.g. GENERATED ALWAYS AS IDENTITY
 
-	flags uint8
+	flags   uint8
+	options map[string]string // pg tag options
 
-	append types.AppenderFunc
-	scan   types.ScannerFunc
+	append  types.AppenderFunc
+	scan    types.ScannerFunc
+	setNow  func(fv reflect.Value) error // auto_now and auto_now_add fields
+	setUUID func(fv reflect.Value) error // uuid fields with generate
 
 	isZero zerochecker.Func
 }
@@ -121,6 +121,75 @@ func (a fieldAppender) AppendQuery(fmter QueryFormatter, b []byte) ([]byte, erro
 
 //------------------------------------------------------------------------------
 
+type groupingAppender struct {
+	name string
+	sets [][]string
+}
+
+var _ QueryAppender = (*groupingAppender)(nil)
+
+func singleColumnSets(columns []string) [][]string {
+	sets := make([][]string, len(columns))
+	for i, column := range columns {
+		sets[i] = []string{column}
+	}
+	return sets
+}
+
+func (a *groupingAppender) AppendQuery(fmter QueryFormatter, b []byte) ([]byte, error) {
+	b = append(b, a.name...)
+	b = append(b, " ("...)
+	for i, set := range a.sets {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		if len(set) == 1 {
+			b = types.AppendIdent(b, set[0], 1)
+			continue
+		}
+		b = append(b, '(')
+		b = appendIdents(b, set)
+		b = append(b, ')')
+	}
+	b = append(b, ')')
+	return b, nil
+}
+
+// Grouping returns `GROUPING(column1, column2, ...)` expression that can be
+// used with GroupByRollup, GroupByCube, and GroupingSets to distinguish
+// subtotal rows from NULL values:
+//
+//    q.ColumnExpr("? AS subtotal", orm.Grouping("brand"))
+func Grouping(columns ...string) types.Safe {
+	b := append([]byte(nil), "GROUPING("...)
+	b = appendIdents(b, columns)
+	b = append(b, ')')
+	return types.Safe(internal.BytesToString(b))
+}
+
+// HstoreGet returns `column -> key` expression that selects the value
+// of the hstore key or NULL when the key is missing:
+//
+//    q.ColumnExpr("? AS color", orm.HstoreGet("attrs", "color"))
+func HstoreGet(column, key string) types.Safe {
+	b := types.AppendIdent(nil, column, 1)
+	b = append(b, " -> "...)
+	b = types.AppendString(b, key, 1)
+	return types.Safe(internal.BytesToString(b))
+}
+
+func appendIdents(b []byte, idents []string) []byte {
+	for i, ident := range idents {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = types.AppendIdent(b, ident, 1)
+	}
+	return b
+}
+
+//------------------------------------------------------------------------------
+
 type dummyFormatter struct{}
 
 func (f dummyFormatter) FormatQuery(b []byte, query string, params ...interface{}) []byte {
@@ -3,6 +3,8 @@ package orm
 import (
 	"context"
 	"reflect"
+	"strconv"
+	"sync"
 )
 
 type hookStubs struct{}
@@ -246,3 +248,98 @@ func callAfterDeleteHookSlice(
 ) error {
 	return callHookSlice2(ctx, slice, ptr, callAfterDeleteHook)
 }
+
+//------------------------------------------------------------------------------
+
+// HookOp is the model operation a global hook is called for.
+type HookOp int
+
+const (
+	BeforeInsertOp HookOp = iota
+	AfterInsertOp
+	BeforeUpdateOp
+	AfterUpdateOp
+	BeforeDeleteOp
+	AfterDeleteOp
+)
+
+func (op HookOp) String() string {
+	switch op {
+	case BeforeInsertOp:
+		return "BeforeInsert"
+	case AfterInsertOp:
+		return "AfterInsert"
+	case BeforeUpdateOp:
+		return "BeforeUpdate"
+	case AfterUpdateOp:
+		return "AfterUpdate"
+	case BeforeDeleteOp:
+		return "BeforeDelete"
+	case AfterDeleteOp:
+		return "AfterDelete"
+	default:
+		return "HookOp(" + strconv.Itoa(int(op)) + ")"
+	}
+}
+
+// GlobalHookEvent describes the model operation passed to global hooks.
+type GlobalHookEvent struct {
+	Op HookOp
+	// Model is the struct or the slice of structs being inserted,
+	// updated or deleted. Model.IsNil reports true for queries like
+	// db.Model((*Book)(nil)).Where("id = 1").Delete().
+	Model TableModel
+	Query *Query
+}
+
+// GlobalHook is called for every insert, update and delete of any model.
+// The context returned by before hooks is passed to the query and to
+// the following hooks.
+type GlobalHook func(context.Context, *GlobalHookEvent) (context.Context, error)
+
+var globalHooks struct {
+	mu    sync.RWMutex
+	hooks []GlobalHook
+}
+
+// AddGlobalHook registers a hook that is called for every insert, update and
+// delete of any model, e.g. to write an audit log or set updated_at.
+//
+// Hooks are called in the order they were added. Before hooks are called
+// before the model hooks, e.g. BeforeInsertHook, and after hooks are called
+// after the model hooks. An error returned by a before hook cancels the query.
+// Soft deletes are reported as updates. Queries without a model, e.g. ones
+// that use only TableExpr, do not call global hooks.
+func AddGlobalHook(hook GlobalHook) {
+	globalHooks.mu.Lock()
+	globalHooks.hooks = append(globalHooks.hooks, hook)
+	globalHooks.mu.Unlock()
+}
+
+func callGlobalHooks(ctx context.Context, q *Query, op HookOp) (context.Context, error) {
+	if q.tableModel == nil {
+		return ctx, nil
+	}
+
+	globalHooks.mu.RLock()
+	hooks := globalHooks.hooks
+	globalHooks.mu.RUnlock()
+
+	if len(hooks) == 0 {
+		return ctx, nil
+	}
+
+	evt := &GlobalHookEvent{
+		Op:    op,
+		Model: q.tableModel,
+		Query: q,
+	}
+	for _, hook := range hooks {
+		var err error
+		ctx, err = hook(ctx, evt)
+		if err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
@@ -1,10 +1,13 @@
 package orm
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 
+	"github.com/go-pg/pg/v10/internal"
 	"github.com/go-pg/pg/v10/types"
 )
 
@@ -59,6 +62,28 @@ func (q *InsertQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 	if q.q.stickyErr != nil {
 		return nil, q.q.stickyErr
 	}
+	if q.q.onConflictDoUpdate() && q.q.onConflictConstraint == "" &&
+		len(q.q.onConflictColumns) == 0 &&
+		strings.HasPrefix(internal.UpperString(strings.TrimSpace(q.q.onConflict.query)), "DO UPDATE") {
+		return nil, errors.New(
+			"pg: ON CONFLICT DO UPDATE requires a conflict target, e.g. OnConflict(\"(id) DO UPDATE\")")
+	}
+	if q.q.onConflictWhere != nil && len(q.q.onConflictColumns) == 0 {
+		return nil, errors.New("pg: OnConflictWhere requires OnConflictColumns")
+	}
+	if len(q.q.onConflictColumns) > 0 && q.q.onConflictConstraint != "" {
+		return nil, errors.New(
+			"pg: OnConflictColumns and OnConflictOnConstraint can't be used together")
+	}
+	if (q.q.onConflictConstraint != "" || len(q.q.onConflictColumns) > 0) &&
+		q.q.onConflict != nil &&
+		!strings.HasPrefix(internal.UpperString(strings.TrimSpace(q.q.onConflict.query)), "DO ") {
+		return nil, errors.New(
+			"pg: OnConflict must only specify the action, e.g. OnConflict(\"DO UPDATE\"), " +
+				"when the target is set with OnConflictOnConstraint or OnConflictColumns")
+	}
+
+	b = q.q.appendComment(b)
 
 	if len(q.q.with) > 0 {
 		b, err = q.q.appendWith(fmter, b)
@@ -68,7 +93,7 @@ func (q *InsertQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 	}
 
 	b = append(b, "INSERT INTO "...)
-	if q.q.onConflict != nil {
+	if q.q.hasOnConflict() {
 		b, err = q.q.appendFirstTableWithAlias(fmter, b)
 	} else {
 		b, err = q.q.appendFirstTable(fmter, b)
@@ -82,11 +107,26 @@ func (q *InsertQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 		return nil, err
 	}
 
-	if q.q.onConflict != nil {
+	if q.q.hasOnConflict() {
 		b = append(b, " ON CONFLICT "...)
-		b, err = q.q.onConflict.AppendQuery(fmter, b)
-		if err != nil {
-			return nil, err
+		if q.q.onConflictConstraint != "" {
+			b = append(b, "ON CONSTRAINT "...)
+			b = types.AppendIdent(b, q.q.onConflictConstraint, 1)
+			b = append(b, ' ')
+		}
+		if len(q.q.onConflictColumns) > 0 {
+			b, err = q.appendConflictTarget(fmter, b)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if q.q.onConflict != nil {
+			b, err = q.q.onConflict.AppendQuery(fmter, b)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			b = append(b, "DO NOTHING"...)
 		}
 
 		if q.q.onConflictDoUpdate() {
@@ -118,18 +158,53 @@ func (q *InsertQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 		}
 	}
 
+	hasReturning := false
 	if len(q.q.returning) > 0 {
 		b, err = q.q.appendReturning(fmter, b)
 		if err != nil {
 			return nil, err
 		}
+		hasReturning = q.q.hasReturning()
 	} else if len(q.returningFields) > 0 {
 		b = appendReturningFields(b, q.returningFields)
+		hasReturning = true
+	}
+
+	if q.q.upsertActions != nil {
+		if hasReturning {
+			b = append(b, ", "...)
+		} else {
+			b = append(b, " RETURNING "...)
+		}
+		b = append(b, "(xmax = 0) AS "...)
+		b = types.AppendIdent(b, upsertActionColumn, 1)
 	}
 
 	return b, q.q.stickyErr
 }
 
+func (q *InsertQuery) appendConflictTarget(fmter QueryFormatter, b []byte) (_ []byte, err error) {
+	b = append(b, '(')
+	for i, column := range q.q.onConflictColumns {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = types.AppendIdent(b, column, 1)
+	}
+	b = append(b, ") "...)
+
+	if q.q.onConflictWhere != nil {
+		b = append(b, "WHERE ("...)
+		b, err = q.q.onConflictWhere.AppendQuery(fmter, b)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, ") "...)
+	}
+
+	return b, nil
+}
+
 func (q *InsertQuery) appendColumnsValues(fmter QueryFormatter, b []byte) (_ []byte, err error) {
 	if q.q.hasMultiTables() {
 		if q.q.columns != nil {
@@ -141,6 +216,7 @@ func (q *InsertQuery) appendColumnsValues(fmter QueryFormatter, b []byte) (_ []b
 			b = append(b, ")"...)
 		}
 
+		b = q.appendOverriding(b)
 		b = append(b, " SELECT * FROM "...)
 		b, err = q.q.appendOtherTables(fmter, b)
 		if err != nil {
@@ -158,9 +234,12 @@ func (q *InsertQuery) appendColumnsValues(fmter QueryFormatter, b []byte) (_ []b
 		return nil, errModelNil
 	}
 
-	fields, err := q.q.getFields()
-	if err != nil {
-		return nil, err
+	fields := q.q.insertFields
+	if len(fields) == 0 {
+		fields, err = q.q.getFields()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if len(fields) == 0 {
@@ -170,7 +249,9 @@ func (q *InsertQuery) appendColumnsValues(fmter QueryFormatter, b []byte) (_ []b
 
 	b = append(b, " ("...)
 	b = q.appendColumns(b, fields)
-	b = append(b, ") VALUES ("...)
+	b = append(b, ")"...)
+	b = q.appendOverriding(b)
+	b = append(b, " VALUES ("...)
 	if m, ok := q.q.tableModel.(*sliceTableModel); ok {
 		if m.sliceLen == 0 {
 			err = fmt.Errorf("pg: can't bulk-insert empty slice %s", value.Type())
@@ -191,6 +272,13 @@ func (q *InsertQuery) appendColumnsValues(fmter QueryFormatter, b []byte) (_ []b
 	return b, nil
 }
 
+func (q *InsertQuery) appendOverriding(b []byte) []byte {
+	if q.q.hasFlag(overridingSystemValueFlag) {
+		b = append(b, " OVERRIDING SYSTEM VALUE"...)
+	}
+	return b
+}
+
 func (q *InsertQuery) appendMapColumnsValues(b []byte, m map[string]interface{}) []byte {
 	keys := make([]string, 0, len(m))
 
@@ -208,7 +296,9 @@ func (q *InsertQuery) appendMapColumnsValues(b []byte, m map[string]interface{})
 		b = types.AppendIdent(b, k, 1)
 	}
 
-	b = append(b, ") VALUES ("...)
+	b = append(b, ")"...)
+	b = q.appendOverriding(b)
+	b = append(b, " VALUES ("...)
 
 	for i, k := range keys {
 		if i > 0 {
@@ -3,6 +3,7 @@ package orm
 import (
 	"time"
 
+	"github.com/go-pg/pg/v10/internal"
 	"github.com/go-pg/pg/v10/types"
 
 	. "github.com/onsi/ginkgo"
@@ -43,6 +44,29 @@ type InsertQTest struct {
 }
 
 var _ = Describe("Insert", func() {
+	It("returns LastInsertId for struct models", func() {
+		q := NewQuery(nil, &InsertTest{Id: 123})
+		id, ok := q.lastInsertID(&batchResult{affected: 1})
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal(int64(123)))
+
+		_, ok = q.lastInsertID(&batchResult{affected: 0})
+		Expect(ok).To(BeFalse())
+
+		_, ok = NewQuery(nil, &InsertTest{}).lastInsertID(&batchResult{affected: 1})
+		Expect(ok).To(BeFalse())
+
+		_, ok = NewQuery(nil, &[]InsertTest{{Id: 1}}).lastInsertID(&batchResult{affected: 1})
+		Expect(ok).To(BeFalse())
+
+		id, err := q.LastInsertId(&batchResult{affected: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(int64(123)))
+
+		_, err = q.LastInsertId(&batchResult{affected: 0})
+		Expect(err).To(Equal(internal.ErrNoLastInsertId))
+	})
+
 	It("supports Column", func() {
 		model := &InsertTest{
 			Id:    1,
@@ -87,6 +111,29 @@ var _ = Describe("Insert", func() {
 		Expect(s).To(Equal(`INSERT INTO "insert_tests" ("id", "value", "unknown") VALUES (1, 'hello', upper('hello'))`))
 	})
 
+	It("supports InsertColumns", func() {
+		model := &InsertDefaultTest{
+			Id:    1,
+			Value: "hello",
+		}
+		q := NewQuery(nil, model).InsertColumns("value", "id")
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "insert_default_tests" ("value", "id") VALUES ('hello', 1)`))
+
+		q = NewQuery(nil, &InsertDefaultTest{Id: 1}, &InsertDefaultTest{Id: 2}).InsertColumns("id")
+
+		s = insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "insert_default_tests" ("id") VALUES (1), (2)`))
+	})
+
+	It("returns an error for unknown InsertColumns", func() {
+		q := NewQuery(nil, &InsertTest{}).InsertColumns("id", "unknown")
+
+		_, err := NewInsertQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: model=InsertTest does not have column=unknown"))
+	})
+
 	It("supports ExcludeColumn", func() {
 		model := &InsertTest{}
 		q := NewQuery(nil, model).ExcludeColumn("value")
@@ -136,6 +183,125 @@ var _ = Describe("Insert", func() {
 		Expect(s).To(Equal(`INSERT INTO "insert_tests" AS "insert_test" ("id", "value") VALUES (DEFAULT, DEFAULT) ON CONFLICT (unq1) DO NOTHING RETURNING "id", "value"`))
 	})
 
+	It("supports ON CONFLICT ON CONSTRAINT", func() {
+		q := NewQuery(nil, &InsertTest{}).
+			OnConflictOnConstraint("insert_tests_pkey")
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "insert_tests" AS "insert_test" ("id", "value") VALUES (DEFAULT, DEFAULT) ON CONFLICT ON CONSTRAINT "insert_tests_pkey" DO NOTHING RETURNING "id", "value"`))
+	})
+
+	It("supports ON CONFLICT ON CONSTRAINT DO UPDATE", func() {
+		q := NewQuery(nil, &InsertTest{}).
+			OnConflictOnConstraint("insert_tests_pkey").
+			OnConflict("DO UPDATE").
+			Set("count1 = count1 + 1").
+			Where("2 = 2")
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "insert_tests" AS "insert_test" ("id", "value") VALUES (DEFAULT, DEFAULT) ON CONFLICT ON CONSTRAINT "insert_tests_pkey" DO UPDATE SET count1 = count1 + 1 WHERE (2 = 2) RETURNING "id", "value"`))
+	})
+
+	It("returns an error for two conflict targets", func() {
+		q := NewQuery(nil, &InsertTest{}).
+			OnConflictOnConstraint("insert_tests_pkey").
+			OnConflict("(id) DO NOTHING")
+
+		_, err := NewInsertQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError(`pg: OnConflict must only specify the action, e.g. OnConflict("DO UPDATE"), ` +
+			`when the target is set with OnConflictOnConstraint or OnConflictColumns`))
+	})
+
+	It("supports ON CONFLICT with columns and index predicate", func() {
+		q := NewQuery(nil, &InsertTest{}).
+			OnConflictColumns("value").
+			OnConflictWhere("deleted_at IS NULL AND tenant_id = ?", 5).
+			OnConflict("DO UPDATE").
+			Set("value = EXCLUDED.value")
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "insert_tests" AS "insert_test" ("id", "value") VALUES (DEFAULT, DEFAULT) ON CONFLICT ("value") WHERE (deleted_at IS NULL AND tenant_id = 5) DO UPDATE SET value = EXCLUDED.value RETURNING "id", "value"`))
+	})
+
+	It("supports ON CONFLICT with columns DO NOTHING", func() {
+		q := NewQuery(nil, &InsertTest{}).
+			OnConflictColumns("id", "value")
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "insert_tests" AS "insert_test" ("id", "value") VALUES (DEFAULT, DEFAULT) ON CONFLICT ("id", "value") DO NOTHING RETURNING "id", "value"`))
+	})
+
+	It("supports OverridingSystemValue", func() {
+		q := NewQuery(nil, &InsertTest{Id: 1, Value: "hello"}).OverridingSystemValue()
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "insert_tests" ("id", "value") OVERRIDING SYSTEM VALUE VALUES (1, 'hello')`))
+
+		models := []InsertTest{{Id: 1}, {Id: 2}}
+		q = NewQuery(nil, &models).Column("id").OverridingSystemValue()
+
+		s = insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "insert_tests" ("id") OVERRIDING SYSTEM VALUE VALUES (1), (2)`))
+
+		values := map[string]interface{}{"id": 1}
+		q = NewQuery(nil, &values).TableExpr("insert_tests").OverridingSystemValue()
+
+		s = insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO insert_tests ("id") OVERRIDING SYSTEM VALUE VALUES (1)`))
+
+		q = NewQuery(nil).Table("dst").TableExpr("src").OverridingSystemValue()
+
+		s = insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "dst" OVERRIDING SYSTEM VALUE SELECT * FROM src`))
+	})
+
+	It("supports ReturningAction", func() {
+		var actions []UpsertAction
+		q := NewQuery(nil, &InsertTest{}).
+			OnConflict("(unq1) DO UPDATE").
+			ReturningAction(&actions)
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "insert_tests" AS "insert_test" ("id", "value") VALUES (DEFAULT, DEFAULT) ON CONFLICT (unq1) DO UPDATE SET "value" = EXCLUDED."value" RETURNING "id", "value", (xmax = 0) AS "_upsert_inserted"`))
+	})
+
+	It("supports ReturningAction with Returning(NULL)", func() {
+		var inserted []bool
+		q := NewQuery(nil, &InsertTest{Id: 1, Value: "hello"}).
+			OnConflict("(id) DO NOTHING").
+			Returning("NULL").
+			ReturningAction(&inserted)
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "insert_tests" AS "insert_test" ("id", "value") VALUES (1, 'hello') ON CONFLICT (id) DO NOTHING RETURNING (xmax = 0) AS "_upsert_inserted"`))
+	})
+
+	It("returns an error for unsupported ReturningAction", func() {
+		var actions []string
+		q := NewQuery(nil, &InsertTest{}).ReturningAction(&actions)
+
+		_, err := q.Insert()
+		Expect(err).To(MatchError(`pg: ReturningAction(unsupported *[]string)`))
+	})
+
+	It("returns an error for OnConflictWhere without columns", func() {
+		q := NewQuery(nil, &InsertTest{}).
+			OnConflictWhere("deleted_at IS NULL").
+			OnConflict("DO NOTHING")
+
+		_, err := NewInsertQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError(`pg: OnConflictWhere requires OnConflictColumns`))
+	})
+
+	It("returns an error for ON CONFLICT DO UPDATE without conflict target", func() {
+		q := NewQuery(nil, &InsertTest{}).
+			OnConflict("DO UPDATE").
+			Set("value = EXCLUDED.value")
+
+		_, err := NewInsertQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError(`pg: ON CONFLICT DO UPDATE requires a conflict target, e.g. OnConflict("(id) DO UPDATE")`))
+	})
+
 	It("supports custom table name on embedded struct", func() {
 		q := NewQuery(nil, &EmbeddedInsertTest{})
 
@@ -278,6 +444,31 @@ var _ = Describe("Insert", func() {
 		Expect(s).To(Equal(`INSERT INTO "uint_models" ("id", "other_id") VALUES (1, 2), (2, DEFAULT) RETURNING "other_id"`))
 	})
 
+	It("supports data-modifying WITH", func() {
+		deleted := NewQuery(nil, &DeleteTest{}).
+			Where("?TableAlias.id = ?", 1).
+			Returning("*")
+		q := NewQuery(nil, &InsertTest{}).
+			WithDelete("deleted", deleted).
+			Table("deleted")
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`WITH "deleted" AS (DELETE FROM "delete_tests" AS "delete_test" WHERE ("delete_test".id = 1) RETURNING *) INSERT INTO "insert_tests" SELECT * FROM "deleted"`))
+	})
+
+	It("supports data-modifying WITH without models", func() {
+		deleted := NewQuery(nil).
+			Table("books").
+			Where("id = ?", 1).
+			Returning("*")
+		q := NewQuery(nil).
+			WithDelete("deleted", deleted).
+			Table("archive", "deleted")
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`WITH "deleted" AS (DELETE FROM "books" WHERE (id = 1) RETURNING *) INSERT INTO "archive" SELECT * FROM "deleted"`))
+	})
+
 	It("supports map[string]interface{}", func() {
 		q := NewQuery(nil, &map[string]interface{}{
 			"hello": "world",
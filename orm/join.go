@@ -1,6 +1,7 @@
 package orm
 
 import (
+	"fmt"
 	"reflect"
 
 	"github.com/go-pg/pg/v10/internal"
@@ -58,17 +59,28 @@ func (j *join) manyQuery(q *Query) (*Query, error) {
 		}
 	}
 
+	if q.limitParam > 0 || q.offsetParam > 0 {
+		return nil, fmt.Errorf(
+			"pg: LimitOffsetParams is not supported by relation=%q", j.Rel.Field.GoName)
+	}
+
 	if len(q.columns) == 0 {
 		q.columns = append(q.columns, &hasManyColumnsAppender{j})
 	}
 
 	baseTable := j.BaseModel.Table()
+	joinAlias, joinFKs := j.JoinModel.Table().Alias, j.Rel.JoinFKs
+	if j.Rel.JoinOn != "" {
+		q = j.joinParent(q)
+		joinAlias, joinFKs = parentAlias, baseTable.PKs
+	}
+
 	var where []byte
-	if len(j.Rel.JoinFKs) > 1 {
+	if len(joinFKs) > 1 {
 		where = append(where, '(')
 	}
-	where = appendColumns(where, j.JoinModel.Table().Alias, j.Rel.JoinFKs)
-	if len(j.Rel.JoinFKs) > 1 {
+	where = appendColumns(where, joinAlias, joinFKs)
+	if len(joinFKs) > 1 {
 		where = append(where, ')')
 	}
 	where = append(where, " IN ("...)
@@ -83,9 +95,107 @@ func (j *join) manyQuery(q *Query) (*Query, error) {
 			baseTable.ModelName, baseTable.TypeName)
 	}
 
+	if q.limit > 0 || q.offset > 0 {
+		q = j.limitPerParent(q)
+	}
+
 	return q, nil
 }
 
+// parentAlias is the alias of the base table in the query of has-many
+// relation with a join condition.
+const parentAlias types.Safe = `"parent"`
+
+// parentColumnPrefix is the prefix of the base table primary keys selected
+// to match the rows of has-many relation with a join condition to parents.
+const parentColumnPrefix = "_parent__"
+
+// joinParent joins the base table using the join condition of the relation
+// and selects its primary keys:
+//
+//    SELECT "event".*, "parent"."id" AS "_parent__id" FROM "events" AS "event"
+//    JOIN "calendars" AS "parent" ON (event.during && parent.during)
+func (j *join) joinParent(q *Query) *Query {
+	baseTable := j.BaseModel.Table()
+
+	var b []byte
+	for i, pk := range baseTable.PKs {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = append(b, parentAlias...)
+		b = append(b, '.')
+		b = append(b, pk.Column...)
+		b = append(b, " AS "...)
+		b = types.AppendIdent(b, parentColumnPrefix+pk.SQLName, 1)
+	}
+	q = q.ColumnExpr(internal.BytesToString(b))
+
+	join := "JOIN " + string(baseTable.SQLNameForSelects) + " AS " + string(parentAlias) +
+		" ON (" + j.Rel.JoinOn + ")"
+	return q.Join(join)
+}
+
+// limitPerParent rewrites the query so LIMIT and OFFSET are applied
+// to the rows of every parent instead of the whole result:
+//
+//    SELECT * FROM (
+//      SELECT ..., row_number() OVER (PARTITION BY fk ORDER BY ...) AS "_row_number"
+//      FROM ... WHERE ...
+//    ) AS alias WHERE "_row_number" > offset AND "_row_number" <= offset + limit
+func (j *join) limitPerParent(q *Query) *Query {
+	limit, offset := q.limit, q.offset
+
+	inner := q.Clone()
+	inner.columns = append(inner.columns, &rowNumberAppender{
+		join:  j,
+		order: inner.order,
+	})
+	inner.order = nil
+	inner.limit = 0
+	inner.offset = 0
+
+	joinTable := j.JoinModel.Table()
+	outer := q.New().withFlag(allWithDeletedFlag)
+	outer = outer.TableExpr("(?) AS ?", inner, joinTable.Alias).
+		ColumnExpr("?.*", joinTable.Alias).
+		Where(`"_row_number" > ?`, offset)
+	if limit > 0 {
+		outer = outer.Where(`"_row_number" <= ?`, offset+limit)
+	}
+	return outer.OrderExpr(`"_row_number" ASC`)
+}
+
+type rowNumberAppender struct {
+	*join
+	order []QueryAppender
+}
+
+var _ QueryAppender = (*rowNumberAppender)(nil)
+
+func (q *rowNumberAppender) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err error) {
+	b = append(b, "row_number() OVER (PARTITION BY "...)
+	if q.Rel.JoinOn != "" {
+		b = appendColumns(b, parentAlias, q.BaseModel.Table().PKs)
+	} else {
+		b = appendColumns(b, q.JoinModel.Table().Alias, q.Rel.JoinFKs)
+	}
+	if len(q.order) > 0 {
+		b = append(b, " ORDER BY "...)
+		for i, f := range q.order {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			b, err = f.AppendQuery(fmter, b)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	b = append(b, `) AS "_row_number"`...)
+	return b, nil
+}
+
 func (j *join) selectM2M(fmter QueryFormatter, q *Query) error {
 	q, err := j.m2mQuery(fmter, q)
 	if err != nil {
@@ -236,7 +346,7 @@ func (j *join) appendHasOneColumns(b []byte) []byte {
 }
 
 func (j *join) appendHasOneJoin(fmter QueryFormatter, b []byte, q *Query) (_ []byte, err error) {
-	isSoftDelete := j.JoinModel.Table().SoftDeleteField != nil && !q.hasFlag(allWithDeletedFlag)
+	isSoftDelete := j.JoinModel.Table().SoftDeleteField != nil && !q.allWithDeleted()
 
 	b = append(b, "LEFT JOIN "...)
 	b = fmter.FormatQuery(b, string(j.JoinModel.Table().SQLNameForSelects))
@@ -0,0 +1,144 @@
+package orm
+
+import (
+	"errors"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/go-pg/pg/v10/internal"
+)
+
+// mergeUnsupportedError is returned by Query.Merge when the server is older
+// than PostgreSQL 15. It wraps the syntax error returned by the server.
+type mergeUnsupportedError struct {
+	err error
+}
+
+func (e mergeUnsupportedError) Error() string {
+	return "pg: MERGE requires PostgreSQL 15 or later: " + e.err.Error()
+}
+
+func (e mergeUnsupportedError) Unwrap() error {
+	return e.err
+}
+
+// MergeQuery merges the source into the model table. See Query.MergeUsing.
+type MergeQuery struct {
+	q *Query
+}
+
+var (
+	_ QueryAppender = (*MergeQuery)(nil)
+	_ QueryCommand  = (*MergeQuery)(nil)
+)
+
+func NewMergeQuery(q *Query) *MergeQuery {
+	return &MergeQuery{
+		q: q,
+	}
+}
+
+func (q *MergeQuery) String() string {
+	b, err := q.AppendQuery(defaultFmter, nil)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func (q *MergeQuery) Operation() QueryOp {
+	return MergeOp
+}
+
+func (q *MergeQuery) Clone() QueryCommand {
+	return &MergeQuery{
+		q: q.q.Clone(),
+	}
+}
+
+func (q *MergeQuery) Query() *Query {
+	return q.q
+}
+
+func (q *MergeQuery) AppendTemplate(b []byte) ([]byte, error) {
+	return q.AppendQuery(dummyFormatter{}, b)
+}
+
+func (q *MergeQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err error) {
+	if q.q.stickyErr != nil {
+		return nil, q.q.stickyErr
+	}
+	if !q.q.hasTables() {
+		return nil, errors.New("pg: Merge requires a model or a table")
+	}
+	if q.q.mergeUsing == nil {
+		return nil, errors.New("pg: Merge requires MergeUsing")
+	}
+	if len(q.q.mergeWhen) == 0 {
+		return nil, errors.New("pg: Merge requires WhenMatched or WhenNotMatched")
+	}
+
+	b, err = q.appendPrefix(fmter, b)
+	if err != nil {
+		return nil, err
+	}
+
+	b = append(b, "MERGE INTO "...)
+	b, err = q.q.appendFirstTableWithAlias(fmter, b)
+	if err != nil {
+		return nil, err
+	}
+
+	b = append(b, " USING "...)
+	b, err = q.q.mergeUsing.AppendQuery(fmter, b)
+	if err != nil {
+		return nil, err
+	}
+
+	b = append(b, " ON ("...)
+	b, err = q.q.mergeOn.AppendQuery(fmter, b)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, ')')
+
+	for _, when := range q.q.mergeWhen {
+		b = append(b, ' ')
+		b, err = when.AppendQuery(fmter, b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// appendPrefix appends the comment and the WITH clause preceding
+// the MERGE keyword.
+func (q *MergeQuery) appendPrefix(fmter QueryFormatter, b []byte) (_ []byte, err error) {
+	b = q.q.appendComment(b)
+	if len(q.q.with) > 0 {
+		b, err = q.q.appendWith(fmter, b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// isMergeSyntaxError reports whether the server does not know the MERGE
+// statement, i.e. it is older than PostgreSQL 15. The error is a syntax
+// error positioned at the MERGE keyword, which does not depend on the
+// language of the server messages.
+func (q *MergeQuery) isMergeSyntaxError(fmter QueryFormatter, err internal.PGError) bool {
+	if err.Field('C') != "42601" {
+		return false
+	}
+	prefix, prefixErr := q.appendPrefix(fmter, nil)
+	if prefixErr != nil {
+		return false
+	}
+	// Position is a 1-based index in characters.
+	pos := strconv.Itoa(utf8.RuneCount(prefix) + 1)
+	return err.Field('P') == pos
+}
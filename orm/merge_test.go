@@ -0,0 +1,104 @@
+package orm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-pg/pg/v10/internal"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Merge", func() {
+	It("merges a table", func() {
+		q := NewQuery(nil, &InsertTest{}).
+			MergeUsing("staged", "s", "insert_test.id = s.id").
+			WhenMatched("AND s.deleted THEN DELETE").
+			WhenMatched("THEN UPDATE SET value = s.value").
+			WhenNotMatched("THEN INSERT (id, value) VALUES (s.id, s.value)")
+
+		s := mergeQueryString(q)
+		Expect(s).To(Equal(`MERGE INTO "insert_tests" AS "insert_test" USING "staged" AS "s" ON (insert_test.id = s.id) WHEN MATCHED AND s.deleted THEN DELETE WHEN MATCHED THEN UPDATE SET value = s.value WHEN NOT MATCHED THEN INSERT (id, value) VALUES (s.id, s.value)`))
+	})
+
+	It("merges a subquery with params", func() {
+		src := NewQuery(nil, &InsertTest{}).Column("id", "value").Where("id > ?", 10)
+		q := NewQuery(nil).
+			Table("archive").
+			MergeUsing(src, "s", "archive.id = s.id AND archive.value <> ?", "").
+			WhenNotMatched("THEN INSERT VALUES (s.id, ?)", "new")
+
+		s := mergeQueryString(q)
+		Expect(s).To(Equal(`MERGE INTO "archive" USING (SELECT "id", "value" FROM "insert_tests" AS "insert_test" WHERE (id > 10)) AS "s" ON (archive.id = s.id AND archive.value <> '') WHEN NOT MATCHED THEN INSERT VALUES (s.id, 'new')`))
+	})
+
+	It("merges a VALUES list", func() {
+		rows := []ValuesRow{{ID: 1, Name: "a"}}
+		q := NewQuery(nil, &InsertTest{}).
+			MergeUsing(NewValuesQuery(rows), "v", "insert_test.id = v.id").
+			WhenMatched("THEN DO NOTHING")
+
+		s := mergeQueryString(q)
+		Expect(s).To(Equal(`MERGE INTO "insert_tests" AS "insert_test" USING (VALUES (1::bigint, 'a'::text)) AS "v" ("id", "name") ON (insert_test.id = v.id) WHEN MATCHED THEN DO NOTHING`))
+	})
+
+	It("does not set the alias on the source VALUES list", func() {
+		values := NewValuesQuery([]ValuesRow{{ID: 1, Name: "a"}})
+		NewQuery(nil, &InsertTest{}).MergeUsing(values, "v", "insert_test.id = v.id")
+		Expect(values.alias).To(Equal(""))
+	})
+
+	It("detects servers without MERGE", func() {
+		q := NewQuery(nil, &InsertTest{}).
+			MergeUsing("staged", "s", "true").
+			WhenMatched("THEN DELETE")
+		merge := NewMergeQuery(q)
+
+		err := internal.NewPGError(map[byte]string{
+			'C': "42601",
+			'M': `Syntaxfehler bei »MERGE«`,
+			'P': "1",
+		})
+		Expect(merge.isMergeSyntaxError(defaultFmter, err)).To(BeTrue())
+
+		err = internal.NewPGError(map[byte]string{
+			'C': "42601",
+			'M': `syntax error at or near "WHEN"`,
+			'P': "60",
+		})
+		Expect(merge.isMergeSyntaxError(defaultFmter, err)).To(BeFalse())
+
+		q = q.Comment("é").With("staged", NewQuery(nil).Table("items"))
+		err = internal.NewPGError(map[byte]string{
+			'C': "42601",
+			'P': strconv.Itoa(len([]rune(strings.Split(merge.String(), "MERGE")[0])) + 1),
+		})
+		Expect(merge.isMergeSyntaxError(defaultFmter, err)).To(BeTrue())
+	})
+
+	It("wraps the syntax error of servers without MERGE", func() {
+		pgErr := internal.NewPGError(map[byte]string{'S': "ERROR", 'C': "42601", 'M': "syntax error"})
+		err := error(mergeUnsupportedError{err: pgErr})
+		Expect(err).To(MatchError("pg: MERGE requires PostgreSQL 15 or later: ERROR #42601 syntax error"))
+		Expect(internal.Unwrap(err)).To(Equal(pgErr))
+	})
+
+	It("returns an error without source and actions", func() {
+		_, err := NewMergeQuery(NewQuery(nil, &InsertTest{})).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: Merge requires MergeUsing"))
+
+		q := NewQuery(nil, &InsertTest{}).MergeUsing("staged", "s", "true")
+		_, err = NewMergeQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: Merge requires WhenMatched or WhenNotMatched"))
+
+		q = NewQuery(nil, &InsertTest{}).MergeUsing(1, "s", "true")
+		_, err = NewMergeQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: MergeUsing does not support int"))
+	})
+})
+
+func mergeQueryString(q *Query) string {
+	qq := NewMergeQuery(q)
+	return queryString(qq)
+}
@@ -0,0 +1,119 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+// bulkUpdateModel scans the rows returned by a bulk update into the slice
+// elements with the same primary key. The returned rows are not ordered
+// and rows that were not updated are not returned at all, so the elements
+// can't be scanned in order like sliceTableModel does.
+type bulkUpdateModel struct {
+	Discard
+	table *Table
+	elems map[string][]reflect.Value
+}
+
+var _ Model = (*bulkUpdateModel)(nil)
+
+func newBulkUpdateModel(m *sliceTableModel) *bulkUpdateModel {
+	elems := make(map[string][]reflect.Value, m.sliceLen)
+	var id []byte
+	for i := 0; i < m.sliceLen; i++ {
+		strct := indirect(m.slice.Index(i))
+		id = modelID(id[:0], strct, m.table.PKs)
+		elems[string(id)] = append(elems[string(id)], strct)
+	}
+	return &bulkUpdateModel{
+		table: m.table,
+		elems: elems,
+	}
+}
+
+func (m *bulkUpdateModel) NextColumnScanner() ColumnScanner {
+	return &bulkUpdateRow{
+		model: m,
+		strct: reflect.New(m.table.Type).Elem(),
+	}
+}
+
+// bulkUpdateRow scans a returned row into a new struct and copies the
+// scanned fields into the matching slice elements once the row is read.
+type bulkUpdateRow struct {
+	model   *bulkUpdateModel
+	strct   reflect.Value
+	scanned []*Field
+}
+
+var (
+	_ BeforeScanHook = (*bulkUpdateRow)(nil)
+	_ AfterScanHook  = (*bulkUpdateRow)(nil)
+)
+
+func (r *bulkUpdateRow) ScanColumn(col types.ColumnInfo, rd types.Reader, n int) error {
+	field, ok := r.model.table.FieldsMap[col.Name]
+	if !ok {
+		if r.model.table.hasFlag(discardUnknownColumnsFlag) || col.Name[0] == '_' {
+			return nil
+		}
+		return fmt.Errorf(
+			"pg: can't find column=%s in %s "+
+				"(prefix the column with underscore or use discard_unknown_columns)",
+			col.Name, r.model.table,
+		)
+	}
+
+	// RETURNING * also returns the columns of the VALUES list,
+	// which follow the columns of the updated table.
+	if r.hasScanned(field) {
+		return nil
+	}
+
+	r.scanned = append(r.scanned, field)
+	return field.ScanValue(r.strct, rd, n)
+}
+
+func (r *bulkUpdateRow) BeforeScan(ctx context.Context) error {
+	return nil
+}
+
+func (r *bulkUpdateRow) AfterScan(ctx context.Context) error {
+	for _, pk := range r.model.table.PKs {
+		if !r.hasScanned(pk) {
+			return fmt.Errorf("pg: bulk update RETURNING must include %s", pk.Column)
+		}
+	}
+
+	id := modelID(nil, r.strct, r.model.table.PKs)
+	for _, strct := range r.model.elems[string(id)] {
+		if r.model.table.hasFlag(beforeScanHookFlag) {
+			if err := callBeforeScanHook(ctx, strct.Addr()); err != nil {
+				return err
+			}
+		}
+
+		for _, f := range r.scanned {
+			f.Value(strct).Set(f.Value(r.strct))
+		}
+
+		if r.model.table.hasFlag(afterScanHookFlag) {
+			if err := callAfterScanHook(ctx, strct.Addr()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *bulkUpdateRow) hasScanned(field *Field) bool {
+	for _, f := range r.scanned {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
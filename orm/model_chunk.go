@@ -0,0 +1,19 @@
+package orm
+
+// chunkModel initializes the model only once, so rows fetched in several
+// chunks are added to the same model.
+type chunkModel struct {
+	Model
+
+	inited bool
+}
+
+var _ Model = (*chunkModel)(nil)
+
+func (m *chunkModel) Init() error {
+	if m.inited {
+		return nil
+	}
+	m.inited = true
+	return m.Model.Init()
+}
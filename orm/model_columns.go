@@ -0,0 +1,65 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+// columnsModel scans rows into per column slices.
+type columnsModel struct {
+	hookStubs
+	names   []string
+	columns []reflect.Value
+}
+
+var _ Model = (*columnsModel)(nil)
+
+func newColumnsModel() *columnsModel {
+	return new(columnsModel)
+}
+
+func (m *columnsModel) Init() error {
+	m.names = m.names[:0]
+	m.columns = m.columns[:0]
+	return nil
+}
+
+func (m *columnsModel) NextColumnScanner() ColumnScanner {
+	return m
+}
+
+func (m *columnsModel) AddColumnScanner(ColumnScanner) error {
+	return nil
+}
+
+func (m *columnsModel) ScanColumn(col types.ColumnInfo, rd types.Reader, n int) error {
+	idx := int(col.Index)
+	if idx == len(m.columns) {
+		for _, name := range m.names {
+			if name == col.Name {
+				return fmt.Errorf("pg: SelectColumns got duplicate column %q", col.Name)
+			}
+		}
+		typ := reflect.SliceOf(types.ColumnValueType(col))
+		m.names = append(m.names, col.Name)
+		m.columns = append(m.columns, reflect.MakeSlice(typ, 0, 0))
+	}
+
+	val, err := types.ReadColumnValue(col, rd, n)
+	if err != nil {
+		return err
+	}
+
+	m.columns[idx] = reflect.Append(m.columns[idx], reflect.ValueOf(val))
+	return nil
+}
+
+func (m *columnsModel) result() map[string]interface{} {
+	res := make(map[string]interface{}, len(m.names))
+	for i, name := range m.names {
+		res[name] = m.columns[i].Interface()
+	}
+	return res
+}
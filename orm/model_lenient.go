@@ -0,0 +1,114 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+// RowError is returned by SelectLenient for every row that can't be scanned.
+type RowError struct {
+	// Row is the index of the row in the query result.
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("pg: row %d: %s", e.Row, e.Err)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// lenientModel wraps a table model and skips rows that can't be scanned
+// collecting their errors instead of failing the whole query. All rows are
+// still read from the connection so it remains usable.
+type lenientModel struct {
+	TableModel
+
+	numRow int
+	errs   []RowError
+}
+
+var _ Model = (*lenientModel)(nil)
+
+func newLenientModel(m TableModel) *lenientModel {
+	return &lenientModel{
+		TableModel: m,
+	}
+}
+
+func (m *lenientModel) NextColumnScanner() ColumnScanner {
+	return &lenientScanner{
+		ColumnScanner: m.TableModel.NextColumnScanner(),
+	}
+}
+
+func (m *lenientModel) AddColumnScanner(s ColumnScanner) error {
+	ls := s.(*lenientScanner)
+
+	row := m.numRow
+	m.numRow++
+
+	if ls.err == nil {
+		return m.TableModel.AddColumnScanner(ls.ColumnScanner)
+	}
+
+	m.errs = append(m.errs, RowError{
+		Row: row,
+		Err: ls.err,
+	})
+	if sm, ok := m.TableModel.(*sliceTableModel); ok {
+		// Remove the element that was appended by NextColumnScanner.
+		sm.slice.Set(sm.slice.Slice(0, sm.slice.Len()-1))
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type lenientScanner struct {
+	ColumnScanner
+	err error
+}
+
+var (
+	_ BeforeScanHook = (*lenientScanner)(nil)
+	_ AfterScanHook  = (*lenientScanner)(nil)
+)
+
+func (s *lenientScanner) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *lenientScanner) ScanColumn(col types.ColumnInfo, rd types.Reader, n int) error {
+	if err := s.ColumnScanner.ScanColumn(col, rd, n); err != nil {
+		s.setErr(err)
+	}
+	return nil
+}
+
+func (s *lenientScanner) BeforeScan(ctx context.Context) error {
+	if h, ok := s.ColumnScanner.(BeforeScanHook); ok {
+		if err := h.BeforeScan(ctx); err != nil {
+			s.setErr(err)
+		}
+	}
+	return nil
+}
+
+func (s *lenientScanner) AfterScan(ctx context.Context) error {
+	if s.err != nil {
+		return nil
+	}
+	if h, ok := s.ColumnScanner.(AfterScanHook); ok {
+		if err := h.AfterScan(ctx); err != nil {
+			s.setErr(err)
+		}
+	}
+	return nil
+}
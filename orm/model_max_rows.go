@@ -0,0 +1,56 @@
+package orm
+
+import (
+	"github.com/go-pg/pg/v10/internal"
+)
+
+// maxRowsModel stops scanning rows into the model once more than max rows
+// are returned. The remaining rows are read and discarded so the
+// connection stays usable, and the query fails with ErrTooManyRows.
+type maxRowsModel struct {
+	Model
+
+	max int
+	n   int
+}
+
+var _ Model = (*maxRowsModel)(nil)
+
+func newMaxRowsModel(model Model, max int) Model {
+	m := &maxRowsModel{
+		Model: model,
+		max:   max,
+	}
+	if _, ok := model.(useQueryOne); ok {
+		return maxRowsOneModel{m}
+	}
+	return m
+}
+
+func (m *maxRowsModel) Init() error {
+	m.n = 0
+	return m.Model.Init()
+}
+
+func (m *maxRowsModel) NextColumnScanner() ColumnScanner {
+	m.n++
+	if m.n > m.max {
+		return Discard{}
+	}
+	return m.Model.NextColumnScanner()
+}
+
+func (m *maxRowsModel) AddColumnScanner(s ColumnScanner) error {
+	if m.n > m.max {
+		return internal.ErrTooManyRows
+	}
+	return m.Model.AddColumnScanner(s)
+}
+
+type maxRowsOneModel struct {
+	*maxRowsModel
+}
+
+func (maxRowsOneModel) useQueryOne() bool {
+	return true
+}
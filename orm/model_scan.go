@@ -67,3 +67,103 @@ func (m scanReflectValuesModel) ScanColumn(col types.ColumnInfo, rd types.Reader
 	}
 	return types.ScanValue(m.values[col.Index], rd, n)
 }
+
+//------------------------------------------------------------------------------
+
+type scanPositionalModel struct {
+	Discard
+
+	value   reflect.Value // struct or slice of structs
+	slice   bool
+	elemPtr bool
+	table   *Table
+
+	strct  reflect.Value
+	numCol int
+
+	err error
+}
+
+var _ Model = (*scanPositionalModel)(nil)
+
+// ScanPositional returns a model that scans columns into the fields of dest
+// by position instead of by name: the first column is scanned into the
+// first field and so on. Dest must be a pointer to a struct or a slice of
+// structs. Scanning fails when the number of columns does not match the
+// number of fields.
+//nolint
+func ScanPositional(dest interface{}) *scanPositionalModel {
+	m := new(scanPositionalModel)
+
+	v := reflect.ValueOf(dest)
+	if !v.IsValid() || v.Kind() != reflect.Ptr || v.IsNil() {
+		m.err = fmt.Errorf("pg: ScanPositional(non-pointer %T)", dest)
+		return m
+	}
+	m.value = v.Elem()
+
+	typ := m.value.Type()
+	if typ.Kind() == reflect.Slice {
+		m.slice = true
+		typ = typ.Elem()
+		if typ.Kind() == reflect.Ptr {
+			m.elemPtr = true
+			typ = typ.Elem()
+		}
+	}
+	if typ.Kind() != reflect.Struct {
+		m.err = fmt.Errorf("pg: ScanPositional(unsupported %T)", dest)
+		return m
+	}
+
+	m.table = GetTable(typ)
+	return m
+}
+
+func (m *scanPositionalModel) useQueryOne() bool {
+	return !m.slice
+}
+
+func (m *scanPositionalModel) Init() error {
+	if m.err != nil {
+		return m.err
+	}
+	if m.slice && m.value.Len() > 0 {
+		m.value.Set(m.value.Slice(0, 0))
+	}
+	return nil
+}
+
+func (m *scanPositionalModel) NextColumnScanner() ColumnScanner {
+	if m.slice {
+		m.strct = reflect.New(m.table.Type).Elem()
+	} else {
+		m.strct = m.value
+	}
+	m.numCol = 0
+	return m
+}
+
+func (m *scanPositionalModel) AddColumnScanner(ColumnScanner) error {
+	if m.numCol != len(m.table.Fields) {
+		return fmt.Errorf("pg: ScanPositional got %d columns for %d fields of %s",
+			m.numCol, len(m.table.Fields), m.table.Type)
+	}
+
+	if m.slice {
+		if m.elemPtr {
+			m.value.Set(reflect.Append(m.value, m.strct.Addr()))
+		} else {
+			m.value.Set(reflect.Append(m.value, m.strct))
+		}
+	}
+	return nil
+}
+
+func (m *scanPositionalModel) ScanColumn(col types.ColumnInfo, rd types.Reader, n int) error {
+	m.numCol = int(col.Index) + 1
+	if int(col.Index) >= len(m.table.Fields) {
+		return nil
+	}
+	return m.table.Fields[col.Index].ScanValue(m.strct, rd, n)
+}
@@ -0,0 +1,56 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+// slicesModel scans every returned column into its own slice, e.g.
+// RETURNING id, title into &ids and &titles.
+type slicesModel struct {
+	Discard
+	slices []*sliceModel
+}
+
+var _ Model = (*slicesModel)(nil)
+
+func newSlicesModel(values []interface{}) (*slicesModel, bool) {
+	slices := make([]*sliceModel, len(values))
+	for i, value := range values {
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+		if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+		slices[i] = newSliceModel(v, v.Type().Elem())
+	}
+	return &slicesModel{
+		slices: slices,
+	}, true
+}
+
+func (m *slicesModel) Init() error {
+	for _, slice := range m.slices {
+		if err := slice.Init(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *slicesModel) NextColumnScanner() ColumnScanner {
+	return m
+}
+
+func (m *slicesModel) ScanColumn(col types.ColumnInfo, rd types.Reader, n int) error {
+	if int(col.Index) >= len(m.slices) {
+		return fmt.Errorf("pg: no Scan var for column index=%d name=%q",
+			col.Index, col.Name)
+	}
+	return m.slices[col.Index].ScanColumn(col, rd, n)
+}
@@ -3,6 +3,9 @@ package orm
 import (
 	"fmt"
 	"reflect"
+	"strings"
+
+	"github.com/go-pg/pg/v10/types"
 )
 
 type manyModel struct {
@@ -12,6 +15,10 @@ type manyModel struct {
 
 	buf       []byte
 	dstValues map[string][]reflect.Value
+
+	// parent holds the primary keys of the base row scanned
+	// for relations with a join condition.
+	parent reflect.Value
 }
 
 var _ TableModel = (*manyModel)(nil)
@@ -33,6 +40,9 @@ func newManyModel(j *join) *manyModel {
 	if !m.sliceOfPtr {
 		m.strct = reflect.New(m.table.Type).Elem()
 	}
+	if m.rel.JoinOn != "" {
+		m.parent = reflect.New(baseTable.Type).Elem()
+	}
 	return &m
 }
 
@@ -46,8 +56,23 @@ func (m *manyModel) NextColumnScanner() ColumnScanner {
 	return m
 }
 
+func (m *manyModel) ScanColumn(col types.ColumnInfo, rd types.Reader, n int) error {
+	if m.rel.JoinOn != "" && strings.HasPrefix(col.Name, parentColumnPrefix) {
+		field, err := m.baseTable.GetField(col.Name[len(parentColumnPrefix):])
+		if err != nil {
+			return err
+		}
+		return field.ScanValue(m.parent, rd, n)
+	}
+	return m.sliceTableModel.ScanColumn(col, rd, n)
+}
+
 func (m *manyModel) AddColumnScanner(model ColumnScanner) error {
-	m.buf = modelID(m.buf[:0], m.strct, m.rel.JoinFKs)
+	if m.rel.JoinOn != "" {
+		m.buf = modelID(m.buf[:0], m.parent, m.baseTable.PKs)
+	} else {
+		m.buf = modelID(m.buf[:0], m.strct, m.rel.JoinFKs)
+	}
 	dstValues, ok := m.dstValues[string(m.buf)]
 	if !ok {
 		return fmt.Errorf(
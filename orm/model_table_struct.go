@@ -292,7 +292,13 @@ func (m *structTableModel) scanColumn(col types.ColumnInfo, rd types.Reader, n i
 
 	field, ok := m.table.FieldsMap[col.Name]
 	if !ok {
-		return false, nil
+		if !m.table.hasFlag(looseColumnsFlag) || col.Name == "" || col.Name[0] == '_' {
+			return false, nil
+		}
+		field = m.table.fieldByAlias(col.Name)
+		if field == nil {
+			return false, nil
+		}
 	}
 
 	return true, field.ScanValue(m.strct, rd, n)
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-pg/pg/v10/internal"
@@ -24,8 +25,12 @@ const (
 	DeleteOp          QueryOp = "DELETE"
 	CreateTableOp     QueryOp = "CREATE TABLE"
 	DropTableOp       QueryOp = "DROP TABLE"
+	TruncateOp        QueryOp = "TRUNCATE"
 	CreateCompositeOp QueryOp = "CREATE COMPOSITE"
 	DropCompositeOp   QueryOp = "DROP COMPOSITE"
+	SelectIntoOp      QueryOp = "SELECT INTO"
+	MergeOp           QueryOp = "MERGE"
+	AddColumnsOp      QueryOp = "ADD COLUMNS"
 )
 
 type queryFlag uint8
@@ -34,6 +39,10 @@ const (
 	implicitModelFlag queryFlag = 1 << iota
 	deletedFlag
 	allWithDeletedFlag
+	noInsertSplitFlag
+	usePrimaryFlag
+	overridingSystemValueFlag
+	softDeleteFlag
 )
 
 type withQuery struct {
@@ -46,6 +55,11 @@ type columnValue struct {
 	value  *SafeQueryAppender
 }
 
+type namedWindow struct {
+	name string
+	expr *SafeQueryAppender
+}
+
 type union struct {
 	expr  string
 	query *Query
@@ -71,16 +85,36 @@ type Query struct {
 	updWhere     []queryWithSepAppender
 	group        []QueryAppender
 	having       []*SafeQueryAppender
+	windows      []*namedWindow
 	union        []*union
 	joins        []QueryAppender
-	joinAppendOn func(app *condAppender)
+	joinAppendOn joinOnAppender
 	order        []QueryAppender
 	limit        int
 	offset       int
+	limitParam   int
+	offsetParam  int
+	withTies     bool
 	selFor       *SafeQueryAppender
+	selForWait   string
+	tableSample  *tableSample
+	chunkSize    int
+	maxRows      int
+	comment      string
+
+	upsertActions interface{}
+
+	insertFields         []*Field
+	updateFields         []*Field
+	onConflict           *SafeQueryAppender
+	onConflictConstraint string
+	onConflictColumns    []string
+	onConflictWhere      *SafeQueryAppender
+	returning            []*SafeQueryAppender
 
-	onConflict *SafeQueryAppender
-	returning  []*SafeQueryAppender
+	mergeUsing *SafeQueryAppender
+	mergeOn    *SafeQueryAppender
+	mergeWhen  []*SafeQueryAppender
 }
 
 func NewQuery(db DB, model ...interface{}) *Query {
@@ -109,7 +143,10 @@ func (q *Query) New() *Query {
 	return clone.withFlag(implicitModelFlag)
 }
 
-// Clone clones the Query.
+// Clone clones the Query. Columns, conditions, joins, and relations added to
+// the clone do not affect the original query and vice versa, so a base query
+// can be used to derive several queries, e.g. one for count and one for
+// a page. The model itself is shared.
 func (q *Query) Clone() *Query {
 	var modelValues map[string]*SafeQueryAppender
 	if len(q.modelValues) > 0 {
@@ -137,22 +174,71 @@ func (q *Query) Clone() *Query {
 		extraValues: q.extraValues[:len(q.extraValues):len(q.extraValues)],
 		where:       q.where[:len(q.where):len(q.where)],
 		updWhere:    q.updWhere[:len(q.updWhere):len(q.updWhere)],
-		joins:       q.joins[:len(q.joins):len(q.joins)],
 		group:       q.group[:len(q.group):len(q.group)],
 		having:      q.having[:len(q.having):len(q.having)],
+		windows:     q.windows[:len(q.windows):len(q.windows)],
 		union:       q.union[:len(q.union):len(q.union)],
 		order:       q.order[:len(q.order):len(q.order)],
 		limit:       q.limit,
 		offset:      q.offset,
+		limitParam:  q.limitParam,
+		offsetParam: q.offsetParam,
+		withTies:    q.withTies,
 		selFor:      q.selFor,
+		selForWait:  q.selForWait,
+		tableSample: q.tableSample,
+		chunkSize:   q.chunkSize,
+		maxRows:     q.maxRows,
+		comment:     q.comment,
 
-		onConflict: q.onConflict,
-		returning:  q.returning[:len(q.returning):len(q.returning)],
+		upsertActions: q.upsertActions,
+
+		insertFields:         q.insertFields[:len(q.insertFields):len(q.insertFields)],
+		updateFields:         q.updateFields[:len(q.updateFields):len(q.updateFields)],
+		onConflict:           q.onConflict,
+		onConflictConstraint: q.onConflictConstraint,
+		onConflictColumns:    q.onConflictColumns[:len(q.onConflictColumns):len(q.onConflictColumns)],
+		onConflictWhere:      q.onConflictWhere,
+		returning:            q.returning[:len(q.returning):len(q.returning)],
+
+		mergeUsing: q.mergeUsing,
+		mergeOn:    q.mergeOn,
+		mergeWhen:  q.mergeWhen[:len(q.mergeWhen):len(q.mergeWhen)],
 	}
+	clone.joins, clone.joinAppendOn = q.cloneJoins(clone.tableModel)
 
 	return clone
 }
 
+// cloneJoins copies joins added with Join so JoinOn conditions applied to
+// the clone do not leak into the original query.
+func (q *Query) cloneJoins(tableModel TableModel) ([]QueryAppender, joinOnAppender) {
+	var appendOn joinOnAppender
+	if j, ok := q.joinAppendOn.(*join); ok {
+		appendOn = findClonedJoin(q.tableModel, tableModel, j)
+	}
+
+	if q.joins == nil {
+		return nil, appendOn
+	}
+
+	joins := make([]QueryAppender, len(q.joins))
+	for i, app := range q.joins {
+		if j, ok := app.(*joinQuery); ok {
+			clone := &joinQuery{
+				join: j.join,
+				on:   j.on[:len(j.on):len(j.on)],
+			}
+			if q.joinAppendOn == joinOnAppender(j) {
+				appendOn = clone
+			}
+			app = clone
+		}
+		joins[i] = app
+	}
+	return joins, appendOn
+}
+
 func cloneTableModelJoins(tm TableModel) TableModel {
 	switch tm := tm.(type) {
 	case *structTableModel:
@@ -160,19 +246,53 @@ func cloneTableModelJoins(tm TableModel) TableModel {
 			return tm
 		}
 		clone := *tm
-		clone.joins = clone.joins[:len(clone.joins):len(clone.joins)]
+		clone.joins = cloneModelJoins(clone.joins)
 		return &clone
 	case *sliceTableModel:
 		if len(tm.joins) == 0 {
 			return tm
 		}
 		clone := *tm
-		clone.joins = clone.joins[:len(clone.joins):len(clone.joins)]
+		clone.joins = cloneModelJoins(clone.joins)
 		return &clone
 	}
 	return tm
 }
 
+// cloneModelJoins copies relation joins including nested ones so applying
+// a relation to the clone does not modify the original model.
+func cloneModelJoins(joins []join) []join {
+	clone := make([]join, len(joins))
+	for i, j := range joins {
+		j.on = j.on[:len(j.on):len(j.on)]
+		j.JoinModel = cloneTableModelJoins(j.JoinModel)
+		clone[i] = j
+	}
+	return clone
+}
+
+func findClonedJoin(orig, clone TableModel, target *join) *join {
+	if orig == nil || clone == nil {
+		return nil
+	}
+	origJoins := orig.GetJoins()
+	cloneJoins := clone.GetJoins()
+	for i := range origJoins {
+		if &origJoins[i] == target {
+			return &cloneJoins[i]
+		}
+		j := findClonedJoin(origJoins[i].JoinModel, cloneJoins[i].JoinModel, target)
+		if j != nil {
+			return j
+		}
+	}
+	return nil
+}
+
+type joinOnAppender interface {
+	AppendOn(app *condAppender)
+}
+
 func (q *Query) err(err error) *Query {
 	if q.stickyErr == nil {
 		q.stickyErr = err
@@ -235,11 +355,21 @@ func (q *Query) TableModel() TableModel {
 
 func (q *Query) isSoftDelete() bool {
 	if q.tableModel != nil {
-		return q.tableModel.Table().SoftDeleteField != nil && !q.hasFlag(allWithDeletedFlag)
+		return q.tableModel.Table().SoftDeleteField != nil && !q.allWithDeleted()
 	}
 	return false
 }
 
+// allWithDeleted reports whether soft deleted rows are not filtered out
+// either by AllWithDeleted or by the context. Deleted takes precedence
+// over the context.
+func (q *Query) allWithDeleted() bool {
+	if q.hasFlag(allWithDeletedFlag) {
+		return true
+	}
+	return !q.hasFlag(deletedFlag) && IsAllWithDeleted(q.ctx)
+}
+
 // Deleted adds `WHERE deleted_at IS NOT NULL` clause for soft deleted models.
 func (q *Query) Deleted() *Query {
 	if q.tableModel != nil {
@@ -251,6 +381,8 @@ func (q *Query) Deleted() *Query {
 }
 
 // AllWithDeleted changes query to return all rows including soft deleted ones.
+// It also applies to the relations selected by the query. Use WithAllDeleted
+// to include soft deleted rows in every query that uses the context.
 func (q *Query) AllWithDeleted() *Query {
 	if q.tableModel != nil {
 		if err := q.tableModel.Table().mustSoftDelete(); err != nil {
@@ -265,14 +397,33 @@ func (q *Query) With(name string, subq *Query) *Query {
 	return q._with(name, NewSelectQuery(subq))
 }
 
+// WithInsert adds subq as data-modifying common table expression
+// rendered as INSERT. Use Returning on subq to make the inserted rows
+// available to the main query.
 func (q *Query) WithInsert(name string, subq *Query) *Query {
 	return q._with(name, NewInsertQuery(subq))
 }
 
+// WithUpdate adds subq as data-modifying common table expression
+// rendered as UPDATE. Use Returning on subq to make the updated rows
+// available to the main query.
 func (q *Query) WithUpdate(name string, subq *Query) *Query {
 	return q._with(name, NewUpdateQuery(subq, false))
 }
 
+// WithDelete adds subq as data-modifying common table expression
+// rendered as DELETE. Use Returning on subq to make the deleted rows
+// available to the main query, for example to archive them:
+//
+//    deleted := db.Model((*Book)(nil)).
+//        Where("created_at < ?", cutoff).
+//        Returning("*")
+//    _, err := db.Model((*ArchivedBook)(nil)).
+//        WithDelete("deleted", deleted).
+//        Table("deleted").
+//        Insert()
+//    // WITH "deleted" AS (DELETE FROM "books" AS "book" WHERE (created_at < '...') RETURNING *)
+//    // INSERT INTO "archived_books" SELECT * FROM "deleted"
 func (q *Query) WithDelete(name string, subq *Query) *Query {
 	return q._with(name, NewDeleteQuery(subq))
 }
@@ -339,6 +490,23 @@ func (q *Query) Column(columns ...string) *Query {
 }
 
 // ColumnExpr adds column expression to the Query.
+// Expressions are scanned into struct fields by the column name or alias.
+// Models tagged with loose_columns match names ignoring case and
+// underscores, e.g. avgPrice matches the field AvgPrice:
+//
+//    var stats []struct {
+//    	tableName struct{} `pg:",loose_columns"`
+//
+//    	Category string
+//    	Count    int
+//    	AvgPrice float64
+//    }
+//    err := db.Model((*Product)(nil)).
+//    	Column("category").
+//    	ColumnExpr("count(*)").
+//    	ColumnExpr("avg(price) AS avgPrice").
+//    	Group("category").
+//    	Select(&stats)
 func (q *Query) ColumnExpr(expr string, params ...interface{}) *Query {
 	q.columns = append(q.columns, SafeQuery(expr, params...))
 	return q
@@ -379,6 +547,25 @@ func (q *Query) getDataFields() ([]*Field, error) {
 	return q._getFields(true)
 }
 
+// getUpdateFields returns the fields set by UpdateColumns or, if there are
+// none, the fields listed using Column.
+func (q *Query) getUpdateFields(omitPKs bool) ([]*Field, error) {
+	if len(q.updateFields) == 0 {
+		return q._getFields(omitPKs)
+	}
+	if !omitPKs {
+		return q.updateFields, nil
+	}
+
+	fields := make([]*Field, 0, len(q.updateFields))
+	for _, f := range q.updateFields {
+		if !f.hasFlag(PrimaryKeyFlag) {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
 func (q *Query) _getFields(omitPKs bool) ([]*Field, error) {
 	table := q.tableModel.Table()
 	columns := make([]*Field, 0, len(q.columns))
@@ -406,6 +593,17 @@ func (q *Query) _getFields(omitPKs bool) ([]*Field, error) {
 //   - RelationName to select all columns,
 //   - RelationName.column_name,
 //   - RelationName._ to join relation without selecting relation columns.
+//
+// For has-many relations Limit and Offset set by the apply function are
+// applied to the rows of every parent model rather than the whole result.
+//
+// Has-many relations can be loaded using a join condition instead of
+// foreign keys, e.g. to load events overlapping the calendar period:
+//
+//    Events []*Event `pg:"rel:has-many,join:event.during && parent.during"`
+//
+// The base table is joined as parent, so columns of the related table
+// must be qualified with its alias in the condition, Order and Where.
 func (q *Query) Relation(name string, apply ...func(*Query) (*Query, error)) *Query {
 	var fn func(*Query) (*Query, error)
 	if len(apply) == 1 {
@@ -426,7 +624,7 @@ func (q *Query) Relation(name string, apply ...func(*Query) (*Query, error)) *Qu
 
 	switch join.Rel.Type {
 	case HasOneRelation, BelongsToRelation:
-		q.joinAppendOn = join.AppendOn
+		q.joinAppendOn = join
 		return q.Apply(fn)
 	default:
 		q.joinAppendOn = nil
@@ -439,6 +637,139 @@ func (q *Query) Set(set string, params ...interface{}) *Query {
 	return q
 }
 
+// UpdateFrom adds the source to the FROM clause of UPDATE under the alias
+// so Set and Where can reference the source columns:
+//
+//    src := db.Model((*Author)(nil)).
+//    	Column("id").
+//    	ColumnExpr("name AS author_name").
+//    	Where("active")
+//    db.Model((*Book)(nil)).
+//    	UpdateFrom(src, "a").
+//    	SetFrom("a", "author_name").
+//    	Where("book.author_id = a.id").
+//    	Update()
+//
+// generates
+//
+//    UPDATE "books" AS "book" SET "author_name" = "a"."author_name"
+//    FROM (SELECT "id", name AS author_name FROM "authors" AS "author" WHERE (active)) AS "a"
+//    WHERE (book.author_id = a.id)
+//
+// Source can be a table name, a model, e.g. (*Author)(nil), or a *Query
+// which is used as a subquery with its parameters, or a *ValuesQuery.
+func (q *Query) UpdateFrom(source interface{}, alias string) *Query {
+	return q.sourceTable("UpdateFrom", source, alias)
+}
+
+// DeleteUsing adds the source to the USING clause of DELETE under the alias
+// so Where can reference the source columns:
+//
+//    db.Model((*Comment)(nil)).
+//    	DeleteUsing((*Post)(nil), "p").
+//    	Where("comment.post_id = p.id").
+//    	Where("p.deleted").
+//    	Delete()
+//
+// generates
+//
+//    DELETE FROM "comments" AS "comment" USING "posts" AS "p"
+//    WHERE (comment.post_id = p.id) AND (p.deleted)
+//
+// Source can be a table name, a model, e.g. (*Post)(nil), or a *Query
+// which is used as a subquery with its parameters, or a *ValuesQuery.
+func (q *Query) DeleteUsing(source interface{}, alias string) *Query {
+	return q.sourceTable("DeleteUsing", source, alias)
+}
+
+func (q *Query) sourceTable(method string, source interface{}, alias string) *Query {
+	expr, err := sourceTableExpr(method, source, alias)
+	if err != nil {
+		return q.err(err)
+	}
+	q.tables = append(q.tables, expr)
+	return q
+}
+
+func sourceTableExpr(method string, source interface{}, alias string) (*SafeQueryAppender, error) {
+	switch source := source.(type) {
+	case string:
+		return SafeQuery("? AS ?", types.Ident(source), types.Ident(alias)), nil
+	case *Query:
+		return SafeQuery("(?) AS ?", source, types.Ident(alias)), nil
+	case *ValuesQuery:
+		// Copy the query so the alias does not leak into the caller's query.
+		values := *source
+		return SafeQuery("?", values.As(alias)), nil
+	}
+
+	typ := reflect.TypeOf(source)
+	if typ != nil {
+		typ = indirectType(typ)
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("pg: %s does not support %T", method, source)
+	}
+	return SafeQuery("? AS ?", GetTable(typ).SQLName, types.Ident(alias)), nil
+}
+
+// MergeUsing sets the source and the join condition of the MERGE query
+// that is executed with Merge. Source can be a table name, a model,
+// a *Query, or a *ValuesQuery like in UpdateFrom. Actions are added with
+// WhenMatched and WhenNotMatched:
+//
+//    db.Model((*Book)(nil)).
+//    	MergeUsing(pg.Values(rows), "s", "book.id = s.id").
+//    	WhenMatched("AND s.deleted THEN DELETE").
+//    	WhenMatched("THEN UPDATE SET title = s.title").
+//    	WhenNotMatched("THEN INSERT (id, title) VALUES (s.id, s.title)").
+//    	Merge()
+//
+// generates
+//
+//    MERGE INTO "books" AS "book" USING (VALUES ...) AS "s" ("id", "title", "deleted")
+//    ON (book.id = s.id)
+//    WHEN MATCHED AND s.deleted THEN DELETE
+//    WHEN MATCHED THEN UPDATE SET title = s.title
+//    WHEN NOT MATCHED THEN INSERT (id, title) VALUES (s.id, s.title)
+func (q *Query) MergeUsing(
+	source interface{}, alias string, condition string, params ...interface{},
+) *Query {
+	expr, err := sourceTableExpr("MergeUsing", source, alias)
+	if err != nil {
+		return q.err(err)
+	}
+	q.mergeUsing = expr
+	q.mergeOn = SafeQuery(condition, params...)
+	return q
+}
+
+// WhenMatched adds the WHEN MATCHED clause to the MERGE query. The clause
+// is an optional AND condition followed by the action, e.g.
+// "THEN UPDATE SET title = s.title" or "AND s.deleted THEN DELETE".
+// Clauses are evaluated in the order they are added.
+func (q *Query) WhenMatched(s string, params ...interface{}) *Query {
+	q.mergeWhen = append(q.mergeWhen, SafeQuery("WHEN MATCHED "+s, params...))
+	return q
+}
+
+// WhenNotMatched adds the WHEN NOT MATCHED clause to the MERGE query,
+// e.g. "THEN INSERT (id, title) VALUES (s.id, s.title)" or "THEN DO NOTHING".
+func (q *Query) WhenNotMatched(s string, params ...interface{}) *Query {
+	q.mergeWhen = append(q.mergeWhen, SafeQuery("WHEN NOT MATCHED "+s, params...))
+	return q
+}
+
+// SetFrom sets the columns to the values of the same columns of the source
+// added with UpdateFrom, e.g. SetFrom("s", "title") generates
+// "title" = "s"."title".
+func (q *Query) SetFrom(alias string, columns ...string) *Query {
+	for _, column := range columns {
+		q.Set("? = ?.?", types.Ident(column), types.Ident(alias), types.Ident(column))
+	}
+	return q
+}
+
 // Value overwrites model value for the column in INSERT and UPDATE queries.
 func (q *Query) Value(column string, value string, params ...interface{}) *Query {
 	if !q.hasTableModel() {
@@ -570,11 +901,135 @@ func (q *Query) WhereIn(where string, slice interface{}) *Query {
 	return q.Where(where, types.In(slice))
 }
 
+// WhereNotIn adds `column NOT IN (values)` condition to the query.
+// Unlike `NOT IN (?)` with pg.In, an empty slice matches all rows.
+func (q *Query) WhereNotIn(column string, slice interface{}) *Query {
+	if v := reflect.ValueOf(slice); v.Kind() == reflect.Slice && v.Len() == 0 {
+		return q.Where("TRUE")
+	}
+	return q.Where("? NOT IN (?)", types.Ident(column), types.In(slice))
+}
+
 // WhereInMulti is a shortcut for Where and pg.InMulti.
 func (q *Query) WhereInMulti(where string, values ...interface{}) *Query {
 	return q.Where(where, types.InMulti(values...))
 }
 
+// WhereExists adds `EXISTS (subq)` condition to the query. The subquery is
+// formatted using the parent query, so it can reference parent columns:
+//
+//    subq := db.Model((*Comment)(nil)).
+//    	ColumnExpr("1").
+//    	Where("comment.book_id = book.id")
+//    q.WhereExists(subq)
+func (q *Query) WhereExists(subq *Query) *Query {
+	return q.Where("EXISTS (?)", subq)
+}
+
+// WhereNotExists adds `NOT EXISTS (subq)` condition to the query.
+func (q *Query) WhereNotExists(subq *Query) *Query {
+	return q.Where("NOT EXISTS (?)", subq)
+}
+
+// WhereRelationCount adds a condition on the number of related rows using
+// a correlated subquery built from the relation join keys, e.g.
+//
+//    db.Model(&posts).WhereRelationCount("Comments", ">", 3)
+//
+// generates
+//
+//    WHERE ((SELECT count(*) FROM "comments" AS "post__comments"
+//    WHERE "post__comments"."post_id" = "post"."id") > 3)
+//
+// Soft deleted related rows are not counted unless the query includes them
+// with AllWithDeleted or its context is created with WithAllDeleted. Both
+// must be applied before WhereRelationCount is called.
+// Supported operators are =, <>, !=, <, <=, > and >=.
+func (q *Query) WhereRelationCount(name, op string, count int) *Query {
+	if q.tableModel == nil {
+		return q.err(errModelNil)
+	}
+
+	switch op {
+	case "=", "<>", "!=", "<", "<=", ">", ">=":
+	default:
+		return q.err(fmt.Errorf("pg: WhereRelationCount(unsupported operator %q)", op))
+	}
+
+	table := q.tableModel.Table()
+	rel, ok := table.Relations[name]
+	if !ok {
+		return q.err(fmt.Errorf("%s does not have relation=%q", table, name))
+	}
+	if rel.JoinOn != "" {
+		return q.err(fmt.Errorf(
+			"pg: WhereRelationCount does not support relation=%q with join condition", name))
+	}
+
+	return q.Where("? "+op+" ?", &relationCountQuery{
+		base:           table,
+		rel:            rel,
+		allWithDeleted: q.allWithDeleted(),
+	}, count)
+}
+
+// WhereDistinctFrom adds `column IS DISTINCT FROM value` condition to the
+// query. Unlike `<>` it treats NULL as a comparable value, e.g. NULL is
+// distinct from 1 but not from NULL.
+func (q *Query) WhereDistinctFrom(column string, value interface{}) *Query {
+	return q.Where("? IS DISTINCT FROM ?", types.Ident(column), value)
+}
+
+// WhereNotDistinctFrom adds `column IS NOT DISTINCT FROM value` condition to
+// the query. Unlike `=` it is true when both sides are NULL.
+func (q *Query) WhereNotDistinctFrom(column string, value interface{}) *Query {
+	return q.Where("? IS NOT DISTINCT FROM ?", types.Ident(column), value)
+}
+
+// WhereInetContainedBy adds `column << network` condition to the query,
+// i.e. the address in the column is strictly contained within the network.
+func (q *Query) WhereInetContainedBy(column string, network interface{}) *Query {
+	return q.Where("? << ?", types.Ident(column), network)
+}
+
+// WhereInetContainedByOrEqual adds `column <<= network` condition to the query.
+func (q *Query) WhereInetContainedByOrEqual(column string, network interface{}) *Query {
+	return q.Where("? <<= ?", types.Ident(column), network)
+}
+
+// WhereInetContains adds `column >> addr` condition to the query,
+// i.e. the network in the column strictly contains the address.
+func (q *Query) WhereInetContains(column string, addr interface{}) *Query {
+	return q.Where("? >> ?", types.Ident(column), addr)
+}
+
+// WhereInetContainsOrEqual adds `column >>= addr` condition to the query.
+func (q *Query) WhereInetContainsOrEqual(column string, addr interface{}) *Query {
+	return q.Where("? >>= ?", types.Ident(column), addr)
+}
+
+// WhereHstoreHasKey adds `column ? key` condition to the query, i.e. the
+// hstore in the column contains the key. It requires the hstore extension:
+//
+//    CREATE EXTENSION IF NOT EXISTS hstore
+func (q *Query) WhereHstoreHasKey(column, key string) *Query {
+	return q.Where("? ? ?", types.Ident(column), types.Safe("?"), key)
+}
+
+// WhereHstoreContains adds `column @> hstore` condition to the query,
+// i.e. the hstore in the column contains all pairs from the map.
+// The map is passed as is if it is already wrapped with pg.Hstore.
+func (q *Query) WhereHstoreContains(column string, m interface{}) *Query {
+	return q.Where("? @> ?", types.Ident(column), hstoreValue(m))
+}
+
+func hstoreValue(m interface{}) interface{} {
+	if _, ok := m.(types.ValueAppender); ok {
+		return m
+	}
+	return types.NewHstore(m)
+}
+
 func (q *Query) addWhere(f queryWithSepAppender) {
 	if q.onConflictDoUpdate() {
 		q.updWhere = append(q.updWhere, f)
@@ -617,7 +1072,7 @@ func (q *Query) Join(join string, params ...interface{}) *Query {
 		join: SafeQuery(join, params...),
 	}
 	q.joins = append(q.joins, j)
-	q.joinAppendOn = j.AppendOn
+	q.joinAppendOn = j
 	return q
 }
 
@@ -627,7 +1082,7 @@ func (q *Query) JoinOn(condition string, params ...interface{}) *Query {
 		q.err(errors.New("pg: no joins to apply JoinOn"))
 		return q
 	}
-	q.joinAppendOn(&condAppender{
+	q.joinAppendOn.AppendOn(&condAppender{
 		sep:    " AND ",
 		cond:   condition,
 		params: params,
@@ -640,7 +1095,7 @@ func (q *Query) JoinOnOr(condition string, params ...interface{}) *Query {
 		q.err(errors.New("pg: no joins to apply JoinOn"))
 		return q
 	}
-	q.joinAppendOn(&condAppender{
+	q.joinAppendOn.AppendOn(&condAppender{
 		sep:    " OR ",
 		cond:   condition,
 		params: params,
@@ -660,11 +1115,73 @@ func (q *Query) GroupExpr(group string, params ...interface{}) *Query {
 	return q
 }
 
+// GroupByRollup adds `ROLLUP (column1, column2, ...)` to the GROUP BY clause.
+// Column names are quoted according to PostgreSQL rules.
+func (q *Query) GroupByRollup(columns ...string) *Query {
+	q.group = append(q.group, &groupingAppender{
+		name: "ROLLUP",
+		sets: singleColumnSets(columns),
+	})
+	return q
+}
+
+// GroupByCube adds `CUBE (column1, column2, ...)` to the GROUP BY clause.
+// Column names are quoted according to PostgreSQL rules.
+func (q *Query) GroupByCube(columns ...string) *Query {
+	q.group = append(q.group, &groupingAppender{
+		name: "CUBE",
+		sets: singleColumnSets(columns),
+	})
+	return q
+}
+
+// GroupingSets adds `GROUPING SETS (...)` to the GROUP BY clause. Every set
+// is a list of column names and an empty set stands for the grand total:
+//
+//    q.GroupingSets([]string{"brand"}, []string{"size"}, nil)
+//
+// generates
+//
+//    GROUP BY GROUPING SETS ("brand", "size", ())
+func (q *Query) GroupingSets(sets ...[]string) *Query {
+	q.group = append(q.group, &groupingAppender{
+		name: "GROUPING SETS",
+		sets: sets,
+	})
+	return q
+}
+
+// Having adds the condition to the HAVING clause of the query. Params are
+// bound to the condition itself, so they don't depend on params of columns,
+// WHERE, or other clauses. Several conditions are joined with AND:
+//
+//    q.Group("author_id").
+//    	Having("count(*) > ?", 1).
+//    	Having("max(price) < ?", 100)
+//
+// generates
+//
+//    GROUP BY "author_id" HAVING (count(*) > 1) AND (max(price) < 100)
 func (q *Query) Having(having string, params ...interface{}) *Query {
 	q.having = append(q.having, SafeQuery(having, params...))
 	return q
 }
 
+// Window adds a named window to the WINDOW clause of the query:
+//
+//    q.ColumnExpr("row_number() OVER w").Window("w", "PARTITION BY a ORDER BY b")
+//
+// generates
+//
+//    SELECT row_number() OVER w ... WINDOW "w" AS (PARTITION BY a ORDER BY b)
+func (q *Query) Window(name string, window string, params ...interface{}) *Query {
+	q.windows = append(q.windows, &namedWindow{
+		name: name,
+		expr: SafeQuery(window, params...),
+	})
+	return q
+}
+
 func (q *Query) Union(other *Query) *Query {
 	return q.addUnion(" UNION ", other)
 }
@@ -700,21 +1217,13 @@ func (q *Query) addUnion(expr string, other *Query) *Query {
 // Order adds sort order to the Query quoting column name. Does not expand params like ?TableAlias etc.
 // OrderExpr can be used to bypass quoting restriction or for params expansion.
 func (q *Query) Order(orders ...string) *Query {
-loop:
 	for _, order := range orders {
 		if order == "" {
 			continue
 		}
-		ind := strings.Index(order, " ")
-		if ind != -1 {
-			field := order[:ind]
-			sort := order[ind+1:]
-			switch internal.UpperString(sort) {
-			case "ASC", "DESC", "ASC NULLS FIRST", "DESC NULLS FIRST",
-				"ASC NULLS LAST", "DESC NULLS LAST":
-				q = q.OrderExpr("? ?", types.Ident(field), types.Safe(sort))
-				continue loop
-			}
+		if field, sort, ok := splitOrder(order); ok {
+			q = q.OrderExpr("? ?", types.Ident(field), types.Safe(sort))
+			continue
 		}
 
 		q.order = append(q.order, fieldAppender{order})
@@ -722,7 +1231,76 @@ loop:
 	return q
 }
 
-// Order adds sort order to the Query.
+// OrderCollate adds sort order to the Query using the collation, e.g.
+//
+//    q.OrderCollate("name DESC", "de-DE-x-icu")
+//
+// generates
+//
+//    ORDER BY "name" COLLATE "de-DE-x-icu" DESC
+//
+// Order has the same format as in Order. Collation must be a plain collation
+// name that consists of letters, digits, and "_", "-", ".", "@", "=" characters.
+func (q *Query) OrderCollate(order, collation string) *Query {
+	if !isCollation(collation) {
+		q.err(fmt.Errorf("pg: invalid collation=%q", collation))
+		return q
+	}
+
+	b := make([]byte, 0, len(collation)+2)
+	b = append(b, '"')
+	b = append(b, collation...)
+	b = append(b, '"')
+	quoted := types.Safe(internal.BytesToString(b))
+
+	if field, sort, ok := splitOrder(order); ok {
+		return q.OrderExpr("? COLLATE ? ?", types.Ident(field), quoted, types.Safe(sort))
+	}
+	return q.OrderExpr("? COLLATE ?", types.Ident(order), quoted)
+}
+
+// splitOrder splits order like "name DESC NULLS LAST" into the field
+// and the sort direction.
+func splitOrder(order string) (field, sort string, ok bool) {
+	ind := strings.Index(order, " ")
+	if ind == -1 {
+		return "", "", false
+	}
+
+	field = order[:ind]
+	sort = order[ind+1:]
+	switch internal.UpperString(sort) {
+	case "ASC", "DESC", "ASC NULLS FIRST", "DESC NULLS FIRST",
+		"ASC NULLS LAST", "DESC NULLS LAST":
+		return field, sort, true
+	}
+	return "", "", false
+}
+
+// isCollation reports whether s can be used as a quoted collation name.
+func isCollation(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			strings.IndexByte("_-.@=", c) != -1) {
+			return false
+		}
+	}
+	return true
+}
+
+// OrderExpr adds sort order expression to the Query. Params are bound the
+// same way as in Where, so slices are encoded as JSON; wrap them with
+// pg.Array to pass PostgreSQL arrays to functions like array_position:
+//
+//    q.OrderExpr("array_position(?, status)", pg.Array([]string{"active", "pending"}))
+//
+// generates
+//
+//    ORDER BY array_position('{"active","pending"}', status)
 func (q *Query) OrderExpr(order string, params ...interface{}) *Query {
 	if order != "" {
 		q.order = append(q.order, SafeQuery(order, params...))
@@ -732,6 +1310,19 @@ func (q *Query) OrderExpr(order string, params ...interface{}) *Query {
 
 func (q *Query) Limit(n int) *Query {
 	q.limit = n
+	q.withTies = false
+	return q
+}
+
+// LimitWithTies limits the result to n rows plus any rows that tie with
+// the last one according to the ORDER BY clause, i.e.
+//
+//    ORDER BY score DESC FETCH FIRST n ROWS WITH TIES
+//
+// The query must have an ORDER BY clause. Requires PostgreSQL 13 or later.
+func (q *Query) LimitWithTies(n int) *Query {
+	q.limit = n
+	q.withTies = true
 	return q
 }
 
@@ -740,11 +1331,217 @@ func (q *Query) Offset(n int) *Query {
 	return q
 }
 
+// LimitOffsetParams renders LIMIT and OFFSET as the bind parameters
+// $limitParam and $offsetParam instead of the values set by Limit and
+// Offset. The query text then does not depend on the values, so it can be
+// prepared once and executed with different pages without a new statement
+// and plan for every page:
+//
+//    q := db.Model((*Book)(nil)).Order("id").LimitOffsetParams(1, 2)
+//    query, err := q.AppendPreparedQuery(db.Formatter(), nil)
+//    stmt, err := db.Prepare(string(query))
+//    _, err = stmt.Query(&books, limit, offset)
+//
+// generates
+//
+//    SELECT ... FROM "books" AS "book" ORDER BY "id" LIMIT $1 OFFSET $2
+//
+// Zero keeps the literal value. The query must be executed using a prepared
+// statement that binds the parameters, so other ways to run the query,
+// e.g. Select, Exists, or using it as a subquery, return an error.
+// Parameterization is opt-in, because
+// PostgreSQL can choose a better plan for a literal limit, e.g. a small
+// LIMIT can make an index scan on the ORDER BY columns cheaper.
+//
+// Queries of has-many relations apply LIMIT and OFFSET to the rows of every
+// parent, so they return an error when LimitOffsetParams is used.
+func (q *Query) LimitOffsetParams(limitParam, offsetParam int) *Query {
+	if limitParam < 0 || offsetParam < 0 {
+		return q.err(fmt.Errorf(
+			"pg: invalid LimitOffsetParams(%d, %d)", limitParam, offsetParam))
+	}
+	q.limitParam = limitParam
+	q.offsetParam = offsetParam
+	return q
+}
+
+// ChunkSize makes Select and ForEach read rows using a server-side cursor
+// that fetches n rows at a time instead of receiving the whole result at once.
+//
+// Smaller chunks reduce the memory used to buffer rows, but every chunk costs
+// an extra round trip to the server. Rows are still accumulated in slice
+// models, so ChunkSize is most useful with ForEach.
+//
+// Cursors only exist inside a transaction, so the query must be run using
+// pg.Tx. Models that select a single row, e.g. a struct, ignore ChunkSize.
+func (q *Query) ChunkSize(n int) *Query {
+	q.chunkSize = n
+	return q
+}
+
+// MaxRows makes Select fail with pg.ErrTooManyRows when the query returns
+// more than n rows, e.g. to guard against loading a whole table into
+// memory. Rows past the limit are not scanned into the model. It
+// overrides Options.MaxRows and zero disables the limit.
+func (q *Query) MaxRows(n int) *Query {
+	q.maxRows = n
+	return q
+}
+
+// UsePrimary makes Select, Count and other read queries run on the primary
+// instead of a read replica, e.g. to read rows that were just written.
+// It has no effect when the DB has no replicas.
+func (q *Query) UsePrimary() *Query {
+	return q.withFlag(usePrimaryFlag)
+}
+
+// Comment prepends an SQL comment to the generated query, e.g.
+//
+//    db.Model(&orders).Comment("checkout-finalize").Where("id = ?", id).Update()
+//
+// generates
+//
+//    /* checkout-finalize */ UPDATE "orders" AS "order" ...
+//
+// The comment is visible in pg_stat_activity.query and in the server logs,
+// which helps to find the code that issued a query. Comment delimiters in
+// the text are broken up so the comment can't end early.
+func (q *Query) Comment(comment string) *Query {
+	comment = strings.Replace(comment, "/*", "/ *", -1)
+	comment = strings.Replace(comment, "*/", "* /", -1)
+	q.comment = comment
+	return q
+}
+
+func (q *Query) appendComment(b []byte) []byte {
+	if q.comment == "" {
+		return b
+	}
+	b = append(b, "/* "...)
+	b = append(b, q.comment...)
+	b = append(b, " */ "...)
+	return b
+}
+
+// InsertColumns sets the list and the order of columns used by Insert:
+//
+//    db.Model(book).InsertColumns("title", "author_id").Insert()
+//
+// generates
+//
+//    INSERT INTO "books" ("title", "author_id") VALUES (...)
+//
+// Model fields that are not listed are omitted from the query, so they get
+// the column DEFAULT value. An error is returned if the model does not have
+// the column.
+func (q *Query) InsertColumns(columns ...string) *Query {
+	if q.tableModel == nil {
+		return q.err(errModelNil)
+	}
+
+	table := q.tableModel.Table()
+	for _, column := range columns {
+		field, err := table.GetField(column)
+		if err != nil {
+			return q.err(err)
+		}
+		q.insertFields = append(q.insertFields, field)
+	}
+	return q
+}
+
+// UpdateColumns restricts Update and UpdateNotZero to the columns,
+// e.g. to the fields present in a partial update request:
+//
+//    db.Model(user).UpdateColumns(columns...).WherePK().Update()
+//
+// generates
+//
+//    UPDATE "users" AS "user" SET "name" = ..., "email" = ... WHERE ...
+//
+// Unlike Column the columns are validated when the query is built: an error
+// is returned if the model does not have a column or no columns are given,
+// so an empty list never updates all the columns. Columns listed using
+// Column are ignored. auto_now fields are still updated.
+func (q *Query) UpdateColumns(columns ...string) *Query {
+	if q.tableModel == nil {
+		return q.err(errModelNil)
+	}
+	if len(columns) == 0 {
+		return q.err(errors.New("pg: UpdateColumns requires at least one column"))
+	}
+
+	table := q.tableModel.Table()
+	for _, column := range columns {
+		field, err := table.GetField(column)
+		if err != nil {
+			return q.err(err)
+		}
+		if !fieldsContain(q.updateFields, field) {
+			q.updateFields = append(q.updateFields, field)
+		}
+	}
+	return q
+}
+
+// OverridingSystemValue adds OVERRIDING SYSTEM VALUE to Insert, so values
+// of GENERATED ALWAYS AS IDENTITY columns are inserted instead of being
+// generated, e.g. to keep the ids of rows copied during a migration.
+// Zero ids are still inserted as DEFAULT. Sequences of identity columns are
+// not advanced, so reset them afterwards, e.g. using
+// setval(pg_get_serial_sequence('books', 'id'), max(id)).
+func (q *Query) OverridingSystemValue() *Query {
+	return q.withFlag(overridingSystemValueFlag)
+}
+
 func (q *Query) OnConflict(s string, params ...interface{}) *Query {
 	q.onConflict = SafeQuery(s, params...)
 	return q
 }
 
+// OnConflictOnConstraint sets the named constraint as the conflict target
+// of the INSERT query. Conflict action is specified using OnConflict, which
+// must not contain another target, and defaults to DO NOTHING:
+//
+//    q.OnConflictOnConstraint("books_pkey").OnConflict("DO UPDATE")
+//
+// generates
+//
+//    ON CONFLICT ON CONSTRAINT "books_pkey" DO UPDATE
+func (q *Query) OnConflictOnConstraint(name string) *Query {
+	q.onConflictConstraint = name
+	return q
+}
+
+// OnConflictColumns sets the columns as the conflict target of the INSERT
+// query. Together with OnConflictWhere it allows to infer partial unique
+// indexes. Conflict action is specified using OnConflict and defaults
+// to DO NOTHING:
+//
+//    q.OnConflictColumns("email").
+//    	OnConflictWhere("deleted_at IS NULL").
+//    	OnConflict("DO UPDATE")
+//
+// generates
+//
+//    ON CONFLICT ("email") WHERE (deleted_at IS NULL) DO UPDATE
+func (q *Query) OnConflictColumns(columns ...string) *Query {
+	q.onConflictColumns = append(q.onConflictColumns, columns...)
+	return q
+}
+
+// OnConflictWhere sets the index predicate of the conflict target set with
+// OnConflictColumns. Params are bound the same way as in Where.
+func (q *Query) OnConflictWhere(predicate string, params ...interface{}) *Query {
+	q.onConflictWhere = SafeQuery(predicate, params...)
+	return q
+}
+
+func (q *Query) hasOnConflict() bool {
+	return q.onConflict != nil || q.onConflictConstraint != "" ||
+		len(q.onConflictColumns) > 0
+}
+
 func (q *Query) onConflictDoUpdate() bool {
 	return q.onConflict != nil &&
 		strings.HasSuffix(internal.UpperString(q.onConflict.query), "DO UPDATE")
@@ -759,11 +1556,165 @@ func (q *Query) Returning(s string, params ...interface{}) *Query {
 	return q
 }
 
+// ReturningAction makes Insert report whether every returned row was
+// inserted or updated by ON CONFLICT DO UPDATE. Actions is a pointer to
+// []UpsertAction or []bool (true means inserted) and is filled in the order
+// of the returned rows, which for bulk inserts is the order of the slice:
+//
+//    var actions []orm.UpsertAction
+//    _, err := db.Model(&books).
+//    	OnConflict("(id) DO UPDATE").
+//    	Set("title = EXCLUDED.title").
+//    	ReturningAction(&actions).
+//    	Insert()
+//
+// The action is derived from the system column xmax, i.e. RETURNING
+// (xmax = 0), which is zero only for rows created by the statement.
+// This relies on PostgreSQL MVCC internals rather than a documented
+// guarantee. Rows skipped by DO NOTHING or by the WHERE of DO UPDATE are
+// not returned, so actions are shorter than the slice and can't be matched
+// to the slice by position; return the primary key to match them.
+func (q *Query) ReturningAction(actions interface{}) *Query {
+	if err := checkUpsertActions(actions); err != nil {
+		return q.err(err)
+	}
+	q.upsertActions = actions
+	return q
+}
+
+type tableSample struct {
+	method  string
+	percent float64
+	seed    *int64
+}
+
+// TableSample selects a random sample of the first table rows using
+// the sampling method, e.g.
+//
+//    q.TableSample("SYSTEM", 10)
+//
+// generates
+//
+//    FROM "books" AS "book" TABLESAMPLE SYSTEM (10)
+//
+// Built-in methods SYSTEM and BERNOULLI expect a percentage between 0 and
+// 100. Use Repeatable to get the same sample in subsequent queries.
+func (q *Query) TableSample(method string, percent float64) *Query {
+	method = internal.UpperString(method)
+	if !isSQLIdent(method) {
+		q.err(fmt.Errorf("pg: invalid TABLESAMPLE method=%q", method))
+		return q
+	}
+	switch method {
+	case "SYSTEM", "BERNOULLI":
+		if !(percent >= 0 && percent <= 100) {
+			q.err(fmt.Errorf("pg: TABLESAMPLE %s percentage must be between 0 and 100, got %v",
+				method, percent))
+			return q
+		}
+	default:
+		if !(percent >= 0) {
+			q.err(fmt.Errorf("pg: TABLESAMPLE %s argument must not be negative, got %v",
+				method, percent))
+			return q
+		}
+	}
+
+	ts := &tableSample{
+		method:  method,
+		percent: percent,
+	}
+	if q.tableSample != nil {
+		ts.seed = q.tableSample.seed
+	}
+	q.tableSample = ts
+	return q
+}
+
+// Repeatable adds REPEATABLE (seed) to the TABLESAMPLE clause so the same
+// seed selects the same sample as long as the table does not change.
+func (q *Query) Repeatable(seed int64) *Query {
+	if q.tableSample == nil {
+		q.err(errors.New("pg: Repeatable requires TableSample"))
+		return q
+	}
+	ts := *q.tableSample
+	ts.seed = &seed
+	q.tableSample = &ts
+	return q
+}
+
+func (ts *tableSample) appendTableSample(b []byte) []byte {
+	b = append(b, " TABLESAMPLE "...)
+	b = append(b, ts.method...)
+	b = append(b, " ("...)
+	b = strconv.AppendFloat(b, ts.percent, 'f', -1, 64)
+	b = append(b, ')')
+	if ts.seed != nil {
+		b = append(b, " REPEATABLE ("...)
+		b = strconv.AppendInt(b, *ts.seed, 10)
+		b = append(b, ')')
+	}
+	return b
+}
+
+// isSQLIdent reports whether s is a plain unquoted SQL identifier.
+func isSQLIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// For adds a locking clause to the query. The clause starts with one of
+// the lock strengths UPDATE, NO KEY UPDATE, SHARE, or KEY SHARE optionally
+// followed by OF and a list of tables, e.g.
+//
+//    q.For("NO KEY UPDATE OF ?TableAlias")
+//
+// Use SkipLocked or NoWait to control how the query waits for locked rows.
 func (q *Query) For(s string, params ...interface{}) *Query {
 	q.selFor = SafeQuery(s, params...)
 	return q
 }
 
+// ForOf is like For, but locks only rows of the tables with the given
+// names or aliases, e.g. joined relations. It is required to lock queries
+// with has-one relations, because PostgreSQL can't lock the nullable side
+// of LEFT JOIN. Names are quoted:
+//
+//    q.Relation("Author").ForOf("UPDATE", "book")
+//    // FOR UPDATE OF "book"
+func (q *Query) ForOf(strength string, tables ...string) *Query {
+	if len(tables) == 0 {
+		return q.For(strength)
+	}
+	b := append([]byte(strength), " OF "...)
+	b = appendIdents(b, tables)
+	q.selFor = SafeQuery(internal.BytesToString(b))
+	return q
+}
+
+// SkipLocked adds SKIP LOCKED to the locking clause set with For so rows
+// that can't be locked immediately are skipped.
+func (q *Query) SkipLocked() *Query {
+	q.selForWait = "SKIP LOCKED"
+	return q
+}
+
+// NoWait adds NOWAIT to the locking clause set with For so the query fails
+// instead of waiting for rows locked by other transactions.
+func (q *Query) NoWait() *Query {
+	q.selForWait = "NOWAIT"
+	return q
+}
+
 // Apply calls the fn passing the Query as an argument.
 func (q *Query) Apply(fn func(*Query) (*Query, error)) *Query {
 	qq, err := fn(q)
@@ -786,6 +1737,42 @@ func (q *Query) Count() (int, error) {
 	return count, err
 }
 
+// SelectScalar selects a single value produced by the query columns,
+// for example an aggregate, and scans it into the value:
+//
+//    var total int
+//    err := db.Model((*Order)(nil)).ColumnExpr("sum(amount)").SelectScalar(&total)
+//
+// Model columns are never selected so ColumnExpr must be used to specify
+// the value. Model hooks are not called.
+func (q *Query) SelectScalar(value interface{}) error {
+	if q.stickyErr != nil {
+		return q.stickyErr
+	}
+	if len(q.columns) == 0 {
+		return errors.New("pg: SelectScalar requires a column (try ColumnExpr)")
+	}
+
+	_, err := q.db.QueryOneContext(q.ctx, Scan(value), NewSelectQuery(q), q.tableModel)
+	return err
+}
+
+// SelectInt is like SelectScalar, but returns the value as an int.
+// NULL is returned as 0.
+func (q *Query) SelectInt() (int, error) {
+	var n int
+	err := q.SelectScalar(&n)
+	return n, err
+}
+
+// SelectFloat is like SelectScalar, but returns the value as a float64.
+// NULL is returned as 0.
+func (q *Query) SelectFloat() (float64, error) {
+	var n float64
+	err := q.SelectScalar(&n)
+	return n, err
+}
+
 func (q *Query) countSelectQuery(column string) *SelectQuery {
 	return &SelectQuery{
 		q:     q,
@@ -835,25 +1822,236 @@ func (q *Query) Select(values ...interface{}) error {
 	if err != nil {
 		return err
 	}
+	if q.maxRows > 0 {
+		model = newMaxRowsModel(model, q.maxRows)
+	}
+
+	var res Result
+	if _, ok := model.(useQueryOne); !ok && q.chunkSize > 0 {
+		res, err = q.selectChunks(model)
+	} else {
+		res, err = q.query(q.ctx, model, NewSelectQuery(q))
+	}
+	if err != nil {
+		return err
+	}
+
+	if res.RowsReturned() > 0 {
+		if q.tableModel != nil {
+			if err := q.selectJoins(q.tableModel.GetJoins()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := model.AfterSelect(q.ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SelectLenient is like Select, but rows that can't be scanned are skipped
+// instead of failing the whole query. Errors for such rows are returned
+// as a slice of RowError along with the successfully scanned rows.
+// It only supports struct and slice of structs models and does not return
+// pg.ErrNoRows.
+func (q *Query) SelectLenient() ([]RowError, error) {
+	if q.stickyErr != nil {
+		return nil, q.stickyErr
+	}
+	if q.tableModel == nil {
+		return nil, errModelNil
+	}
+
+	model := newLenientModel(q.tableModel)
+	res, err := q.db.QueryContext(q.ctx, model, NewSelectQuery(q), q.tableModel)
+	if err != nil {
+		return model.errs, err
+	}
+
+	if res.RowsReturned() > len(model.errs) {
+		if err := q.selectJoins(q.tableModel.GetJoins()); err != nil {
+			return model.errs, err
+		}
+	}
+
+	if err := model.AfterSelect(q.ctx); err != nil {
+		return model.errs, err
+	}
+
+	return model.errs, nil
+}
+
+// SelectByIDsOrdered selects rows by the primary key values in ids and
+// stores them in the model in the order of ids, which is useful to
+// implement dataloaders. The model must be a slice of struct pointers that
+// gets a row for every id and nil for missing rows:
+//
+//    var books []*Book
+//    err := db.Model(&books).
+//    	Relation("Author").
+//    	SelectByIDsOrdered([]int{3, 1, 2})
+//
+// The model must have a single primary key.
+func (q *Query) SelectByIDsOrdered(ids interface{}) error {
+	if q.tableModel != nil && q.tableModel.Kind() == reflect.Slice &&
+		q.tableModel.Value().Type().Elem().Kind() != reflect.Ptr {
+		return fmt.Errorf("pg: SelectByIDsOrdered(unsupported %s, wanted a slice of pointers)",
+			q.tableModel.Value().Type())
+	}
+
+	rows, byID, err := q.selectByIDs("SelectByIDsOrdered", ids)
+	if err != nil {
+		return err
+	}
+
+	idsv := reflect.ValueOf(ids)
+	slice := reflect.MakeSlice(rows.Type(), idsv.Len(), idsv.Len())
+	for i := 0; i < idsv.Len(); i++ {
+		if row, ok := byID[idKey(idsv.Index(i))]; ok {
+			slice.Index(i).Set(row)
+		}
+	}
+	rows.Set(slice)
+
+	return nil
+}
+
+// SelectByIDsMap is like SelectByIDsOrdered, but stores the found rows in
+// the map keyed by the primary key, e.g. *map[int]*Book. The model slice
+// gets the found rows in no particular order.
+func (q *Query) SelectByIDsMap(ids, dest interface{}) error {
+	mv := reflect.ValueOf(dest)
+	if mv.Kind() != reflect.Ptr || mv.IsNil() || mv.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("pg: SelectByIDsMap(unsupported %T, wanted a pointer to a map)", dest)
+	}
+	mv = mv.Elem()
+
+	rows, byID, err := q.selectByIDs("SelectByIDsMap", ids)
+	if err != nil {
+		return err
+	}
+	if mv.Type().Elem() != rows.Type().Elem() {
+		return fmt.Errorf("pg: SelectByIDsMap(unsupported %T, wanted values of type %s)",
+			dest, rows.Type().Elem())
+	}
+
+	keyType := mv.Type().Key()
+	m := reflect.MakeMap(mv.Type())
+	idsv := reflect.ValueOf(ids)
+	for i := 0; i < idsv.Len(); i++ {
+		id := indirect(idsv.Index(i))
+		row, ok := byID[idKey(id)]
+		if !ok {
+			continue
+		}
+		if !id.Type().ConvertibleTo(keyType) {
+			return fmt.Errorf("pg: SelectByIDsMap can't use %s as %s map key", id.Type(), keyType)
+		}
+		m.SetMapIndex(id.Convert(keyType), row)
+	}
+	mv.Set(m)
+
+	return nil
+}
+
+// selectByIDs selects rows by the primary key values into the model slice
+// and returns the slice with its elements keyed by idKey.
+func (q *Query) selectByIDs(
+	method string, ids interface{},
+) (reflect.Value, map[string]reflect.Value, error) {
+	if q.stickyErr != nil {
+		return reflect.Value{}, nil, q.stickyErr
+	}
+	if q.tableModel == nil {
+		return reflect.Value{}, nil, errModelNil
+	}
+	if q.tableModel.Kind() != reflect.Slice {
+		return reflect.Value{}, nil, fmt.Errorf("pg: %s requires a slice model, got %s",
+			method, q.tableModel.Value().Type())
+	}
+
+	table := q.tableModel.Table()
+	if len(table.PKs) != 1 {
+		return reflect.Value{}, nil, fmt.Errorf(
+			"pg: %s requires a single primary key, %s has %d", method, table, len(table.PKs))
+	}
+	pk := table.PKs[0]
+
+	idsv := reflect.ValueOf(ids)
+	if idsv.Kind() != reflect.Slice {
+		return reflect.Value{}, nil, fmt.Errorf("pg: %s(ids %T is not a slice)", method, ids)
+	}
+
+	rows := q.tableModel.Value()
+	if idsv.Len() > 0 {
+		err := q.Clone().
+			Where("?.? IN (?)", table.Alias, pk.Column, types.In(ids)).
+			Select()
+		if err != nil {
+			return reflect.Value{}, nil, err
+		}
+	} else {
+		rows.Set(rows.Slice(0, 0))
+	}
+
+	byID := make(map[string]reflect.Value, rows.Len())
+	for i := 0; i < rows.Len(); i++ {
+		row := rows.Index(i)
+		byID[idKey(pk.Value(indirect(row)))] = row
+	}
+	return rows, byID, nil
+}
+
+// idKey returns the primary key value formatted as SQL so ids of
+// different Go types, e.g. int and int64, match.
+func idKey(v reflect.Value) string {
+	v = indirect(v)
+	if !v.IsValid() {
+		return "NULL"
+	}
+	return string(types.Append(nil, v.Interface(), 1))
+}
+
+var cursorSeq uint64
+
+// selectChunks declares a cursor for the select query and fetches rows
+// in chunks of q.chunkSize rows until the cursor is exhausted.
+func (q *Query) selectChunks(model Model) (Result, error) {
+	cursor := fmt.Sprintf("pg_cursor_%d", atomic.AddUint64(&cursorSeq, 1))
+
+	_, err := q.db.ExecContext(q.ctx, "DECLARE ? NO SCROLL CURSOR FOR ?",
+		types.Ident(cursor), NewSelectQuery(q))
+	if err != nil {
+		return nil, err
+	}
 
-	res, err := q.query(q.ctx, model, NewSelectQuery(q))
-	if err != nil {
-		return err
+	model = &chunkModel{Model: model}
+	res := &batchResult{
+		model: model,
 	}
+	for {
+		chunk, err := q.db.QueryContext(q.ctx, model, "FETCH FORWARD ? FROM ?",
+			q.chunkSize, types.Ident(cursor))
+		if err != nil {
+			return nil, err
+		}
 
-	if res.RowsReturned() > 0 {
-		if q.tableModel != nil {
-			if err := q.selectJoins(q.tableModel.GetJoins()); err != nil {
-				return err
-			}
+		res.affected += chunk.RowsAffected()
+		res.returned += chunk.RowsReturned()
+
+		if chunk.RowsReturned() < q.chunkSize {
+			break
 		}
 	}
 
-	if err := model.AfterSelect(q.ctx); err != nil {
-		return err
+	_, err = q.db.ExecContext(q.ctx, "CLOSE ?", types.Ident(cursor))
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return res, nil
 }
 
 func (q *Query) newModel(values []interface{}) (Model, error) {
@@ -863,6 +2061,18 @@ func (q *Query) newModel(values []interface{}) (Model, error) {
 	return q.tableModel, nil
 }
 
+// newReturningModel is like newModel, but scans the RETURNING columns of
+// all affected rows into separate slices when every value is a slice ptr.
+// It is only used by Delete, including soft deletes.
+func (q *Query) newReturningModel(values []interface{}) (Model, error) {
+	if len(values) > 1 && q.hasReturning() {
+		if model, ok := newSlicesModel(values); ok {
+			return model, nil
+		}
+	}
+	return q.newModel(values)
+}
+
 func (q *Query) query(ctx context.Context, model Model, query interface{}) (Result, error) {
 	if _, ok := model.(useQueryOne); ok {
 		return q.db.QueryOneContext(ctx, model, query, q.tableModel)
@@ -959,7 +2169,8 @@ func (q *Query) SelectAndCountEstimate(threshold int, values ...interface{}) (co
 }
 
 // ForEach calls the function for each row returned by the query
-// without loading all rows into the memory.
+// without loading all rows into the memory. See ChunkSize to also limit
+// the number of rows the server sends at once.
 //
 // Function can accept a struct, a pointer to a struct, an orm.Model,
 // or values for the columns in a row. Function must return an error.
@@ -968,6 +2179,18 @@ func (q *Query) ForEach(fn interface{}) error {
 	return q.Select(m)
 }
 
+// SelectColumns selects rows and returns them column by column. Every
+// column is a slice with one value per row, e.g. []int64 for bigint and
+// []string for text columns. See types.ColumnValueType for the mapping.
+// NULLs are returned as zero values. Columns must have distinct names.
+func (q *Query) SelectColumns() (map[string]interface{}, error) {
+	m := newColumnsModel()
+	if err := q.Select(m); err != nil {
+		return nil, err
+	}
+	return m.result(), nil
+}
+
 func (q *Query) forEachHasOneJoin(fn func(*join) error) error {
 	if q.tableModel == nil {
 		return nil
@@ -1010,7 +2233,20 @@ func (q *Query) selectJoins(joins []join) error {
 	return nil
 }
 
+// maxInsertValues is the maximum number of values (rows * columns) sent in
+// a single INSERT statement. Values are inlined in the query text, so the
+// limit keeps statements with large slices to a reasonable size instead of
+// building one huge query in memory.
+const maxInsertValues = 65535
+
 // Insert inserts the model.
+//
+// Bulk inserts of slices with more than 65535 values (rows * columns) are
+// split into several INSERT statements that run one after another. Values
+// returned by the database are scanned into the slice elements as usual and
+// RowsAffected is the sum for all statements. The statements are atomic only
+// when the query runs in a transaction. Use DisableInsertSplit to always send
+// a single statement.
 func (q *Query) Insert(values ...interface{}) (Result, error) {
 	if q.stickyErr != nil {
 		return nil, q.stickyErr
@@ -1021,7 +2257,17 @@ func (q *Query) Insert(values ...interface{}) (Result, error) {
 		return nil, err
 	}
 
-	ctx := q.ctx
+	ctx, err := callGlobalHooks(q.ctx, q, BeforeInsertOp)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.setAutoNow(true); err != nil {
+		return nil, err
+	}
+	if err := q.setUUIDs(); err != nil {
+		return nil, err
+	}
 
 	if q.tableModel != nil && q.tableModel.Table().hasFlag(beforeInsertHookFlag) {
 		ctx, err = q.tableModel.BeforeInsert(ctx)
@@ -1030,8 +2276,19 @@ func (q *Query) Insert(values ...interface{}) (Result, error) {
 		}
 	}
 
-	query := NewInsertQuery(q)
-	res, err := q.returningQuery(ctx, model, query)
+	if q.upsertActions != nil {
+		resetUpsertActions(q.upsertActions)
+	}
+
+	var res Result
+	if batchSize := q.insertBatchSize(values); batchSize > 0 {
+		res, err = q.insertInBatches(ctx, batchSize)
+	} else {
+		if q.upsertActions != nil && model != nil {
+			model = newUpsertActionModel(model, q.upsertActions)
+		}
+		res, err = q.returningQuery(ctx, model, NewInsertQuery(q))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1042,6 +2299,133 @@ func (q *Query) Insert(values ...interface{}) (Result, error) {
 		}
 	}
 
+	if _, err := callGlobalHooks(ctx, q, AfterInsertOp); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LastInsertId returns the primary key of the row inserted by Insert
+// using the result returned by it:
+//
+//    q := db.Model(book)
+//    res, err := q.Insert()
+//    id, err := q.LastInsertId(res)
+//
+// It is only available for inserts of a single struct model with one
+// integer primary key, e.g. a serial id. Zero primary keys are inserted as
+// DEFAULT and returned using RETURNING, so this works without adding
+// RETURNING manually. Otherwise ErrNoLastInsertId is returned.
+func (q *Query) LastInsertId(res Result) (int64, error) {
+	if id, ok := q.lastInsertID(res); ok {
+		return id, nil
+	}
+	return 0, internal.ErrNoLastInsertId
+}
+
+func (q *Query) lastInsertID(res Result) (int64, bool) {
+	if res == nil {
+		return 0, false
+	}
+	if q.tableModel == nil || q.tableModel.Kind() != reflect.Struct || res.RowsAffected() != 1 {
+		return 0, false
+	}
+
+	pks := q.tableModel.Table().PKs
+	if len(pks) != 1 {
+		return 0, false
+	}
+
+	strct := q.tableModel.Value()
+	if !strct.IsValid() || pks[0].HasZeroValue(strct) {
+		return 0, false
+	}
+
+	fv := reflect.Indirect(pks[0].Value(strct))
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(fv.Uint()), true
+	}
+	return 0, false
+}
+
+// DisableInsertSplit disables splitting of large bulk inserts into several
+// statements, i.e. Insert always sends a single INSERT statement.
+func (q *Query) DisableInsertSplit() *Query {
+	return q.withFlag(noInsertSplitFlag)
+}
+
+// insertBatchSize returns the number of rows per INSERT statement or 0 if
+// the insert does not need to be split.
+func (q *Query) insertBatchSize(values []interface{}) int {
+	if len(values) > 0 || q.hasFlag(noInsertSplitFlag) || q.hasMultiTables() {
+		return 0
+	}
+
+	m, ok := q.tableModel.(*sliceTableModel)
+	if !ok {
+		return 0
+	}
+
+	numFields := len(q.insertFields)
+	if numFields == 0 {
+		fields, err := q.getFields()
+		if err != nil {
+			return 0
+		}
+		numFields = len(fields)
+	}
+	if numFields == 0 {
+		numFields = len(m.table.Fields)
+	}
+	numFields += len(q.extraValues)
+	if numFields == 0 {
+		return 0
+	}
+
+	if m.slice.Len()*numFields <= maxInsertValues {
+		return 0
+	}
+	if batchSize := maxInsertValues / numFields; batchSize > 0 {
+		return batchSize
+	}
+	return 1
+}
+
+// insertInBatches inserts the slice model using several INSERT statements.
+// Returned rows are scanned into the original slice elements.
+func (q *Query) insertInBatches(c context.Context, batchSize int) (Result, error) {
+	slice := q.tableModel.Value()
+	res := &batchResult{
+		model: q.tableModel,
+	}
+
+	for i := 0; i < slice.Len(); i += batchSize {
+		j := i + batchSize
+		if j > slice.Len() {
+			j = slice.Len()
+		}
+
+		batch := reflect.New(slice.Type())
+		batch.Elem().Set(slice.Slice(i, j))
+
+		batchq := q.Clone().Model(batch.Interface())
+		var model Model = batchq.tableModel
+		if q.upsertActions != nil {
+			model = newUpsertActionModel(model, q.upsertActions)
+		}
+		batchres, err := batchq.returningQuery(c, model, NewInsertQuery(batchq))
+		if err != nil {
+			return nil, err
+		}
+
+		res.affected += batchres.RowsAffected()
+		res.returned += batchres.RowsReturned()
+	}
+
 	return res, nil
 }
 
@@ -1106,7 +2490,74 @@ func (q *Query) SelectOrInsert(values ...interface{}) (inserted bool, _ error) {
 	return false, err
 }
 
+// Save inserts the model when its primary keys have zero values and
+// updates it by primary keys otherwise. Slices are split into a batch of
+// rows to insert and a batch of rows to update. Models with composite
+// primary keys must have either all or none of the primary keys set.
+func (q *Query) Save() error {
+	if q.stickyErr != nil {
+		return q.stickyErr
+	}
+	if !q.hasTableModel() {
+		return errModelNil
+	}
+
+	table := q.tableModel.Table()
+	if err := table.checkPKs(); err != nil {
+		return err
+	}
+
+	if q.tableModel.Kind() == reflect.Struct {
+		isNew, err := table.hasZeroPKs(q.tableModel.Value())
+		if err != nil {
+			return err
+		}
+		if isNew {
+			_, err = q.Insert()
+		} else {
+			_, err = q.Clone().WherePK().Update()
+		}
+		return err
+	}
+
+	slice := q.tableModel.Value()
+	sliceType := reflect.SliceOf(reflect.PtrTo(table.Type))
+	inserts := reflect.New(sliceType)
+	updates := reflect.New(sliceType)
+
+	sliceLen := slice.Len()
+	for i := 0; i < sliceLen; i++ {
+		strct := indirect(slice.Index(i))
+		isNew, err := table.hasZeroPKs(strct)
+		if err != nil {
+			return err
+		}
+		if isNew {
+			inserts.Elem().Set(reflect.Append(inserts.Elem(), strct.Addr()))
+		} else {
+			updates.Elem().Set(reflect.Append(updates.Elem(), strct.Addr()))
+		}
+	}
+
+	if inserts.Elem().Len() > 0 {
+		if _, err := q.Clone().Model(inserts.Interface()).Insert(); err != nil {
+			return err
+		}
+	}
+	if updates.Elem().Len() > 0 {
+		if _, err := q.Clone().Model(updates.Interface()).Update(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Update updates the model.
+//
+// For a slice model with Returning the returned rows are scanned into the
+// elements with the same primary key, so the primary key must be returned.
+// Elements that were not updated are left unchanged and are not counted
+// by Result.RowsAffected.
 func (q *Query) Update(scan ...interface{}) (Result, error) {
 	return q.update(scan, false)
 }
@@ -1128,12 +2579,30 @@ func (q *Query) update(values []interface{}, omitZero bool) (Result, error) {
 		return nil, q.stickyErr
 	}
 
-	model, err := q.newModel(values)
+	var model Model
+	var err error
+	if q.hasFlag(softDeleteFlag) {
+		model, err = q.newReturningModel(values)
+	} else {
+		model, err = q.newModel(values)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if len(values) == 0 && q.isSliceModelWithData() && q.hasReturning() {
+		model = newBulkUpdateModel(q.tableModel.(*sliceTableModel))
+	}
+
+	if len(q.set) == 0 {
+		if err := q.setAutoNow(false); err != nil {
+			return nil, err
+		}
+	}
 
-	c := q.ctx
+	c, err := callGlobalHooks(q.ctx, q, BeforeUpdateOp)
+	if err != nil {
+		return nil, err
+	}
 
 	if q.tableModel != nil {
 		c, err = q.tableModel.BeforeUpdate(c)
@@ -1142,12 +2611,29 @@ func (q *Query) update(values []interface{}, omitZero bool) (Result, error) {
 		}
 	}
 
+	versionField := q.versionField()
+	var version reflect.Value
+	if versionField != nil {
+		// Copy the current version, because the query can return a new one.
+		version = reflect.ValueOf(versionField.Value(q.tableModel.Value()).Interface())
+	}
+
 	query := NewUpdateQuery(q, omitZero)
 	res, err := q.returningQuery(c, model, query)
 	if err != nil {
+		if err == internal.ErrNoRows && versionField != nil {
+			return nil, internal.ErrStaleObject
+		}
 		return nil, err
 	}
 
+	if versionField != nil {
+		if res.RowsAffected() == 0 {
+			return nil, internal.ErrStaleObject
+		}
+		setNextVersion(versionField.Value(q.tableModel.Value()), version)
+	}
+
 	if q.tableModel != nil {
 		err = q.tableModel.AfterUpdate(c)
 		if err != nil {
@@ -1155,9 +2641,96 @@ func (q *Query) update(values []interface{}, omitZero bool) (Result, error) {
 		}
 	}
 
+	if _, err := callGlobalHooks(c, q, AfterUpdateOp); err != nil {
+		return nil, err
+	}
+
 	return res, nil
 }
 
+// versionField returns the field tagged with version when the query updates
+// the struct model using the model values.
+func (q *Query) versionField() *Field {
+	if len(q.set) > 0 || q.tableModel == nil {
+		return nil
+	}
+	table := q.tableModel.Table()
+	if table.VersionField == nil || q.tableModel.Kind() != reflect.Struct ||
+		!q.tableModel.Value().IsValid() {
+		return nil
+	}
+	return table.VersionField
+}
+
+// setAutoNow sets auto_now fields and, on insert, zero auto_now_add fields
+// of the model to the current time. Like other time.Time values the time is
+// sent in UTC, so timestamptz columns get the same instant regardless of
+// the client time zone.
+func (q *Query) setAutoNow(insert bool) error {
+	if q.tableModel == nil {
+		return nil
+	}
+
+	table := q.tableModel.Table()
+	if len(table.AutoNowFields) == 0 && (!insert || len(table.AutoNowAddFields) == 0) {
+		return nil
+	}
+
+	v := q.tableModel.Value()
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return table.setAutoNow(v, insert)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			strct := indirect(v.Index(i))
+			if !strct.IsValid() {
+				continue
+			}
+			if err := table.setAutoNow(strct, insert); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setUUIDs generates random UUIDs for zero uuid fields tagged with generate.
+func (q *Query) setUUIDs() error {
+	if q.tableModel == nil {
+		return nil
+	}
+
+	table := q.tableModel.Table()
+	if len(table.UUIDFields) == 0 {
+		return nil
+	}
+
+	v := q.tableModel.Value()
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return table.setUUIDs(v)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			strct := indirect(v.Index(i))
+			if !strct.IsValid() {
+				continue
+			}
+			if err := table.setUUIDs(strct); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (q *Query) returningQuery(c context.Context, model Model, query interface{}) (Result, error) {
 	if !q.hasReturning() {
 		return q.db.QueryContext(c, model, query, q.tableModel)
@@ -1170,6 +2743,17 @@ func (q *Query) returningQuery(c context.Context, model Model, query interface{}
 
 // Delete deletes the model. When model has deleted_at column the row
 // is soft deleted instead.
+//
+// With Returning the returned columns are scanned into the model or
+// into values. Several slice values receive one column each, e.g.
+// to audit a bulk delete:
+//
+//    var ids []int
+//    var titles []string
+//    _, err := db.Model((*Book)(nil)).
+//    	Where("author_id = ?", authorID).
+//    	Returning("id, title").
+//    	Delete(&ids, &titles)
 func (q *Query) Delete(values ...interface{}) (Result, error) {
 	if q.tableModel == nil {
 		return q.ForceDelete(values...)
@@ -1180,7 +2764,7 @@ func (q *Query) Delete(values ...interface{}) (Result, error) {
 		return q.ForceDelete(values...)
 	}
 
-	clone := q.Clone()
+	clone := q.Clone().withFlag(softDeleteFlag)
 	if q.tableModel.IsNil() {
 		if table.SoftDeleteField.SQLType == pgTypeBigint {
 			clone = clone.Set("? = ?", table.SoftDeleteField.Column, time.Now().UnixNano())
@@ -1206,12 +2790,15 @@ func (q *Query) ForceDelete(values ...interface{}) (Result, error) {
 	}
 	q = q.withFlag(deletedFlag)
 
-	model, err := q.newModel(values)
+	model, err := q.newReturningModel(values)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := q.ctx
+	ctx, err := callGlobalHooks(q.ctx, q, BeforeDeleteOp)
+	if err != nil {
+		return nil, err
+	}
 
 	if q.tableModel != nil {
 		ctx, err = q.tableModel.BeforeDelete(ctx)
@@ -1231,6 +2818,10 @@ func (q *Query) ForceDelete(values ...interface{}) (Result, error) {
 		}
 	}
 
+	if _, err := callGlobalHooks(ctx, q, AfterDeleteOp); err != nil {
+		return nil, err
+	}
+
 	return res, nil
 }
 
@@ -1244,6 +2835,68 @@ func (q *Query) DropTable(opt *DropTableOptions) error {
 	return err
 }
 
+// AddColumnsIfMissing adds the columns of the model that are missing in the
+// existing table using ALTER TABLE ... ADD COLUMN IF NOT EXISTS and returns
+// the names of the added columns. Existing columns are read from
+// information_schema. It is meant for simple additive migrations: columns
+// are never dropped, and existing columns are not altered even when their
+// type or constraints differ from the model. Table constraints, e.g.
+// primary and foreign keys, are not added, and NOT NULL columns without
+// a default can only be added to empty tables.
+func (q *Query) AddColumnsIfMissing() ([]string, error) {
+	if q.stickyErr != nil {
+		return nil, q.stickyErr
+	}
+	if q.tableModel == nil {
+		return nil, errModelNil
+	}
+
+	table := q.tableModel.Table()
+
+	var existing []string
+	_, err := q.db.QueryContext(q.ctx, &existing, tableColumnsQuery, string(table.SQLName))
+	if err != nil {
+		return nil, err
+	}
+
+	columns := missingColumns(table, existing)
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	_, err = q.db.ExecContext(q.ctx, NewAddColumnsQuery(q, columns))
+	if err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// SelectInto creates a temporary table with the rows returned by the query
+// using CREATE TEMP TABLE ... AS SELECT. The query runs on the query DB,
+// i.e. in the transaction when the query is created with Tx.Model.
+// RowsAffected returns the number of rows in the created table.
+func (q *Query) SelectInto(table string, opt *SelectIntoOptions) (Result, error) {
+	return q.db.ExecContext(q.ctx, NewSelectIntoQuery(q, table, opt))
+}
+
+// Merge executes the MERGE query built with MergeUsing, WhenMatched,
+// and WhenNotMatched. MERGE is available since PostgreSQL 15; the syntax
+// error returned by older servers is wrapped in an error saying so.
+func (q *Query) Merge() (Result, error) {
+	merge := NewMergeQuery(q)
+	res, err := q.db.ExecContext(q.ctx, merge)
+	if pgErr, ok := err.(internal.PGError); ok && merge.isMergeSyntaxError(q.db.Formatter(), pgErr) {
+		return nil, mergeUnsupportedError{err: err}
+	}
+	return res, err
+}
+
+// Truncate truncates the model table and tables added with Table.
+func (q *Query) Truncate(opt *TruncateOptions) error {
+	_, err := q.db.ExecContext(q.ctx, NewTruncateQuery(q, opt))
+	return err
+}
+
 func (q *Query) CreateComposite(opt *CreateCompositeOptions) error {
 	_, err := q.db.ExecContext(q.ctx, NewCreateCompositeQuery(q, opt))
 	return err
@@ -1296,6 +2949,15 @@ func (q *Query) AppendQuery(fmter QueryFormatter, b []byte) ([]byte, error) {
 	return NewSelectQuery(q).AppendQuery(fmter, b)
 }
 
+// AppendPreparedQuery is like AppendQuery, but also renders the bind
+// parameters set with LimitOffsetParams. The query is meant to be passed
+// to DB.Prepare.
+func (q *Query) AppendPreparedQuery(fmter QueryFormatter, b []byte) ([]byte, error) {
+	sel := NewSelectQuery(q)
+	sel.prepared = true
+	return sel.AppendQuery(fmter, b)
+}
+
 // Exists returns true or false depending if there are any rows matching the query.
 func (q *Query) Exists() (bool, error) {
 	q = q.Clone() // copy to not change original query
@@ -1535,7 +3197,7 @@ func (q *Query) appendWith(fmter QueryFormatter, b []byte) (_ []byte, err error)
 		b = types.AppendIdent(b, with.name, 1)
 		b = append(b, " AS ("...)
 
-		b, err = with.query.AppendQuery(fmter, b)
+		b, err = with.query.AppendQuery(withFormatter(fmter, with.query), b)
 		if err != nil {
 			return nil, err
 		}
@@ -1546,6 +3208,24 @@ func (q *Query) appendWith(fmter QueryFormatter, b []byte) (_ []byte, err error)
 	return b, nil
 }
 
+// withFormatter returns formatter that resolves model placeholders,
+// e.g. ?TableAlias, against the table model of the CTE query instead of
+// the main query.
+func withFormatter(fmter QueryFormatter, query QueryAppender) QueryFormatter {
+	f, ok := fmter.(*Formatter)
+	if !ok {
+		return fmter
+	}
+	cmd, ok := query.(QueryCommand)
+	if !ok {
+		return fmter
+	}
+	if model := cmd.Query().tableModel; model != nil {
+		return f.WithTableModel(model)
+	}
+	return fmter
+}
+
 func (q *Query) isSliceModelWithData() bool {
 	if !q.hasTableModel() {
 		return false
@@ -1623,6 +3303,24 @@ func (q wherePKSliceQuery) AppendQuery(fmter QueryFormatter, b []byte) ([]byte,
 	return b, nil
 }
 
+// wherePKSliceInQuery compares the primary keys with the keys of the
+// slice elements using IN. It replaces wherePKSliceQuery in DELETE
+// queries, which don't support the join with the VALUES list.
+type wherePKSliceInQuery struct {
+	q *Query
+}
+
+var _ queryWithSepAppender = (*wherePKSliceInQuery)(nil)
+
+func (wherePKSliceInQuery) AppendSep(b []byte) []byte {
+	return append(b, " AND "...)
+}
+
+func (q wherePKSliceInQuery) AppendQuery(fmter QueryFormatter, b []byte) ([]byte, error) {
+	table := q.q.tableModel.Table()
+	return appendColumnAndSliceValue(fmter, b, q.q.tableModel.Value(), table.Alias, table.PKs), nil
+}
+
 type joinPKSliceQuery struct {
 	q *Query
 }
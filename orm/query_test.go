@@ -3,6 +3,10 @@ package orm
 import (
 	"testing"
 
+	"github.com/go-pg/pg/v10/internal"
+	"github.com/go-pg/pg/v10/internal/pool"
+	"github.com/go-pg/pg/v10/types"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -49,3 +53,241 @@ var _ = Describe("NewQuery", func() {
 		Expect(string(b)).To(Equal(`SELECT "model"."id" FROM "models" AS "model"`))
 	})
 })
+
+var _ = Describe("Query.Clone", func() {
+	It("does not share conditions and columns", func() {
+		base := NewQuery(nil, &SelectModel{}).
+			Column("id").
+			Where("id > ?", 1)
+
+		q1 := base.Clone().Column("name").Where("name = ?", "foo").Order("id")
+		q2 := base.Clone().Where("name = ?", "bar").Limit(10)
+
+		Expect(selectQueryString(base)).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" WHERE (id > 1)`))
+		Expect(selectQueryString(q1)).To(Equal(`SELECT "id", "name" FROM "select_models" AS "select_model" WHERE (id > 1) AND (name = 'foo') ORDER BY "id"`))
+		Expect(selectQueryString(q2)).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" WHERE (id > 1) AND (name = 'bar') LIMIT 10`))
+	})
+
+	It("does not share join conditions", func() {
+		base := NewQuery(nil, &SelectModel{}).
+			Column("id").
+			Join("JOIN t ON t.id = select_model.id")
+
+		q := base.Clone().JoinOn("t.flag IS TRUE")
+		base.JoinOn("t.other IS TRUE")
+
+		Expect(selectQueryString(base)).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" JOIN t ON t.id = select_model.id ON (t.other IS TRUE)`))
+		Expect(selectQueryString(q)).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" JOIN t ON t.id = select_model.id ON (t.flag IS TRUE)`))
+	})
+
+	It("does not share relations", func() {
+		base := NewQuery(nil, &SelectModel{}).Relation("HasOne")
+
+		q := base.Clone().Relation("HasOne", func(q *Query) (*Query, error) {
+			return q.JoinOn("has_one.id > ?", 10), nil
+		})
+
+		Expect(selectQueryString(base)).To(Equal(`SELECT "select_model"."id", "select_model"."name", "select_model"."has_one_id", "has_one"."id" AS "has_one__id" FROM "select_models" AS "select_model" LEFT JOIN "has_one_models" AS "has_one" ON "has_one"."id" = "select_model"."has_one_id"`))
+		Expect(selectQueryString(q)).To(Equal(`SELECT "select_model"."id", "select_model"."name", "select_model"."has_one_id", "has_one"."id" AS "has_one__id" FROM "select_models" AS "select_model" LEFT JOIN "has_one_models" AS "has_one" ON "has_one"."id" = "select_model"."has_one_id" AND (has_one.id > 10)`))
+	})
+})
+
+var _ = Describe("Query.Comment", func() {
+	It("prepends the comment", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			Comment("checkout-finalize").
+			Column("id").
+			Where("id = ?", 1)
+
+		Expect(selectQueryString(q)).To(Equal(`/* checkout-finalize */ SELECT "id" FROM "select_models" AS "select_model" WHERE (id = 1)`))
+
+		s := queryString(NewDeleteQuery(q))
+		Expect(s).To(Equal(`/* checkout-finalize */ DELETE FROM "select_models" AS "select_model" WHERE (id = 1)`))
+	})
+
+	It("prepends the comment before WITH and the count wrapper", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			Comment("report").
+			With("ids", NewQuery(nil).TableExpr("t")).
+			Column("name").
+			Group("name")
+
+		sel := NewSelectQuery(q)
+		sel.count = "count(*)"
+		Expect(queryString(sel)).To(HavePrefix(`/* report */ WITH "_count_wrapper" AS (WITH "ids" AS (SELECT * FROM t) SELECT`))
+	})
+
+	It("escapes comment delimiters", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			Comment("a */ DROP TABLE users; /* b").
+			Column("id")
+
+		Expect(selectQueryString(q)).To(Equal(`/* a * / DROP TABLE users; / * b */ SELECT "id" FROM "select_models" AS "select_model"`))
+
+		q = q.Comment("/*/")
+		Expect(selectQueryString(q)).To(Equal(`/* / * / */ SELECT "id" FROM "select_models" AS "select_model"`))
+	})
+
+	It("is copied by Clone", func() {
+		q := NewQuery(nil, &SelectModel{}).Comment("label").Column("id")
+		clone := q.Clone()
+		q.Comment("")
+
+		Expect(selectQueryString(q)).To(Equal(`SELECT "id" FROM "select_models" AS "select_model"`))
+		Expect(selectQueryString(clone)).To(Equal(`/* label */ SELECT "id" FROM "select_models" AS "select_model"`))
+	})
+})
+
+var _ = Describe("Ident and Safe", func() {
+	const evil = `name" FROM users; DROP TABLE users; --`
+	const quoted = `"name"" FROM users; DROP TABLE users; --"`
+
+	It("quotes column names", func() {
+		q := NewQuery(nil, &SelectModel{}).Column(evil)
+		Expect(selectQueryString(q)).To(Equal(`SELECT ` + quoted + ` FROM "select_models" AS "select_model"`))
+
+		q = NewQuery(nil, &SelectModel{}).ColumnExpr("? AS ?", types.Ident("t.id"), types.Ident(evil))
+		Expect(selectQueryString(q)).To(Equal(`SELECT "t"."id" AS ` + quoted + ` FROM "select_models" AS "select_model"`))
+	})
+
+	It("quotes table names", func() {
+		q := NewQuery(nil).Table(evil)
+		Expect(selectQueryString(q)).To(Equal(`SELECT * FROM ` + quoted))
+
+		q = NewQuery(nil).TableExpr("? AS ?", types.Ident("users"), types.Ident(evil))
+		Expect(selectQueryString(q)).To(Equal(`SELECT * FROM "users" AS ` + quoted))
+	})
+
+	It("quotes identifiers in conditions, ordering and grouping", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			Column("id").
+			Where("? = ?", types.Ident(evil), evil).
+			Group(evil).
+			Order(evil)
+		Expect(selectQueryString(q)).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" WHERE (` + quoted + ` = 'name" FROM users; DROP TABLE users; --') GROUP BY ` + quoted + ` ORDER BY ` + quoted))
+
+		s := updateQueryString(NewQuery(nil, &SelectModel{}).Set("? = ?", types.Ident(evil), 1).Where("id = 1"))
+		Expect(s).To(Equal(`UPDATE "select_models" AS "select_model" SET ` + quoted + ` = 1 WHERE (id = 1)`))
+	})
+
+	It("only treats a whole * segment as a wildcard", func() {
+		q := NewQuery(nil).TableExpr("t").ColumnExpr("?, ?", types.Ident("t.*"), types.Ident("*"+evil))
+		Expect(selectQueryString(q)).To(Equal(`SELECT "t".*, "*name"" FROM users; DROP TABLE users; --" FROM t`))
+	})
+
+	It("appends Safe verbatim", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			ColumnExpr("?", types.Safe("count(*)")).
+			Where("? IN (?)", types.Ident("id"), types.Safe("1, 2"))
+		Expect(selectQueryString(q)).To(Equal(`SELECT count(*) FROM "select_models" AS "select_model" WHERE ("id" IN (1, 2))`))
+	})
+})
+
+var _ = Describe("Query.MaxRows", func() {
+	It("stops scanning past the limit", func() {
+		var ids []int
+		model, err := newScanModel([]interface{}{&ids})
+		Expect(err).NotTo(HaveOccurred())
+
+		m := newMaxRowsModel(model, 2)
+		Expect(m.Init()).To(Succeed())
+
+		var errs []error
+		for _, id := range []string{"1", "2", "3", "4"} {
+			s := m.NextColumnScanner()
+			b := []byte(id)
+			err := s.ScanColumn(types.ColumnInfo{Name: "id"}, pool.NewBytesReader(b), len(b))
+			Expect(err).NotTo(HaveOccurred())
+			errs = append(errs, m.AddColumnScanner(s))
+		}
+
+		Expect(ids).To(Equal([]int{1, 2}))
+		Expect(errs[:2]).To(Equal([]error{nil, nil}))
+		Expect(errs[2]).To(Equal(internal.ErrTooManyRows))
+		Expect(errs[3]).To(Equal(internal.ErrTooManyRows))
+	})
+
+	It("keeps QueryOne for struct models", func() {
+		m := newMaxRowsModel(NewQuery(nil, &SelectModel{}).tableModel, 1)
+		_, ok := m.(useQueryOne)
+		Expect(ok).To(BeTrue())
+	})
+
+	It("is copied by Clone", func() {
+		q := NewQuery(nil, &SelectModel{}).MaxRows(10)
+		Expect(q.Clone().maxRows).To(Equal(10))
+	})
+})
+
+var _ = Describe("scanning computed columns", func() {
+	type Stat struct {
+		tableName struct{} `pg:",loose_columns"`
+
+		Category string
+		Count    int
+		AvgPrice float64
+	}
+
+	scan := func(dest interface{}, columns []string, values ...string) error {
+		model, err := newScanModel([]interface{}{dest})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Init()).To(Succeed())
+
+		s := model.NextColumnScanner()
+		for i, column := range columns {
+			b := []byte(values[i])
+			err := s.ScanColumn(types.ColumnInfo{Name: column}, pool.NewBytesReader(b), len(b))
+			if err != nil {
+				return err
+			}
+		}
+		return model.AddColumnScanner(s)
+	}
+
+	It("maps aliases to fields ignoring case and underscores", func() {
+		for _, columns := range [][]string{
+			{"category", "count", "avg_price"},
+			{"category", "count", "avgprice"},
+			{"Category", "COUNT", "AvgPrice"},
+		} {
+			var stats []Stat
+			err := scan(&stats, columns, "books", "3", "9.5")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(Equal([]Stat{{Category: "books", Count: 3, AvgPrice: 9.5}}))
+		}
+	})
+
+	It("does not map ambiguous aliases", func() {
+		type Ambiguous struct {
+			tableName struct{} `pg:",loose_columns"`
+
+			AB  int
+			A_B int
+		}
+
+		var rows []Ambiguous
+		err := scan(&rows, []string{"ab"}, "1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rows[0].AB).To(Equal(1))
+
+		err = scan(&rows, []string{"Ab"}, "1")
+		Expect(err).To(MatchError(ContainSubstring("can't find column=Ab")))
+	})
+
+	It("requires loose_columns", func() {
+		type Strict struct {
+			AvgPrice float64
+		}
+
+		var rows []Strict
+		err := scan(&rows, []string{"avgPrice"}, "9.5")
+		Expect(err).To(MatchError(ContainSubstring("can't find column=avgPrice")))
+	})
+
+	It("does not map columns prefixed with underscore", func() {
+		var stats []Stat
+		err := scan(&stats, []string{"_count"}, "1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats[0].Count).To(Equal(0))
+	})
+})
@@ -22,6 +22,10 @@ type Relation struct {
 	JoinFKs     []*Field
 	Polymorphic *Field
 
+	// JoinOn is the join condition of has-many relation defined with
+	// the join tag option. It replaces the equality of foreign keys.
+	JoinOn string
+
 	M2MTableName  types.Safe
 	M2MTableAlias types.Safe
 	M2MBaseFKs    []string
@@ -31,3 +35,80 @@ type Relation struct {
 func (r *Relation) String() string {
 	return fmt.Sprintf("relation=%s", r.Field.GoName)
 }
+
+// relationCountQuery is a correlated subquery that counts the rows
+// of the relation for the current row of the base table.
+type relationCountQuery struct {
+	base           *Table
+	rel            *Relation
+	allWithDeleted bool
+}
+
+var _ QueryAppender = (*relationCountQuery)(nil)
+
+func (q *relationCountQuery) AppendQuery(fmter QueryFormatter, b []byte) ([]byte, error) {
+	base := q.base
+	alias := types.AppendIdent(nil, base.ModelName+"__"+q.rel.Field.SQLName, 1)
+
+	b = append(b, "(SELECT count(*) FROM "...)
+
+	if q.rel.Type == Many2ManyRelation {
+		b = fmter.FormatQuery(b, string(q.rel.M2MTableName))
+		b = append(b, " AS "...)
+		b = append(b, alias...)
+		b = append(b, " WHERE "...)
+		for i, col := range q.rel.M2MBaseFKs {
+			if i > 0 {
+				b = append(b, " AND "...)
+			}
+			b = append(b, alias...)
+			b = append(b, '.')
+			b = types.AppendIdent(b, col, 1)
+			b = append(b, " = "...)
+			b = append(b, base.Alias...)
+			b = append(b, '.')
+			b = append(b, base.PKs[i].Column...)
+		}
+		return append(b, ')'), nil
+	}
+
+	joinTable := q.rel.JoinTable
+	b = fmter.FormatQuery(b, string(joinTable.SQLNameForSelects))
+	b = append(b, " AS "...)
+	b = append(b, alias...)
+	b = append(b, " WHERE "...)
+	for i, baseFK := range q.rel.BaseFKs {
+		if i > 0 {
+			b = append(b, " AND "...)
+		}
+		b = append(b, alias...)
+		b = append(b, '.')
+		b = append(b, q.rel.JoinFKs[i].Column...)
+		b = append(b, " = "...)
+		b = append(b, base.Alias...)
+		b = append(b, '.')
+		b = append(b, baseFK.Column...)
+	}
+
+	if q.rel.Polymorphic != nil {
+		b = append(b, " AND "...)
+		b = append(b, alias...)
+		b = append(b, '.')
+		b = append(b, q.rel.Polymorphic.Column...)
+		b = append(b, " IN ("...)
+		b = types.AppendString(b, base.ModelName, 1)
+		b = append(b, ", "...)
+		b = types.AppendString(b, base.TypeName, 1)
+		b = append(b, ')')
+	}
+
+	if joinTable.SoftDeleteField != nil && !q.allWithDeleted {
+		b = append(b, " AND "...)
+		b = append(b, alias...)
+		b = append(b, '.')
+		b = append(b, joinTable.SoftDeleteField.Column...)
+		b = append(b, " IS NULL"...)
+	}
+
+	return append(b, ')'), nil
+}
@@ -12,3 +12,24 @@ type Result interface {
 	// RowsReturned returns the number of rows returned by the query.
 	RowsReturned() int
 }
+
+// batchResult aggregates results of several statements.
+type batchResult struct {
+	model    Model
+	affected int
+	returned int
+}
+
+var _ Result = (*batchResult)(nil)
+
+func (res *batchResult) Model() Model {
+	return res.model
+}
+
+func (res *batchResult) RowsAffected() int {
+	return res.affected
+}
+
+func (res *batchResult) RowsReturned() int {
+	return res.returned
+}
@@ -2,16 +2,21 @@ package orm
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
+	"github.com/go-pg/pg/v10/internal"
 	"github.com/go-pg/pg/v10/types"
 )
 
 type SelectQuery struct {
 	q     *Query
 	count string
+
+	// prepared allows bind parameters set with LimitOffsetParams.
+	prepared bool
 }
 
 var (
@@ -37,10 +42,31 @@ func (q *SelectQuery) Operation() QueryOp {
 	return SelectOp
 }
 
+// ReadOnly reports whether the query can run on a read replica, i.e. it
+// does not lock rows, does not modify data in WITH queries, and was not
+// marked with UsePrimary.
+func (q *SelectQuery) ReadOnly() bool {
+	if q.q.hasFlag(usePrimaryFlag) || q.q.selFor != nil {
+		return false
+	}
+	for _, with := range q.q.with {
+		switch query := with.query.(type) {
+		case *SelectQuery:
+			if !query.ReadOnly() {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 func (q *SelectQuery) Clone() QueryCommand {
 	return &SelectQuery{
-		q:     q.q.Clone(),
-		count: q.count,
+		q:        q.q.Clone(),
+		count:    q.count,
+		prepared: q.prepared,
 	}
 }
 
@@ -56,8 +82,14 @@ func (q *SelectQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 	if q.q.stickyErr != nil {
 		return nil, q.q.stickyErr
 	}
+	if err := q.validate(fmter); err != nil {
+		return nil, err
+	}
+
+	b = q.q.appendComment(b)
 
-	cteCount := q.count != "" && (len(q.q.group) > 0 || q.isDistinct())
+	cteCount := q.count != "" &&
+		(len(q.q.group) > 0 || len(q.q.having) > 0 || q.isDistinct())
 	if cteCount {
 		b = append(b, `WITH "_count_wrapper" AS (`...)
 	}
@@ -157,6 +189,22 @@ func (q *SelectQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 		}
 	}
 
+	if len(q.q.windows) > 0 {
+		b = append(b, " WINDOW "...)
+		for i, w := range q.q.windows {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			b = types.AppendIdent(b, w.name, 1)
+			b = append(b, " AS ("...)
+			b, err = w.expr.AppendQuery(fmter, b)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, ')')
+		}
+	}
+
 	if q.count == "" {
 		if len(q.q.order) > 0 {
 			b = append(b, " ORDER BY "...)
@@ -171,14 +219,26 @@ func (q *SelectQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 			}
 		}
 
-		if q.q.limit != 0 {
-			b = append(b, " LIMIT "...)
-			b = strconv.AppendInt(b, int64(q.q.limit), 10)
-		}
+		if q.q.withTies {
+			if q.q.offset != 0 || q.q.offsetParam != 0 {
+				b = append(b, " OFFSET "...)
+				b = appendLimitValue(b, q.q.offset, q.q.offsetParam)
+				b = append(b, " ROWS"...)
+			}
+
+			b = append(b, " FETCH FIRST "...)
+			b = appendLimitValue(b, q.q.limit, q.q.limitParam)
+			b = append(b, " ROWS WITH TIES"...)
+		} else {
+			if q.q.limit != 0 || q.q.limitParam != 0 {
+				b = append(b, " LIMIT "...)
+				b = appendLimitValue(b, q.q.limit, q.q.limitParam)
+			}
 
-		if q.q.offset != 0 {
-			b = append(b, " OFFSET "...)
-			b = strconv.AppendInt(b, int64(q.q.offset), 10)
+			if q.q.offset != 0 || q.q.offsetParam != 0 {
+				b = append(b, " OFFSET "...)
+				b = appendLimitValue(b, q.q.offset, q.q.offsetParam)
+			}
 		}
 
 		if q.q.selFor != nil {
@@ -187,6 +247,10 @@ func (q *SelectQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 			if err != nil {
 				return nil, err
 			}
+			if q.q.selForWait != "" {
+				b = append(b, ' ')
+				b = append(b, q.q.selForWait...)
+			}
 		}
 	} else if cteCount {
 		b = append(b, `) SELECT `...)
@@ -211,6 +275,94 @@ func (q *SelectQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 	return b, q.q.stickyErr
 }
 
+// appendLimitValue appends the bind parameter $param if it is set
+// or the value otherwise.
+func appendLimitValue(b []byte, value, param int) []byte {
+	if param > 0 {
+		b = append(b, '$')
+		return strconv.AppendInt(b, int64(param), 10)
+	}
+	return strconv.AppendInt(b, int64(value), 10)
+}
+
+// validate reports clause combinations that PostgreSQL rejects so users get
+// a descriptive error before the query is sent.
+func (q *SelectQuery) validate(fmter QueryFormatter) error {
+	if q.count != "" {
+		return nil
+	}
+
+	if (q.q.limitParam > 0 || q.q.offsetParam > 0) && !q.prepared {
+		return errors.New("pg: LimitOffsetParams requires a prepared statement " +
+			"(use AppendPreparedQuery and DB.Prepare)")
+	}
+
+	if q.q.tableSample != nil && !q.q.hasTables() {
+		return errors.New("pg: TableSample requires a table")
+	}
+
+	if q.q.withTies && len(q.q.order) == 0 {
+		return errors.New("pg: LimitWithTies requires ORDER BY")
+	}
+
+	if q.q.selFor == nil {
+		if q.q.selForWait != "" {
+			return fmt.Errorf("pg: %s requires a locking clause (use For)", q.q.selForWait)
+		}
+		return nil
+	}
+
+	// The lock strength may be passed as a param, so the formatted clause
+	// is validated. Templates are not formatted and are left as is.
+	selFor := q.q.selFor.query
+	if !isTemplateFormatter(fmter) {
+		b, err := q.q.selFor.AppendQuery(fmter, nil)
+		if err != nil {
+			return err
+		}
+		selFor = string(b)
+
+		if !isLockStrength(selFor) {
+			return fmt.Errorf("pg: invalid lock strength in FOR %s "+
+				"(expected UPDATE, NO KEY UPDATE, SHARE, or KEY SHARE)", selFor)
+		}
+	}
+
+	var clause string
+	switch {
+	case len(q.q.group) > 0:
+		clause = "GROUP BY"
+	case len(q.q.having) > 0:
+		clause = "HAVING"
+	case q.isDistinct():
+		clause = "DISTINCT"
+	case len(q.q.union) > 0:
+		clause = "UNION/INTERSECT/EXCEPT"
+	case len(q.q.windows) > 0:
+		clause = "WINDOW"
+	default:
+		return nil
+	}
+	return fmt.Errorf("pg: FOR %s is not allowed with %s clause", selFor, clause)
+}
+
+var lockStrengths = []string{"UPDATE", "NO KEY UPDATE", "SHARE", "KEY SHARE"}
+
+// isLockStrength reports whether the locking clause starts with a lock
+// strength supported by PostgreSQL.
+func isLockStrength(s string) bool {
+	s = internal.UpperString(strings.TrimSpace(s))
+	for _, strength := range lockStrengths {
+		if !strings.HasPrefix(s, strength) {
+			continue
+		}
+		if len(s) == len(strength) || s[len(strength)] == ' ' {
+			return true
+		}
+	}
+	return false
+}
+
 func (q SelectQuery) appendColumns(fmter QueryFormatter, b []byte) (_ []byte, err error) {
 	start := len(b)
 
@@ -277,6 +429,9 @@ func (q *SelectQuery) appendTables(fmter QueryFormatter, b []byte) (_ []byte, er
 			b = append(b, " AS "...)
 			b = append(b, table.Alias...)
 		}
+		if q.q.tableSample != nil {
+			b = q.q.tableSample.appendTableSample(b)
+		}
 
 		if len(tables) > 0 {
 			b = append(b, ", "...)
@@ -290,6 +445,9 @@ func (q *SelectQuery) appendTables(fmter QueryFormatter, b []byte) (_ []byte, er
 			b = append(b, " AS "...)
 			b = append(b, q.q.tableModel.Table().Alias...)
 		}
+		if q.q.tableSample != nil {
+			b = q.q.tableSample.appendTableSample(b)
+		}
 
 		tables = tables[1:]
 		if len(tables) > 0 {
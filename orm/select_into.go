@@ -0,0 +1,86 @@
+package orm
+
+import (
+	"errors"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+type SelectIntoOptions struct {
+	// OnCommitDrop drops the table at the end of the current transaction.
+	OnCommitDrop bool
+}
+
+// SelectIntoQuery creates a temporary table from the select query:
+//
+//    db.Model((*Book)(nil)).Column("id").Where("author_id = 1").
+//    	SelectInto("author_books", &orm.SelectIntoOptions{OnCommitDrop: true})
+//
+// generates
+//
+//    CREATE TEMP TABLE "author_books" ON COMMIT DROP AS
+//    SELECT "id" FROM "books" AS "book" WHERE (author_id = 1)
+type SelectIntoQuery struct {
+	q     *Query
+	table string
+	opt   *SelectIntoOptions
+}
+
+var (
+	_ QueryAppender = (*SelectIntoQuery)(nil)
+	_ QueryCommand  = (*SelectIntoQuery)(nil)
+)
+
+func NewSelectIntoQuery(q *Query, table string, opt *SelectIntoOptions) *SelectIntoQuery {
+	return &SelectIntoQuery{
+		q:     q,
+		table: table,
+		opt:   opt,
+	}
+}
+
+func (q *SelectIntoQuery) String() string {
+	b, err := q.AppendQuery(defaultFmter, nil)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func (q *SelectIntoQuery) Operation() QueryOp {
+	return SelectIntoOp
+}
+
+func (q *SelectIntoQuery) Clone() QueryCommand {
+	return &SelectIntoQuery{
+		q:     q.q.Clone(),
+		table: q.table,
+		opt:   q.opt,
+	}
+}
+
+func (q *SelectIntoQuery) Query() *Query {
+	return q.q
+}
+
+func (q *SelectIntoQuery) AppendTemplate(b []byte) ([]byte, error) {
+	return q.AppendQuery(dummyFormatter{}, b)
+}
+
+func (q *SelectIntoQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err error) {
+	if q.q.stickyErr != nil {
+		return nil, q.q.stickyErr
+	}
+	if q.table == "" {
+		return nil, errors.New("pg: SelectInto requires a table name")
+	}
+
+	b = append(b, "CREATE TEMP TABLE "...)
+	b = types.AppendIdent(b, q.table, 1)
+	if q.opt != nil && q.opt.OnCommitDrop {
+		b = append(b, " ON COMMIT DROP"...)
+	}
+	b = append(b, " AS "...)
+
+	return NewSelectQuery(q.q).AppendQuery(fmter, b)
+}
@@ -0,0 +1,39 @@
+package orm
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type SelectIntoModel struct {
+	ID       int
+	AuthorID int
+}
+
+var _ = Describe("SelectInto", func() {
+	It("creates temp table from the query", func() {
+		q := NewQuery(nil, &SelectIntoModel{}).Column("id").Where("author_id = ?", 1)
+
+		s := selectIntoQueryString(q, "author_models", nil)
+		Expect(s).To(Equal(`CREATE TEMP TABLE "author_models" AS SELECT "id" FROM "select_into_models" AS "select_into_model" WHERE (author_id = 1)`))
+	})
+
+	It("supports ON COMMIT DROP", func() {
+		q := NewQuery(nil).TableExpr("generate_series(1, 10) AS n")
+
+		s := selectIntoQueryString(q, "series", &SelectIntoOptions{OnCommitDrop: true})
+		Expect(s).To(Equal(`CREATE TEMP TABLE "series" ON COMMIT DROP AS SELECT * FROM generate_series(1, 10) AS n`))
+	})
+
+	It("returns an error without table name", func() {
+		q := NewQuery(nil, &SelectIntoModel{})
+
+		_, err := NewSelectIntoQuery(q, "", nil).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: SelectInto requires a table name"))
+	})
+})
+
+func selectIntoQueryString(q *Query, table string, opt *SelectIntoOptions) string {
+	qq := NewSelectIntoQuery(q, table, opt)
+	return queryString(qq)
+}
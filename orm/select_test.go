@@ -1,6 +1,9 @@
 package orm
 
 import (
+	"context"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -36,6 +39,17 @@ type HasManyModel struct {
 	SelectModelId int
 }
 
+type CalendarModel struct {
+	Id     int
+	During string           `pg:"type:daterange"`
+	Events []*CalendarEvent `pg:"rel:has-many,join:calendar_event.during && parent.during"`
+}
+
+type CalendarEvent struct {
+	Id     int
+	During string `pg:"type:daterange"`
+}
+
 var _ = Describe("Select", func() {
 	It("works with User model", func() {
 		q := NewQuery(nil, &User{})
@@ -123,6 +137,59 @@ var _ = Describe("Select", func() {
 		Expect(s).To(Equal(`SELECT expr FROM "has_many_models" AS "has_many_model" WHERE ("has_many_model"."select_model_id" IN (1))`))
 	})
 
+	It("applies has many limit per parent", func() {
+		q := NewQuery(nil, &SelectModel{Id: 1}).
+			Relation("HasMany", func(q *Query) (*Query, error) {
+				return q.Order("id DESC").Limit(5).Offset(2), nil
+			})
+
+		q, err := q.tableModel.GetJoin("HasMany").manyQuery(q.New())
+		Expect(err).NotTo(HaveOccurred())
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "has_many_model".* FROM (SELECT "has_many_model"."id", "has_many_model"."select_model_id", row_number() OVER (PARTITION BY "has_many_model"."select_model_id" ORDER BY "id" DESC) AS "_row_number" FROM "has_many_models" AS "has_many_model" WHERE ("has_many_model"."select_model_id" IN (1))) AS "has_many_model" WHERE ("_row_number" > 2) AND ("_row_number" <= 7) ORDER BY "_row_number" ASC`))
+	})
+
+	It("does not support LimitOffsetParams in has many relations", func() {
+		q := NewQuery(nil, &SelectModel{Id: 1}).
+			Relation("HasMany", func(q *Query) (*Query, error) {
+				return q.Order("id").Limit(5).LimitOffsetParams(1, 2), nil
+			})
+
+		_, err := q.tableModel.GetJoin("HasMany").manyQuery(q.New())
+		Expect(err).To(MatchError(`pg: LimitOffsetParams is not supported by relation="HasMany"`))
+	})
+
+	It("joins has many with join condition", func() {
+		q := NewQuery(nil, &[]CalendarModel{{Id: 1}, {Id: 2}}).Relation("Events")
+
+		q, err := q.tableModel.GetJoin("Events").manyQuery(q.New())
+		Expect(err).NotTo(HaveOccurred())
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "calendar_event"."id", "calendar_event"."during", "parent"."id" AS "_parent__id" FROM "calendar_events" AS "calendar_event" JOIN "calendar_models" AS "parent" ON (calendar_event.during && parent.during) WHERE ("parent"."id" IN (1, 2))`))
+	})
+
+	It("applies has many limit per parent with join condition", func() {
+		q := NewQuery(nil, &CalendarModel{Id: 1}).
+			Relation("Events", func(q *Query) (*Query, error) {
+				return q.Order("calendar_event.id").Limit(3), nil
+			})
+
+		q, err := q.tableModel.GetJoin("Events").manyQuery(q.New())
+		Expect(err).NotTo(HaveOccurred())
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "calendar_event".* FROM (SELECT "calendar_event"."id", "calendar_event"."during", "parent"."id" AS "_parent__id", row_number() OVER (PARTITION BY "parent"."id" ORDER BY "calendar_event"."id") AS "_row_number" FROM "calendar_events" AS "calendar_event" JOIN "calendar_models" AS "parent" ON (calendar_event.during && parent.during) WHERE ("parent"."id" IN (1))) AS "calendar_event" WHERE ("_row_number" > 0) AND ("_row_number" <= 3) ORDER BY "_row_number" ASC`))
+	})
+
+	It("does not support WhereRelationCount with join condition", func() {
+		q := NewQuery(nil, &CalendarModel{}).WhereRelationCount("Events", ">", 1)
+
+		_, err := NewSelectQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError(`pg: WhereRelationCount does not support relation="Events" with join condition`))
+	})
+
 	It("expands ?TableColumns", func() {
 		q := NewQuery(nil, &SelectModel{Id: 1}).ColumnExpr("?TableColumns")
 
@@ -173,6 +240,275 @@ var _ = Describe("Select", func() {
 		Expect(s).To(Equal(`SELECT * WHERE (id IN (SELECT "id" FROM "select_models" AS "select_model" WHERE (name IS NOT NULL)))`))
 	})
 
+	It("supports WhereExists and WhereNotExists", func() {
+		subq := NewQuery(nil, &HasManyModel{}).
+			ColumnExpr("1").
+			Where("has_many_model.select_model_id = select_model.id").
+			Where("has_many_model.id > ?", 10)
+		q := NewQuery(nil, &SelectModel{}).
+			Column("id").
+			Where("name = ?", "foo").
+			WhereExists(subq).
+			WhereNotExists(NewQuery(nil, &HasOneModel{}).ColumnExpr("1").Where("id = ?", 20))
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" WHERE (name = 'foo') AND (EXISTS (SELECT 1 FROM "has_many_models" AS "has_many_model" WHERE (has_many_model.select_model_id = select_model.id) AND (has_many_model.id > 10))) AND (NOT EXISTS (SELECT 1 FROM "has_one_models" AS "has_one_model" WHERE (id = 20)))`))
+	})
+
+	It("supports IS DISTINCT FROM", func() {
+		var name *string
+		q := NewQuery(nil, &SelectModel{}).
+			Column("id").
+			WhereDistinctFrom("name", "foo").
+			WhereDistinctFrom("select_model.name", nil).
+			WhereNotDistinctFrom("name", name).
+			WhereNotDistinctFrom("has_one_id", 1)
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" WHERE ("name" IS DISTINCT FROM 'foo') AND ("select_model"."name" IS DISTINCT FROM NULL) AND ("name" IS NOT DISTINCT FROM NULL) AND ("has_one_id" IS NOT DISTINCT FROM 1)`))
+	})
+
+	It("supports inet operators", func() {
+		network, err := types.ParseCIDR("10.0.0.0/8")
+		Expect(err).NotTo(HaveOccurred())
+		addr, err := types.ParseInet("2001:db8::1")
+		Expect(err).NotTo(HaveOccurred())
+
+		q := NewQuery(nil, &SelectModel{}).
+			Column("id").
+			WhereInetContainedBy("select_model.addr", network).
+			WhereInetContainedByOrEqual("addr", "10.0.0.0/8'; --").
+			WhereInetContains("network", addr).
+			WhereInetContainsOrEqual("network", addr)
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" WHERE ("select_model"."addr" << '10.0.0.0/8') AND ("addr" <<= '10.0.0.0/8''; --') AND ("network" >> '2001:db8::1') AND ("network" >>= '2001:db8::1')`))
+	})
+
+	It("supports hstore operators", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			ColumnExpr("? AS color", HstoreGet("attrs", "color")).
+			WhereHstoreHasKey("select_model.attrs", "size").
+			WhereHstoreContains("attrs", map[string]string{"color": "red"})
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "attrs" -> 'color' AS color FROM "select_models" AS "select_model" WHERE ("select_model"."attrs" ? 'size') AND ("attrs" @> '"color"=>"red"')`))
+	})
+
+	It("supports ROLLUP, CUBE, and GROUPING SETS", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			Column("brand", "size").
+			ColumnExpr("? AS subtotal", Grouping("brand", "size")).
+			ColumnExpr("sum(sales)").
+			GroupByRollup("brand", "size")
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "brand", "size", GROUPING("brand", "size") AS subtotal, sum(sales) FROM "select_models" AS "select_model" GROUP BY ROLLUP ("brand", "size")`))
+
+		q = NewQuery(nil, &SelectModel{}).
+			Column("brand").
+			Group("region").
+			GroupByCube("select_model.brand", "size")
+
+		s = selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "brand" FROM "select_models" AS "select_model" GROUP BY "region", CUBE ("select_model"."brand", "size")`))
+
+		q = NewQuery(nil, &SelectModel{}).
+			Column("brand").
+			GroupingSets([]string{"brand", "size"}, []string{"size"}, nil)
+
+		s = selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "brand" FROM "select_models" AS "select_model" GROUP BY GROUPING SETS (("brand", "size"), "size", ())`))
+	})
+
+	It("binds params in ORDER BY", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			Column("id").
+			Where("name = ?", "where").
+			OrderExpr("array_position(?, status)", types.NewArray([]string{"active", "pending"})).
+			OrderExpr("CASE WHEN name = ? THEN 0 ELSE ? END", "order", 1).
+			OrderExpr("position(? in name), ?", []byte("a"), types.Ident("id")).
+			Where("id > ?", 10)
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" WHERE (name = 'where') AND (id > 10) ORDER BY array_position('{"active","pending"}', status), CASE WHEN name = 'order' THEN 0 ELSE 1 END, position('\x61' in name), "id"`))
+	})
+
+	It("returns an error for FOR UPDATE with incompatible clauses", func() {
+		tests := []struct {
+			q      *Query
+			wanted string
+		}{
+			{
+				NewQuery(nil, &SelectModel{}).Group("name"),
+				"pg: FOR UPDATE is not allowed with GROUP BY clause",
+			},
+			{
+				NewQuery(nil, &SelectModel{}).Having("count(*) > 1"),
+				"pg: FOR UPDATE is not allowed with HAVING clause",
+			},
+			{
+				NewQuery(nil, &SelectModel{}).DistinctOn("name"),
+				"pg: FOR UPDATE is not allowed with DISTINCT clause",
+			},
+			{
+				NewQuery(nil, &SelectModel{}).Union(NewQuery(nil, &SelectModel{})),
+				"pg: FOR UPDATE is not allowed with UNION/INTERSECT/EXCEPT clause",
+			},
+			{
+				NewQuery(nil, &SelectModel{}).Window("w", "ORDER BY id"),
+				"pg: FOR UPDATE is not allowed with WINDOW clause",
+			},
+		}
+		for _, test := range tests {
+			_, err := NewSelectQuery(test.q.For("UPDATE")).AppendQuery(defaultFmter, nil)
+			Expect(err).To(MatchError(test.wanted))
+		}
+
+		s := selectQueryString(NewQuery(nil, &SelectModel{}).Where("id = 1").For("UPDATE"))
+		Expect(s).To(Equal(`SELECT "select_model"."id", "select_model"."name", "select_model"."has_one_id" FROM "select_models" AS "select_model" WHERE (id = 1) FOR UPDATE`))
+	})
+
+	It("supports WINDOW", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			Column("id").
+			ColumnExpr("row_number() OVER w").
+			ColumnExpr("rank() OVER w2").
+			Group("id").
+			Having("count(*) > ?", 1).
+			Window("w", "PARTITION BY name ORDER BY id").
+			Window("w2", "ORDER BY ? DESC", types.Ident("id")).
+			Order("id")
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "id", row_number() OVER w, rank() OVER w2 FROM "select_models" AS "select_model" GROUP BY "id" HAVING (count(*) > 1) WINDOW "w" AS (PARTITION BY name ORDER BY id), "w2" AS (ORDER BY "id" DESC) ORDER BY "id"`))
+	})
+
+	It("binds HAVING params independently of other clauses", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			ColumnExpr("name, count(*) FILTER (WHERE id > ?) AS n", 1).
+			Join("JOIN ? AS p ON p.id = select_model.id", types.Ident("parents")).
+			Where("name <> ?", "having").
+			GroupExpr("name, ?", types.Ident("p.kind")).
+			Having("count(*) > ?", 2).
+			Having("max(id) BETWEEN ? AND ?", 3, 4).
+			OrderExpr("n DESC, ?", types.Ident("name")).
+			Limit(5)
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT name, count(*) FILTER (WHERE id > 1) AS n FROM "select_models" AS "select_model" JOIN "parents" AS p ON p.id = select_model.id WHERE (name <> 'having') GROUP BY name, "p"."kind" HAVING (count(*) > 2) AND (max(id) BETWEEN 3 AND 4) ORDER BY n DESC, "name" LIMIT 5`))
+	})
+
+	It("supports FETCH FIRST WITH TIES", func() {
+		q := NewQuery(nil).Table("scores").Order("score DESC").LimitWithTies(10)
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT * FROM "scores" ORDER BY "score" DESC FETCH FIRST 10 ROWS WITH TIES`))
+
+		s = selectQueryString(q.Offset(20))
+		Expect(s).To(Equal(`SELECT * FROM "scores" ORDER BY "score" DESC OFFSET 20 ROWS FETCH FIRST 10 ROWS WITH TIES`))
+
+		s = selectQueryString(q.Limit(5))
+		Expect(s).To(Equal(`SELECT * FROM "scores" ORDER BY "score" DESC LIMIT 5 OFFSET 20`))
+	})
+
+	It("supports LimitOffsetParams", func() {
+		q := NewQuery(nil).Table("books").Order("id").Limit(10).Offset(20).LimitOffsetParams(1, 2)
+		preparedQueryString := func(q *Query) string {
+			b, err := q.AppendPreparedQuery(defaultFmter, nil)
+			Expect(err).NotTo(HaveOccurred())
+			return string(b)
+		}
+
+		s := preparedQueryString(q)
+		Expect(s).To(Equal(`SELECT * FROM "books" ORDER BY "id" LIMIT $1 OFFSET $2`))
+
+		s = preparedQueryString(q.Clone().Limit(5).Offset(0))
+		Expect(s).To(Equal(`SELECT * FROM "books" ORDER BY "id" LIMIT $1 OFFSET $2`))
+
+		s = preparedQueryString(q.Clone().LimitOffsetParams(3, 0))
+		Expect(s).To(Equal(`SELECT * FROM "books" ORDER BY "id" LIMIT $3 OFFSET 20`))
+
+		s = preparedQueryString(q.Clone().LimitOffsetParams(0, 0).Offset(0))
+		Expect(s).To(Equal(`SELECT * FROM "books" ORDER BY "id" LIMIT 10`))
+
+		s = preparedQueryString(q.Clone().LimitWithTies(10))
+		Expect(s).To(Equal(`SELECT * FROM "books" ORDER BY "id" OFFSET $2 ROWS FETCH FIRST $1 ROWS WITH TIES`))
+
+		s = queryString(q.countSelectQuery("count(*)"))
+		Expect(s).To(Equal(`SELECT count(*) FROM "books"`))
+	})
+
+	It("returns an error for LimitOffsetParams outside a prepared statement", func() {
+		q := NewQuery(nil).Table("books").LimitOffsetParams(1, 0)
+
+		_, err := NewSelectQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: LimitOffsetParams requires a prepared statement " +
+			"(use AppendPreparedQuery and DB.Prepare)"))
+
+		s := selectQueryString(NewQuery(nil).Table("authors").
+			Where("id IN (?)", q.Column("author_id")))
+		Expect(s).To(ContainSubstring("?!(pg: LimitOffsetParams requires a prepared statement"))
+	})
+
+	It("returns an error for negative LimitOffsetParams", func() {
+		q := NewQuery(nil).Table("books").LimitOffsetParams(-1, 0)
+
+		_, err := NewSelectQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: invalid LimitOffsetParams(-1, 0)"))
+	})
+
+	It("returns an error for WITH TIES without ORDER BY", func() {
+		q := NewQuery(nil).Table("scores").LimitWithTies(10)
+
+		_, err := NewSelectQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: LimitWithTies requires ORDER BY"))
+	})
+
+	It("supports TABLESAMPLE", func() {
+		q := NewQuery(nil, &SelectModel{}).Column("id").TableSample("system", 10)
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" TABLESAMPLE SYSTEM (10)`))
+
+		q = NewQuery(nil).
+			Table("books").
+			Table("authors").
+			TableSample("BERNOULLI", 0.5).
+			Repeatable(42)
+
+		s = selectQueryString(q)
+		Expect(s).To(Equal(`SELECT * FROM "books" TABLESAMPLE BERNOULLI (0.5) REPEATABLE (42), "authors"`))
+	})
+
+	It("returns an error for invalid TABLESAMPLE", func() {
+		tests := []struct {
+			q      *Query
+			wanted string
+		}{
+			{
+				NewQuery(nil).Table("books").TableSample("SYSTEM; DROP", 10),
+				`pg: invalid TABLESAMPLE method="SYSTEM; DROP"`,
+			},
+			{
+				NewQuery(nil).Table("books").TableSample("SYSTEM", 101),
+				"pg: TABLESAMPLE SYSTEM percentage must be between 0 and 100, got 101",
+			},
+			{
+				NewQuery(nil).Table("books").Repeatable(1),
+				"pg: Repeatable requires TableSample",
+			},
+			{
+				NewQuery(nil).TableSample("SYSTEM", 10),
+				"pg: TableSample requires a table",
+			},
+		}
+		for _, test := range tests {
+			_, err := NewSelectQuery(test.q).AppendQuery(defaultFmter, nil)
+			Expect(err).To(MatchError(test.wanted))
+		}
+	})
+
 	It("supports locking", func() {
 		q := NewQuery(nil).For("UPDATE SKIP LOCKED")
 
@@ -180,6 +516,68 @@ var _ = Describe("Select", func() {
 		Expect(s).To(Equal(`SELECT * FOR UPDATE SKIP LOCKED`))
 	})
 
+	It("supports lock strengths", func() {
+		for _, strength := range []string{"UPDATE", "NO KEY UPDATE", "SHARE", "KEY SHARE"} {
+			q := NewQuery(nil, &SelectModel{}).Column("id").For(strength)
+
+			s := selectQueryString(q)
+			Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" FOR ` + strength))
+
+			s = selectQueryString(q.Clone().SkipLocked())
+			Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" FOR ` + strength + ` SKIP LOCKED`))
+
+			s = selectQueryString(q.Clone().NoWait())
+			Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" FOR ` + strength + ` NOWAIT`))
+
+			s = selectQueryString(q.Clone().For(strength + " OF ?TableAlias").SkipLocked())
+			Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" FOR ` + strength + ` OF "select_model" SKIP LOCKED`))
+
+			s = selectQueryString(q.Clone().For("? OF ?TableAlias", types.Safe(strength)))
+			Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" FOR ` + strength + ` OF "select_model"`))
+		}
+	})
+
+	It("supports locking joined tables with ForOf", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			Column("select_model.id").
+			Relation("HasOne").
+			ForOf("UPDATE", "select_model").
+			NoWait()
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "select_model"."id", "has_one"."id" AS "has_one__id" FROM "select_models" AS "select_model" LEFT JOIN "has_one_models" AS "has_one" ON "has_one"."id" = "select_model"."has_one_id" FOR UPDATE OF "select_model" NOWAIT`))
+
+		q = NewQuery(nil, &SelectModel{}).
+			Column("select_model.id").
+			ForOf("NO KEY UPDATE", "select_model", "has_one", "weird\"alias")
+		s = selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "select_model"."id" FROM "select_models" AS "select_model" FOR NO KEY UPDATE OF "select_model", "has_one", "weird""alias"`))
+
+		q = NewQuery(nil, &SelectModel{}).Column("id").ForOf("SHARE")
+		s = selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" FOR SHARE`))
+
+		q = NewQuery(nil, &SelectModel{}).Column("id").For("UPDATE OF ?, ?", types.Ident("a"), types.Ident("b"))
+		s = selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "id" FROM "select_models" AS "select_model" FOR UPDATE OF "a", "b"`))
+	})
+
+	It("returns an error for invalid locking clauses", func() {
+		_, err := NewSelectQuery(NewQuery(nil).For("KEY UPDATE")).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: invalid lock strength in FOR KEY UPDATE " +
+			"(expected UPDATE, NO KEY UPDATE, SHARE, or KEY SHARE)"))
+
+		_, err = NewSelectQuery(NewQuery(nil).For("?", types.Safe("KEY UPDATE"))).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: invalid lock strength in FOR KEY UPDATE " +
+			"(expected UPDATE, NO KEY UPDATE, SHARE, or KEY SHARE)"))
+
+		_, err = NewSelectQuery(NewQuery(nil).For("SHARED")).AppendQuery(defaultFmter, nil)
+		Expect(err).To(HaveOccurred())
+
+		_, err = NewSelectQuery(NewQuery(nil).NoWait()).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: NOWAIT requires a locking clause (use For)"))
+	})
+
 	It("supports WhereGroup", func() {
 		q := NewQuery(nil).Where("TRUE").WhereGroup(func(q *Query) (*Query, error) {
 			q = q.Where("FALSE").WhereOr("TRUE")
@@ -239,6 +637,17 @@ var _ = Describe("Select", func() {
 		Expect(s).To(Equal(`SELECT * WHERE (id IN ('foo','bar'))`))
 	})
 
+	It("supports WhereNotIn", func() {
+		q := NewQuery(nil).
+			WhereNotIn("select_model.id", []int{1, 2})
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT * WHERE ("select_model"."id" NOT IN (1,2))`))
+
+		s = selectQueryString(NewQuery(nil).WhereNotIn("id", []int{}))
+		Expect(s).To(Equal(`SELECT * WHERE (TRUE)`))
+	})
+
 	It("supports Where & pg.In", func() {
 		q := NewQuery(nil).
 			Where("id IN (?)", types.In([]string{"foo", "bar"}))
@@ -278,6 +687,56 @@ var _ = Describe("Select", func() {
 	})
 })
 
+type ScanOnlySelectModel struct {
+	Id       int
+	Distance float64 `pg:",scanonly"`
+}
+
+var _ = Describe("Select WherePK with composite primary key", func() {
+	It("selects a struct", func() {
+		q := NewQuery(nil, &CompositePKTest{TenantID: 1, ID: 2}).WherePK()
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "composite_pk_test"."tenant_id", "composite_pk_test"."id", "composite_pk_test"."name" FROM "composite_pk_tests" AS "composite_pk_test" WHERE "composite_pk_test"."tenant_id" = 1 AND "composite_pk_test"."id" = 2`))
+	})
+
+	It("selects a slice", func() {
+		models := []CompositePKTest{{TenantID: 1, ID: 2}, {TenantID: 3, ID: 4}}
+		q := NewQuery(nil, &models).WherePK()
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "composite_pk_test"."tenant_id", "composite_pk_test"."id", "composite_pk_test"."name" FROM "composite_pk_tests" AS "composite_pk_test" JOIN (VALUES (1, 2, 0), (3, 4, 1)) AS "_data" ("tenant_id", "id", "ordering") ON TRUE WHERE "composite_pk_test"."tenant_id" = "_data"."tenant_id" AND "composite_pk_test"."id" = "_data"."id" ORDER BY "_data"."ordering" ASC`))
+	})
+
+	It("returns an error when a primary key is missing", func() {
+		q := NewQuery(nil, &DeleteTest{}).WherePK()
+
+		_, err := NewSelectQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("scanonly", func() {
+	It("selects computed column only with ColumnExpr", func() {
+		q := NewQuery(nil, &ScanOnlySelectModel{})
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "scan_only_select_model"."id" FROM "scan_only_select_models" AS "scan_only_select_model"`))
+
+		q = q.ColumnExpr("?TableColumns").ColumnExpr("point(0, 0) <-> point(1, 1) AS distance")
+
+		s = selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "scan_only_select_model"."id", point(0, 0) <-> point(1, 1) AS distance FROM "scan_only_select_models" AS "scan_only_select_model"`))
+	})
+
+	It("does not insert computed column", func() {
+		q := NewQuery(nil, &ScanOnlySelectModel{Id: 1, Distance: 2})
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "scan_only_select_models" ("id") VALUES (1)`))
+	})
+})
+
 var _ = Describe("Count", func() {
 	It("removes LIMIT, OFFSET, and ORDER", func() {
 		q := NewQuery(nil).Order("order").Limit(1).Offset(2)
@@ -316,6 +775,17 @@ var _ = Describe("Count", func() {
 		Expect(s).To(Equal(`WITH "_count_wrapper" AS (SELECT * GROUP BY "one") SELECT count(*) FROM "_count_wrapper"`))
 	})
 
+	It("uses CTE when query contains HAVING", func() {
+		q := NewQuery(nil).
+			TableExpr("books").
+			ColumnExpr("sum(price) * ?", 2).
+			Where("author_id = ?", 10).
+			Having("sum(price) > ?", 100)
+
+		s := queryString(q.countSelectQuery("count(*)"))
+		Expect(s).To(Equal(`WITH "_count_wrapper" AS (SELECT sum(price) * 2 FROM books WHERE (author_id = 10) HAVING (sum(price) > 100)) SELECT count(*) FROM "_count_wrapper"`))
+	})
+
 	It("uses CTE when column contains DISTINCT", func() {
 		q := NewQuery(nil).ColumnExpr("DISTINCT group_id")
 
@@ -399,6 +869,25 @@ var _ = Describe("Select Order", func() {
 			Expect(s).To(Equal(`SELECT * ORDER BY ` + test.query))
 		}
 	})
+
+	It("sets order with collation", func() {
+		q := NewQuery(nil).
+			OrderCollate("name", "de-DE-x-icu").
+			OrderCollate("book.title DESC NULLS LAST", "C").
+			Order("id")
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT * ORDER BY "name" COLLATE "de-DE-x-icu", "book"."title" COLLATE "C" DESC NULLS LAST, "id"`))
+	})
+
+	It("returns an error for invalid collation", func() {
+		for _, collation := range []string{"", `C" DESC, (SELECT 1) --`, "de DE"} {
+			q := NewQuery(nil).OrderCollate("name", collation)
+
+			_, err := NewSelectQuery(q).AppendQuery(defaultFmter, nil)
+			Expect(err).To(MatchError(fmt.Sprintf("pg: invalid collation=%q", collation)))
+		}
+	})
 })
 
 type NonSoftDeleteModel struct {
@@ -458,6 +947,19 @@ var _ = Describe("SoftDeleteModel", func() {
 		Expect(s).To(Equal(`SELECT "soft_delete_model"."id", "soft_delete_model"."deleted_at" FROM "soft_delete_models" AS "soft_delete_model"`))
 	})
 
+	It("supports WithAllDeleted context", func() {
+		ctx := WithAllDeleted(context.Background())
+		q := NewQueryContext(ctx, nil, &SoftDeleteModel{})
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "soft_delete_model"."id", "soft_delete_model"."deleted_at" FROM "soft_delete_models" AS "soft_delete_model"`))
+
+		q = NewQueryContext(ctx, nil, &SoftDeleteModel{}).Deleted()
+
+		s = selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "soft_delete_model"."id", "soft_delete_model"."deleted_at" FROM "soft_delete_models" AS "soft_delete_model" WHERE "soft_delete_model"."deleted_at" IS NOT NULL`))
+	})
+
 	It("will respect join SoftDelete", func() {
 		q := NewQuery(nil, &SoftDeleteParent{}).Relation("Children").Relation("Children.SubChildren")
 
@@ -529,3 +1031,147 @@ func TestLongSelectFormatter(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, `SELECT "model"."11 columns" FROM "models" AS "model"`, string(b))
 }
+
+type RemoteUser struct {
+	tableName struct{} `pg:"table:foreign_schema.remote_users"`
+
+	Id        int
+	Name      string
+	ProfileId int
+	Profile   *RemoteProfile
+	Posts     []RemotePost
+}
+
+type RemoteProfile struct {
+	tableName struct{} `pg:"\"Remote Schema\".\"Profiles.v2\",alias:profile"`
+
+	Id int
+}
+
+type RemotePost struct {
+	tableName struct{} `pg:"foreign_schema.remote_posts,alias:post"`
+
+	Id           int
+	RemoteUserId int
+}
+
+var _ = Describe("schema-qualified table", func() {
+	It("quotes every part of the table name", func() {
+		table := GetTable(reflect.TypeOf(RemoteUser{}))
+		Expect(string(table.SQLName)).To(Equal(`"foreign_schema"."remote_users"`))
+		Expect(string(table.Alias)).To(Equal(`"remote_user"`))
+
+		table = GetTable(reflect.TypeOf(RemoteProfile{}))
+		Expect(string(table.SQLName)).To(Equal(`"Remote Schema"."Profiles.v2"`))
+	})
+
+	It("selects with relations", func() {
+		q := NewQuery(nil, &RemoteUser{Id: 1}).Relation("Profile").WherePK()
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "remote_user"."id", "remote_user"."name", "remote_user"."profile_id", "profile"."id" AS "profile__id" FROM "foreign_schema"."remote_users" AS "remote_user" LEFT JOIN "Remote Schema"."Profiles.v2" AS "profile" ON "profile"."id" = "remote_user"."profile_id" WHERE "remote_user"."id" = 1`))
+
+		q = NewQuery(nil, &RemoteUser{Id: 1}).Relation("Posts")
+		q, err := q.tableModel.GetJoin("Posts").manyQuery(q.New())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "post"."id", "post"."remote_user_id" FROM "foreign_schema"."remote_posts" AS "post" WHERE ("post"."remote_user_id" IN (1))`))
+	})
+
+	It("inserts into the table", func() {
+		q := NewQuery(nil, &RemoteUser{Id: 1, Name: "foo", ProfileId: 2})
+
+		s := insertQueryString(q)
+		Expect(s).To(Equal(`INSERT INTO "foreign_schema"."remote_users" ("id", "name", "profile_id") VALUES (1, 'foo', 2)`))
+	})
+})
+
+type RelCountPost struct {
+	Id       int
+	Comments []RelCountComment `pg:"rel:has-many"`
+	Tags     []RelCountTag     `pg:"many2many:rel_count_post_tags"`
+	Notes    []RelCountNote    `pg:"rel:has-many,join_fk:trackable_,polymorphic:trackable_"`
+}
+
+type RelCountComment struct {
+	Id             int
+	RelCountPostId int
+	DeletedAt      time.Time `pg:",soft_delete"`
+}
+
+type RelCountTag struct {
+	Id int
+}
+
+type RelCountPostTag struct {
+	tableName struct{} `pg:"rel_count_post_tags"`
+
+	RelCountPostId int
+	RelCountTagId  int
+}
+
+func init() {
+	RegisterTable((*RelCountPostTag)(nil))
+}
+
+type RelCountNote struct {
+	Id            int
+	TrackableId   int
+	TrackableType string
+}
+
+var _ = Describe("WhereRelationCount", func() {
+	It("counts has-many relations", func() {
+		q := NewQuery(nil, (*RelCountPost)(nil)).
+			Column("id").
+			WhereRelationCount("Comments", ">", 3)
+
+		Expect(selectQueryString(q)).To(Equal(`SELECT "id" FROM "rel_count_posts" AS "rel_count_post" WHERE ((SELECT count(*) FROM "rel_count_comments" AS "rel_count_post__comments" WHERE "rel_count_post__comments"."rel_count_post_id" = "rel_count_post"."id" AND "rel_count_post__comments"."deleted_at" IS NULL) > 3)`))
+	})
+
+	It("counts soft deleted rows with WithAllDeleted", func() {
+		q := NewQuery(nil, (*RelCountPost)(nil)).
+			Context(WithAllDeleted(context.Background())).
+			Column("id").
+			WhereRelationCount("Comments", ">", 3)
+
+		Expect(selectQueryString(q)).To(Equal(`SELECT "id" FROM "rel_count_posts" AS "rel_count_post" WHERE ((SELECT count(*) FROM "rel_count_comments" AS "rel_count_post__comments" WHERE "rel_count_post__comments"."rel_count_post_id" = "rel_count_post"."id") > 3)`))
+	})
+
+	It("does not depend on the query after the condition is added", func() {
+		q := NewQuery(nil, (*RelCountPost)(nil)).
+			Column("id").
+			WhereRelationCount("Comments", ">", 3)
+		clone := q.Clone()
+		q.AllWithDeleted()
+
+		Expect(selectQueryString(clone)).To(ContainSubstring(`"rel_count_post__comments"."deleted_at" IS NULL`))
+	})
+
+	It("counts many2many relations", func() {
+		q := NewQuery(nil, (*RelCountPost)(nil)).
+			Column("id").
+			WhereRelationCount("Tags", "=", 0)
+
+		Expect(selectQueryString(q)).To(Equal(`SELECT "id" FROM "rel_count_posts" AS "rel_count_post" WHERE ((SELECT count(*) FROM "rel_count_post_tags" AS "rel_count_post__tags" WHERE "rel_count_post__tags"."rel_count_post_id" = "rel_count_post"."id") = 0)`))
+	})
+
+	It("counts polymorphic relations", func() {
+		q := NewQuery(nil, (*RelCountPost)(nil)).
+			Column("id").
+			WhereRelationCount("Notes", ">=", 1)
+
+		Expect(selectQueryString(q)).To(Equal(`SELECT "id" FROM "rel_count_posts" AS "rel_count_post" WHERE ((SELECT count(*) FROM "rel_count_notes" AS "rel_count_post__notes" WHERE "rel_count_post__notes"."trackable_id" = "rel_count_post"."id" AND "rel_count_post__notes"."trackable_type" IN ('rel_count_post', 'RelCountPost')) >= 1)`))
+	})
+
+	It("returns an error for unknown relations and operators", func() {
+		q := NewQuery(nil, (*RelCountPost)(nil)).WhereRelationCount("Unknown", ">", 1)
+		_, err := NewSelectQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError(`model=RelCountPost does not have relation="Unknown"`))
+
+		q = NewQuery(nil, (*RelCountPost)(nil)).WhereRelationCount("Comments", "LIKE", 1)
+		_, err = NewSelectQuery(q).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError(`pg: WhereRelationCount(unsupported operator "LIKE")`))
+	})
+})
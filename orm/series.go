@@ -0,0 +1,191 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+// SeriesQuery is a generate_series call that can be used as a table
+// expression, e.g. in Join or TableExpr:
+//
+//    q.TableExpr("?", NewSeriesQuery(start, stop, "1 day").As("day"))
+//
+// generates
+//
+//    FROM generate_series('2020-01-01 00:00:00+00:00:00'::timestamptz,
+//    '2020-01-31 00:00:00+00:00:00'::timestamptz, '1 day'::interval) AS "day" ("day")
+//
+// Integer series accept integer bounds and step. Timestamp series accept
+// time.Time bounds and a step that is either an interval string or
+// a time.Duration.
+type SeriesQuery struct {
+	start, stop, step interface{}
+	typ               string
+	alias             string
+	ordinality        bool
+	err               error
+}
+
+var _ QueryAppender = (*SeriesQuery)(nil)
+
+// NewSeriesQuery returns a generate_series call for the bounds and step.
+// The step can be nil for integer series to use the default step 1.
+func NewSeriesQuery(start, stop, step interface{}) *SeriesQuery {
+	q := &SeriesQuery{
+		start: start,
+		stop:  stop,
+		step:  step,
+	}
+	q.err = q.init()
+	return q
+}
+
+func (q *SeriesQuery) init() error {
+	switch start := q.start.(type) {
+	case time.Time:
+		if _, ok := q.stop.(time.Time); !ok {
+			return fmt.Errorf("pg: GenerateSeries stop must be time.Time, got %T", q.stop)
+		}
+		q.typ = "timestamptz"
+
+		switch step := q.step.(type) {
+		case string:
+			if step == "" {
+				return errors.New("pg: GenerateSeries requires a non-empty step interval")
+			}
+		case time.Duration:
+			if step == 0 {
+				return errors.New("pg: GenerateSeries step can't be zero")
+			}
+			q.step = durationInterval(step)
+		default:
+			return fmt.Errorf(
+				"pg: GenerateSeries step must be string or time.Duration, got %T", q.step)
+		}
+		return nil
+	default:
+		if !isIntegerValue(start) {
+			return fmt.Errorf("pg: GenerateSeries(unsupported %T)", q.start)
+		}
+		if !isIntegerValue(q.stop) {
+			return fmt.Errorf("pg: GenerateSeries stop must be an integer, got %T", q.stop)
+		}
+		if q.step == nil {
+			return nil
+		}
+		if !isIntegerValue(q.step) {
+			return fmt.Errorf("pg: GenerateSeries step must be an integer, got %T", q.step)
+		}
+		if isZeroInteger(q.step) {
+			return errors.New("pg: GenerateSeries step can't be zero")
+		}
+		return nil
+	}
+}
+
+// As sets the alias of the series. The generated column has the same name
+// so it can be referenced both as alias and alias.alias.
+func (q *SeriesQuery) As(alias string) *SeriesQuery {
+	q.alias = alias
+	return q
+}
+
+// WithOrdinality adds the ordinality column numbering the generated rows
+// starting from 1. The column is named ordinality.
+func (q *SeriesQuery) WithOrdinality() *SeriesQuery {
+	q.ordinality = true
+	return q
+}
+
+func (q *SeriesQuery) AppendQuery(fmter QueryFormatter, b []byte) ([]byte, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	isPlaceholder := isTemplateFormatter(fmter)
+
+	b = append(b, "generate_series("...)
+	b = q.appendArg(b, q.start, q.typ, isPlaceholder)
+	b = append(b, ", "...)
+	b = q.appendArg(b, q.stop, q.typ, isPlaceholder)
+	if q.step != nil {
+		b = append(b, ", "...)
+		if q.typ != "" {
+			b = q.appendArg(b, q.step, "interval", isPlaceholder)
+		} else {
+			b = q.appendArg(b, q.step, "", isPlaceholder)
+		}
+	}
+	b = append(b, ')')
+	b = appendFuncTableAlias(b, q.alias, q.ordinality)
+
+	return b, nil
+}
+
+func (q *SeriesQuery) appendArg(b []byte, v interface{}, typ string, isPlaceholder bool) []byte {
+	if isPlaceholder {
+		b = append(b, '?')
+	} else {
+		b = types.Append(b, v, 1)
+	}
+	if typ != "" {
+		b = append(b, "::"...)
+		b = append(b, typ...)
+	}
+	return b
+}
+
+// appendFuncTableAlias appends WITH ORDINALITY and the alias of the
+// function table expression. The value column is named after the alias.
+func appendFuncTableAlias(b []byte, alias string, ordinality bool) []byte {
+	if ordinality {
+		b = append(b, " WITH ORDINALITY"...)
+	}
+	if alias == "" {
+		return b
+	}
+
+	b = append(b, " AS "...)
+	b = types.AppendIdent(b, alias, 1)
+	b = append(b, " ("...)
+	b = types.AppendIdent(b, alias, 1)
+	if ordinality {
+		b = append(b, `, "ordinality"`...)
+	}
+	b = append(b, ')')
+	return b
+}
+
+func isIntegerValue(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isZeroInteger(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint() == 0
+	default:
+		return rv.Int() == 0
+	}
+}
+
+func durationInterval(d time.Duration) string {
+	if d%time.Second == 0 {
+		return strconv.FormatInt(int64(d/time.Second), 10) + " seconds"
+	}
+	return strconv.FormatInt(int64(d/time.Microsecond), 10) + " microseconds"
+}
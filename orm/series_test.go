@@ -0,0 +1,72 @@
+package orm
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Series", func() {
+	It("generates a daily date spine", func() {
+		start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+		stop := start.AddDate(0, 0, 6)
+
+		q := NewQuery(nil).
+			ColumnExpr("day::date").
+			TableExpr("?", NewSeriesQuery(start, stop, "1 day").As("day")).
+			Order("day")
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT day::date FROM generate_series('2020-01-01 00:00:00+00:00:00'::timestamptz, '2020-01-07 00:00:00+00:00:00'::timestamptz, '1 day'::interval) AS "day" ("day") ORDER BY "day"`))
+	})
+
+	It("joins an integer series", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			Column("n").
+			Join("LEFT JOIN ? ON n = select_model.id", NewSeriesQuery(1, 10, 2).As("n"))
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "n" FROM "select_models" AS "select_model" LEFT JOIN generate_series(1, 10, 2) AS "n" ("n") ON n = select_model.id`))
+	})
+
+	It("converts time.Duration step to interval", func() {
+		start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		b, err := NewSeriesQuery(start, start.Add(time.Hour), 15*time.Minute).AppendQuery(defaultFmter, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(`generate_series('2020-01-01 00:00:00+00:00:00'::timestamptz, '2020-01-01 01:00:00+00:00:00'::timestamptz, '900 seconds'::interval)`))
+	})
+
+	It("omits nil integer step", func() {
+		b, err := NewSeriesQuery(int64(1), uint(3), nil).AppendQuery(defaultFmter, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(`generate_series(1, 3)`))
+	})
+
+	It("supports WITH ORDINALITY", func() {
+		b, err := NewSeriesQuery(10, 30, 10).As("n").WithOrdinality().AppendQuery(defaultFmter, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(`generate_series(10, 30, 10) WITH ORDINALITY AS "n" ("n", "ordinality")`))
+	})
+
+	It("returns an error for invalid arguments", func() {
+		now := time.Now()
+		for _, test := range []struct {
+			start, stop, step interface{}
+			err               string
+		}{
+			{"a", "b", nil, "pg: GenerateSeries(unsupported string)"},
+			{1, now, nil, "pg: GenerateSeries stop must be an integer, got time.Time"},
+			{1, 10, "1", "pg: GenerateSeries step must be an integer, got string"},
+			{1, 10, 0, "pg: GenerateSeries step can't be zero"},
+			{now, 10, "1 day", "pg: GenerateSeries stop must be time.Time, got int"},
+			{now, now, nil, "pg: GenerateSeries step must be string or time.Duration, got <nil>"},
+			{now, now, "", "pg: GenerateSeries requires a non-empty step interval"},
+			{now, now, time.Duration(0), "pg: GenerateSeries step can't be zero"},
+		} {
+			_, err := NewSeriesQuery(test.start, test.stop, test.step).AppendQuery(defaultFmter, nil)
+			Expect(err).To(MatchError(test.err))
+		}
+	})
+})
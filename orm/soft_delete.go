@@ -0,0 +1,36 @@
+package orm
+
+import "context"
+
+type allWithDeletedKey struct{}
+
+// WithAllDeleted returns a copy of the context that disables filtering
+// of soft deleted rows, e.g. for admin views. It applies to all queries
+// that use the context and to their relations, as if AllWithDeleted were
+// called on every query. Soft delete filtering is the only default
+// filter applied by the ORM.
+//
+// Deleted on a query takes precedence over the context. Relations can
+// be filtered explicitly using the apply function:
+//
+//    ctx = orm.WithAllDeleted(ctx)
+//    err := db.ModelContext(ctx, &authors).
+//    	Relation("Books", func(q *orm.Query) (*orm.Query, error) {
+//    		return q.Where("book.deleted_at IS NULL"), nil
+//    	}).
+//    	Select()
+//
+// Delete still soft deletes models, but does not skip rows that are
+// already soft deleted.
+func WithAllDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allWithDeletedKey{}, true)
+}
+
+// IsAllWithDeleted reports whether the context was created by WithAllDeleted.
+func IsAllWithDeleted(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	v, _ := ctx.Value(allWithDeletedKey{}).(bool)
+	return v
+}
@@ -33,6 +33,7 @@ const (
 	beforeDeleteHookFlag
 	afterDeleteHookFlag
 	discardUnknownColumnsFlag
+	looseColumnsFlag
 )
 
 var (
@@ -41,6 +42,9 @@ var (
 	sqlNullTimeType    = reflect.TypeOf((*sql.NullTime)(nil)).Elem()
 	ipType             = reflect.TypeOf((*net.IP)(nil)).Elem()
 	ipNetType          = reflect.TypeOf((*net.IPNet)(nil)).Elem()
+	inetType           = reflect.TypeOf((*types.Inet)(nil)).Elem()
+	cidrType           = reflect.TypeOf((*types.CIDR)(nil)).Elem()
+	numericType        = reflect.TypeOf((*types.Numeric)(nil)).Elem()
 	scannerType        = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
 	nullBoolType       = reflect.TypeOf((*sql.NullBool)(nil)).Elem()
 	nullFloatType      = reflect.TypeOf((*sql.NullFloat64)(nil)).Elem()
@@ -82,13 +86,27 @@ type Table struct {
 	fieldsMapMu sync.RWMutex
 	FieldsMap   map[string]*Field
 
+	aliasFieldsOnce sync.Once
+	aliasFields     map[string]*Field // see fieldByAlias
+
 	Methods   map[string]*Method
 	Relations map[string]*Relation
 	Unique    map[string][]*Field
+	Exclude   map[string]*ExcludeConstraint
+
+	// UniqueDeferrable is e.g. DEFERRABLE INITIALLY IMMEDIATE
+	// for the names in Unique declared as deferrable.
+	UniqueDeferrable map[string]string
 
 	SoftDeleteField    *Field
 	SetSoftDeleteField func(fv reflect.Value) error
 
+	VersionField *Field
+
+	AutoNowFields    []*Field // set to the current time on insert and update
+	AutoNowAddFields []*Field // set to the current time on insert
+	UUIDFields       []*Field // set to a random UUID on insert when zero
+
 	flags uint16
 }
 
@@ -175,6 +193,25 @@ func (t *Table) checkPKs() error {
 	return nil
 }
 
+// hasZeroPKs reports whether all primary keys of the struct have zero
+// values. It returns an error when only some of the primary keys are set.
+func (t *Table) hasZeroPKs(strct reflect.Value) (bool, error) {
+	var numZero int
+	for _, f := range t.PKs {
+		if f.HasZeroValue(strct) {
+			numZero++
+		}
+	}
+	switch numZero {
+	case 0:
+		return false, nil
+	case len(t.PKs):
+		return true, nil
+	default:
+		return false, fmt.Errorf("pg: %s has partially set primary keys", t)
+	}
+}
+
 func (t *Table) mustSoftDelete() error {
 	if t.SoftDeleteField == nil {
 		return fmt.Errorf("pg: %s does not support soft deletes", t)
@@ -218,6 +255,41 @@ func (t *Table) getField(name string) *Field {
 	return field
 }
 
+// fieldByAlias returns the field that matches the column alias ignoring
+// case and underscores, e.g. avgPrice, "AvgPrice", and avg_price all match
+// the field AvgPrice. It is used for computed columns that don't match
+// a field exactly in models tagged with loose_columns. Ambiguous aliases
+// don't match any field.
+func (t *Table) fieldByAlias(name string) *Field {
+	t.aliasFieldsOnce.Do(func() {
+		t.aliasFields = make(map[string]*Field, len(t.Fields))
+		for _, f := range t.Fields {
+			key := normalizeAlias(f.SQLName)
+			if _, ok := t.aliasFields[key]; ok {
+				t.aliasFields[key] = nil
+				continue
+			}
+			t.aliasFields[key] = f
+		}
+	})
+	return t.aliasFields[normalizeAlias(name)]
+}
+
+func normalizeAlias(s string) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_':
+			continue
+		case c >= 'A' && c <= 'Z':
+			c += 'a' - 'A'
+		}
+		b = append(b, c)
+	}
+	return internal.BytesToString(b)
+}
+
 func (t *Table) HasField(name string) bool {
 	_, ok := t.FieldsMap[name]
 	return ok
@@ -332,10 +404,14 @@ func (t *Table) newField(f reflect.StructField, index []int) *Field {
 			t.PartitionBy = s
 		}
 
-		if pgTag.Name == "_" {
+		name := pgTag.Name
+		if v, ok := pgTag.Options["table"]; ok && name == "" {
+			name = v
+		}
+		if name == "_" {
 			t.setName("")
-		} else if pgTag.Name != "" {
-			s, _ := tagparser.Unquote(pgTag.Name)
+		} else if name != "" {
+			s, _ := tagparser.Unquote(name)
 			t.setName(types.Safe(quoteTableName(s)))
 		}
 
@@ -353,6 +429,9 @@ func (t *Table) newField(f reflect.StructField, index []int) *Field {
 		if _, ok := pgTag.Options["discard_unknown_columns"]; ok {
 			t.setFlag(discardUnknownColumnsFlag)
 		}
+		if _, ok := pgTag.Options["loose_columns"]; ok {
+			t.setFlag(looseColumnsFlag)
+		}
 
 		return nil
 	}
@@ -398,6 +477,8 @@ func (t *Table) newField(f reflect.StructField, index []int) *Field {
 		Column:  quoteIdent(sqlName),
 
 		Index: index,
+
+		options: pgTag.Options,
 	}
 
 	if _, ok := pgTag.Options["notnull"]; ok {
@@ -410,14 +491,42 @@ func (t *Table) newField(f reflect.StructField, index []int) *Field {
 		// Split the value by comma, this will allow multiple names to be specified.
 		// We can use this to create multiple named unique constraints where a single column
 		// might be included in multiple constraints.
+		// A name can be followed by the deferrable mode, e.g. `pg:"unique:pos:deferrable"`.
 		v, _ = tagparser.Unquote(v)
 		for _, uniqueName := range strings.Split(v, ",") {
+			if i := strings.IndexByte(uniqueName, ':'); i >= 0 {
+				var mode string
+				uniqueName, mode = uniqueName[:i], uniqueName[i+1:]
+				t.setUniqueDeferrable(field, uniqueName, mode)
+			}
 			if t.Unique == nil {
 				t.Unique = make(map[string][]*Field)
 			}
 			t.Unique[uniqueName] = append(t.Unique[uniqueName], field)
 		}
 	}
+	if v, ok := pgTag.Options["exclude"]; ok {
+		// The value is the constraint name optionally followed by
+		// the operator, e.g. `pg:"exclude:no_overlap:&&"`. Fields with
+		// the same name are combined into one constraint.
+		v, _ = tagparser.Unquote(v)
+		name, op := v, "="
+		if i := strings.IndexByte(v, ':'); i >= 0 {
+			name, op = v[:i], v[i+1:]
+		}
+		if t.Exclude == nil {
+			t.Exclude = make(map[string]*ExcludeConstraint)
+		}
+		c, ok := t.Exclude[name]
+		if !ok {
+			c = &ExcludeConstraint{Name: name}
+			t.Exclude[name] = c
+		}
+		c.Elements = append(c.Elements, ExcludeElement{
+			Column:   sqlName,
+			Operator: op,
+		})
+	}
 	if v, ok := pgTag.Options["default"]; ok {
 		v, ok = tagparser.Unquote(v)
 		if ok {
@@ -455,16 +564,48 @@ func (t *Table) newField(f reflect.StructField, index []int) *Field {
 	}
 
 	if v, ok := pgTag.Options["on_delete"]; ok {
-		field.OnDelete = v
+		field.OnDelete = referentialAction(field, "on_delete", v)
 	}
 
 	if v, ok := pgTag.Options["on_update"]; ok {
-		field.OnUpdate = v
+		field.OnUpdate = referentialAction(field, "on_update", v)
+	}
+
+	if v, ok := pgTag.Options["deferrable"]; ok {
+		switch v {
+		case "", "deferred":
+			field.Deferrable = "DEFERRABLE INITIALLY DEFERRED"
+		case "immediate":
+			field.Deferrable = "DEFERRABLE INITIALLY IMMEDIATE"
+		default:
+			panic(fmt.Errorf("pg: %s has unsupported deferrable=%q", field.GoName, v))
+		}
+	}
+
+	if v, ok := pgTag.Options["identity"]; ok {
+		switch v {
+		case "", "by_default":
+			field.Identity = "GENERATED BY DEFAULT AS IDENTITY"
+		case "always":
+			field.Identity = "GENERATED ALWAYS AS IDENTITY"
+		default:
+			panic(fmt.Errorf("pg: %s has unsupported identity=%q", field.GoName, v))
+		}
 	}
 
-	if _, ok := pgTag.Options["composite"]; ok {
+	if _, ok := pgTag.Options["composite"]; ok && isCompositeArray(field) {
+		field.append = compositeArrayAppender(f.Type)
+		field.scan = compositeArrayScanner(f.Type)
+	} else if ok {
 		field.append = compositeAppender(f.Type)
 		field.scan = compositeScanner(f.Type)
+	} else if _, ok := pgTag.Options["json"]; ok {
+		field.append = appendJSONFieldValue
+		if _, ok := pgTag.Options["json_use_number"]; ok {
+			field.scan = scanJSONValue
+		} else {
+			field.scan = scanJSONValueNoNumber
+		}
 	} else if _, ok := pgTag.Options["json_use_number"]; ok {
 		field.append = types.Appender(f.Type)
 		field.scan = scanJSONValue
@@ -484,11 +625,42 @@ func (t *Table) newField(f reflect.StructField, index []int) *Field {
 	} else if _, ok := pgTag.Options["msgpack"]; ok {
 		field.append = msgpackAppender(f.Type)
 		field.scan = msgpackScanner(f.Type)
+	} else if field.SQLType == pgTypeUUID && isUUIDArray(f.Type) {
+		field.append = appendUUIDValue
+		field.scan = scanUUIDValue
+	} else if v, ok := pgTag.Options["bool_type"]; ok {
+		v, _ = tagparser.Unquote(v)
+		bt, err := getBoolType(f.Type, v)
+		if err != nil {
+			panic(fmt.Errorf("pg: %s.%s: %s", t.TypeName, f.Name, err))
+		}
+		field.append = bt.appender()
+		field.scan = bt.scanner()
+		// false is stored as e.g. 'N' and not as NULL.
+		if f.Type.Kind() != reflect.Ptr {
+			field.setFlag(UseZeroFlag)
+		}
+	} else if isTimeType(indirectType(f.Type)) && isTimestamp(field.SQLType) {
+		field.append = appendWallTimeValue
+		field.scan = types.Scanner(f.Type)
 	} else {
 		field.append = types.Appender(f.Type)
 		field.scan = types.Scanner(f.Type)
 	}
-	field.isZero = zerochecker.Checker(f.Type)
+	if v, ok := pgTag.Options["default_on_null"]; ok {
+		v, _ = tagparser.Unquote(v)
+		scan, err := defaultOnNullScanner(f.Type, field.scan, v)
+		if err != nil {
+			panic(fmt.Errorf("pg: %s.%s: %s", t.TypeName, f.Name, err))
+		}
+		field.scan = scan
+	}
+	if _, ok := pgTag.Options["omitempty"]; ok {
+		// Empty structs and pointers to them are stored as NULL too.
+		field.isZero = isEmptyValue
+	} else {
+		field.isZero = zerochecker.Checker(f.Type)
+	}
 
 	if v, ok := pgTag.Options["alias"]; ok {
 		v, _ = tagparser.Unquote(v)
@@ -502,6 +674,14 @@ func (t *Table) newField(f reflect.StructField, index []int) *Field {
 		return nil
 	}
 
+	// Scan-only fields, e.g. computed columns added with ColumnExpr, are not
+	// selected, inserted, updated or created, but are scanned when the query
+	// returns the column. When the column is absent the field is left as is.
+	if _, ok := pgTag.Options["scanonly"]; ok {
+		t.FieldsMap[field.SQLName] = field
+		return nil
+	}
+
 	if _, ok := pgTag.Options["soft_delete"]; ok {
 		t.SetSoftDeleteField = setSoftDeleteFieldFunc(f.Type)
 		if t.SetSoftDeleteField == nil {
@@ -512,6 +692,45 @@ func (t *Table) newField(f reflect.StructField, index []int) *Field {
 		t.SoftDeleteField = field
 	}
 
+	// Version field is used for optimistic locking: updates of struct models
+	// increment it and only match the row with the current version.
+	if _, ok := pgTag.Options["version"]; ok {
+		switch f.Type.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			panic(fmt.Errorf("pg: version is only supported for integer fields, got %s", f.Type))
+		}
+		field.setFlag(UseZeroFlag)
+		t.VersionField = field
+	}
+
+	_, autoNow := pgTag.Options["auto_now"]
+	_, autoNowAdd := pgTag.Options["auto_now_add"]
+	if _, ok := pgTag.Options["generate"]; ok {
+		field.setUUID = setUUIDFieldFunc(f.Type)
+		if field.SQLType != pgTypeUUID || field.setUUID == nil {
+			panic(fmt.Errorf(
+				"pg: %s.%s: generate is only supported for uuid fields of type string or [16]byte",
+				t.TypeName, f.Name))
+		}
+		t.UUIDFields = append(t.UUIDFields, field)
+	}
+
+	if autoNow || autoNowAdd {
+		field.setNow = setSoftDeleteFieldFunc(f.Type)
+		if field.setNow == nil {
+			err := fmt.Errorf(
+				"pg: auto_now and auto_now_add are only supported for time.Time, pg.NullTime, sql.NullInt64, and int64 (or implement ValueScanner that scans time)")
+			panic(err)
+		}
+		if autoNow {
+			t.AutoNowFields = append(t.AutoNowFields, field)
+		} else {
+			t.AutoNowAddFields = append(t.AutoNowAddFields, field)
+		}
+	}
+
 	return field
 }
 
@@ -563,6 +782,41 @@ func (t *Table) initRelations() {
 	}
 }
 
+func (t *Table) setUniqueDeferrable(field *Field, name, mode string) {
+	var deferrable string
+	switch mode {
+	case "deferrable", "immediate":
+		deferrable = "DEFERRABLE INITIALLY IMMEDIATE"
+	case "deferred":
+		deferrable = "DEFERRABLE INITIALLY DEFERRED"
+	default:
+		panic(fmt.Errorf("pg: %s has unsupported unique deferrable mode=%q", field.GoName, mode))
+	}
+	if t.UniqueDeferrable == nil {
+		t.UniqueDeferrable = make(map[string]string)
+	}
+	t.UniqueDeferrable[name] = deferrable
+}
+
+// referentialAction normalizes ON DELETE and ON UPDATE actions,
+// e.g. "set null" is SET NULL. SET NULL and SET DEFAULT can be followed
+// by a column list.
+func referentialAction(field *Field, option, v string) string {
+	v, _ = tagparser.Unquote(strings.TrimSpace(v))
+	s := strings.Join(strings.Fields(v), " ")
+	action := strings.ToUpper(s)
+	switch action {
+	case "NO ACTION", "RESTRICT", "CASCADE", "SET NULL", "SET DEFAULT":
+		return action
+	}
+	for _, prefix := range []string{"SET NULL (", "SET DEFAULT ("} {
+		if strings.HasPrefix(action, prefix) && strings.HasSuffix(action, ")") {
+			return prefix + s[len(prefix):]
+		}
+	}
+	panic(fmt.Errorf("pg: %s has unsupported %s=%q", field.GoName, option, v))
+}
+
 func (t *Table) tryRelation(field *Field) bool {
 	pgTag := tagparser.Parse(field.Field.Tag.Get("pg"))
 
@@ -754,6 +1008,22 @@ func (t *Table) mustHasManyRelation(field *Field, pgTag *tagparser.Tag) bool {
 	}
 
 	joinTable := _tables.get(indirectType(field.Type.Elem()), true)
+
+	if on, ok := pgTag.Options["join"]; ok {
+		on, _ = tagparser.Unquote(on)
+		if on == "" {
+			panic(fmt.Errorf("pg: %s has-many %s: join condition is empty", t.TypeName, field.GoName))
+		}
+		t.addRelation(&Relation{
+			Type:      HasManyRelation,
+			Field:     field,
+			JoinTable: joinTable,
+			BaseFKs:   t.PKs,
+			JoinOn:    on,
+		})
+		return true
+	}
+
 	fkPrefix, fkOK := pgTag.Options["join_fk"]
 	_, polymorphic := pgTag.Options["polymorphic"]
 
@@ -1201,16 +1471,42 @@ func isScanner(typ reflect.Type) bool {
 func fieldSQLType(field *Field, pgTag *tagparser.Tag) string {
 	if typ, ok := pgTag.Options["type"]; ok {
 		typ, _ = tagparser.Unquote(typ)
+		// The type of array fields can be given as the element type,
+		// e.g. pg:",array,type:varchar(20)" is varchar(20)[].
+		if field.hasFlag(ArrayFlag) && !strings.HasSuffix(typ, "[]") {
+			switch indirectType(field.Type).Kind() {
+			case reflect.Slice, reflect.Array:
+				typ += "[]"
+			}
+		}
 		field.UserSQLType = typ
 		typ = normalizeSQLType(typ)
 		return typ
 	}
 
+	if _, ok := pgTag.Options["uuid"]; ok {
+		return pgTypeUUID
+	}
+
+	if v, ok := pgTag.Options["bool_type"]; ok {
+		v, _ = tagparser.Unquote(v)
+		if bt, ok := boolTypes[v]; ok {
+			return bt.sqlType
+		}
+	}
+
 	if typ, ok := pgTag.Options["composite"]; ok {
 		typ, _ = tagparser.Unquote(typ)
+		if isCompositeArray(field) && !strings.HasSuffix(typ, "[]") {
+			typ += "[]"
+		}
 		return typ
 	}
 
+	if _, ok := pgTag.Options["json"]; ok {
+		return pgTypeJSONB
+	}
+
 	if _, ok := pgTag.Options["hstore"]; ok {
 		return "hstore"
 	} else if _, ok := pgTag.Options["hstore"]; ok {
@@ -1229,14 +1525,26 @@ func fieldSQLType(field *Field, pgTag *tagparser.Tag) string {
 	return sqlType
 }
 
+// isCompositeArray reports whether the field is a slice or an array of composite
+// values, for example, []Address stored as address[].
+func isCompositeArray(field *Field) bool {
+	switch indirectType(field.Type).Kind() {
+	case reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
 func sqlType(typ reflect.Type) string {
 	switch typ {
 	case timeType, nullTimeType, sqlNullTimeType:
-		return pgTypeTimestampTz
-	case ipType:
+		return defaultTimeSQLType
+	case ipType, inetType:
 		return pgTypeInet
-	case ipNetType:
+	case ipNetType, cidrType:
 		return pgTypeCidr
+	case numericType:
+		return pgTypeNumeric
 	case nullBoolType:
 		return pgTypeBoolean
 	case nullFloatType:
@@ -1422,6 +1730,16 @@ func foreignKeys(base, join *Table, fk string, tryFK bool) []*Field {
 }
 
 func scanJSONValue(v reflect.Value, rd types.Reader, n int) error {
+	return scanJSON(v, rd, n, true)
+}
+
+func scanJSONValueNoNumber(v reflect.Value, rd types.Reader, n int) error {
+	return scanJSON(v, rd, n, false)
+}
+
+// scanJSON decodes JSON into the value. NULL sets pointers to nil and
+// other values to zero.
+func scanJSON(v reflect.Value, rd types.Reader, n int, useNumber bool) error {
 	// Zero value so it works with SelectOrInsert.
 	// TODO: better handle slices
 	v.Set(reflect.New(v.Type()).Elem())
@@ -1431,10 +1749,29 @@ func scanJSONValue(v reflect.Value, rd types.Reader, n int) error {
 	}
 
 	dec := pgjson.NewDecoder(rd)
-	dec.UseNumber()
+	if useNumber {
+		dec.UseNumber()
+	}
 	return dec.Decode(v.Addr().Interface())
 }
 
+// appendJSONFieldValue encodes the value as JSON regardless of its type.
+// Nil pointers, maps, slices, and interfaces are appended as NULL.
+func appendJSONFieldValue(b []byte, v reflect.Value, flags int) []byte {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if v.IsNil() {
+			return types.AppendNull(b, flags)
+		}
+	}
+
+	bb, err := pgjson.Marshal(v.Interface())
+	if err != nil {
+		return types.AppendError(b, err)
+	}
+	return types.AppendJSONB(b, bb, flags)
+}
+
 func appendUintAsInt(b []byte, v reflect.Value, _ int) []byte {
 	return strconv.AppendInt(b, int64(v.Uint()), 10)
 }
@@ -1459,13 +1796,100 @@ func quoteTableName(s string) types.Safe {
 		strings.IndexByte(s, '(') >= 0 && strings.IndexByte(s, ')') >= 0 {
 		return types.Safe(s)
 	}
+	if strings.IndexByte(s, '"') >= 0 {
+		if b, ok := appendQuotedTableName(nil, s); ok {
+			return types.Safe(b)
+		}
+	}
 	return quoteIdent(s)
 }
 
+// appendQuotedTableName quotes every part of the schema-qualified table name
+// keeping parts that are already quoted, e.g. "Remote Schema".users.
+func appendQuotedTableName(b []byte, s string) ([]byte, bool) {
+	for {
+		if s != "" && s[0] == '"' {
+			end := closingQuote(s)
+			if end == -1 {
+				return nil, false
+			}
+			b = append(b, s[:end+1]...)
+			s = s[end+1:]
+		} else {
+			end := strings.IndexByte(s, '.')
+			if end == -1 {
+				end = len(s)
+			}
+			if end == 0 || strings.IndexByte(s[:end], '"') >= 0 {
+				return nil, false
+			}
+			b = types.AppendIdent(b, s[:end], 1)
+			s = s[end:]
+		}
+
+		if s == "" {
+			return b, true
+		}
+		if s[0] != '.' {
+			return nil, false
+		}
+		b = append(b, '.')
+		s = s[1:]
+	}
+}
+
+// closingQuote returns the index of the quote that closes the quoted
+// identifier at the start of s or -1.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] != '"' {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '"' {
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
 func quoteIdent(s string) types.Safe {
 	return types.Safe(types.AppendIdent(nil, s, 1))
 }
 
+func (t *Table) setUUIDs(strct reflect.Value) error {
+	for _, f := range t.UUIDFields {
+		if !f.HasZeroValue(strct) {
+			continue
+		}
+		if err := f.setUUID(f.Value(strct)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Table) setAutoNow(strct reflect.Value, insert bool) error {
+	for _, f := range t.AutoNowFields {
+		if err := f.setNow(f.Value(strct)); err != nil {
+			return err
+		}
+	}
+	if !insert {
+		return nil
+	}
+	for _, f := range t.AutoNowAddFields {
+		if !f.HasZeroValue(strct) {
+			continue
+		}
+		if err := f.setNow(f.Value(strct)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func setSoftDeleteFieldFunc(typ reflect.Type) func(fv reflect.Value) error {
 	switch typ {
 	case timeType:
@@ -1538,9 +1962,87 @@ func setSoftDeleteFallbackFunc(typ reflect.Type) func(fv reflect.Value) error {
 	}
 }
 
+// defaultOnNullScanner returns a scanner that scans the default instead of
+// NULL. The default is parsed according to the field type: bool accepts
+// strconv.ParseBool values, numbers are parsed with strconv, time.Time
+// accepts PostgreSQL timestamps, and strings are used as is. Empty default
+// scans the zero value and allocates pointer fields.
+func defaultOnNullScanner(
+	typ reflect.Type, scan types.ScannerFunc, value string,
+) (types.ScannerFunc, error) {
+	b, err := parseDefaultOnNull(indirectType(typ), value)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(fv reflect.Value, rd types.Reader, n int) error {
+		if n != -1 {
+			return scan(fv, rd, n)
+		}
+		if b != nil {
+			return scan(fv, pool.NewBytesReader(b), len(b))
+		}
+		if fv.Kind() == reflect.Ptr {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		} else {
+			fv.Set(reflect.Zero(fv.Type()))
+		}
+		return nil
+	}, nil
+}
+
+func parseDefaultOnNull(typ reflect.Type, value string) ([]byte, error) {
+	kind := typ.Kind()
+	if typ == timeType {
+		kind = reflect.Struct
+	} else if !isDefaultOnNullKind(kind) {
+		return nil, fmt.Errorf("default_on_null is not supported for %s", typ)
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	var err error
+	switch kind {
+	case reflect.Struct:
+		_, err = types.ParseTimeString(value)
+	case reflect.Bool:
+		var flag bool
+		flag, err = strconv.ParseBool(value)
+		if err == nil {
+			value = "f"
+			if flag {
+				value = "t"
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(value, 10, typ.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(value, 10, typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(value, typ.Bits())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid default_on_null %q: %s", value, err)
+	}
+	return []byte(value), nil
+}
+
+func isDefaultOnNullKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
 func isKnownTableOption(name string) bool {
 	switch name {
 	case "alias",
+		"table",
 		"select",
 		"tablespace",
 		"partition_by",
@@ -1557,20 +2059,34 @@ func isKnownFieldOption(name string) bool {
 		"array",
 		"hstore",
 		"composite",
+		"json",
 		"json_use_number",
+		"omitempty",
 		"msgpack",
 		"notnull",
 		"use_zero",
 		"default",
+		"default_on_null",
+		"bool_type",
+		"uuid",
+		"generate",
 		"unique",
+		"exclude",
+		"scanonly",
 		"soft_delete",
+		"version",
+		"auto_now",
+		"auto_now_add",
 		"on_delete",
 		"on_update",
+		"deferrable",
+		"identity",
 
 		"pk",
 		"nopk",
 		"rel",
 		"fk",
+		"join",
 		"join_fk",
 		"many2many",
 		"polymorphic":
@@ -0,0 +1,118 @@
+package orm
+
+import (
+	"errors"
+)
+
+const tableColumnsQuery = `
+SELECT c.column_name
+FROM information_schema.columns AS c
+JOIN pg_namespace AS n ON n.nspname = c.table_schema
+JOIN pg_class AS t ON t.relnamespace = n.oid AND t.relname = c.table_name
+WHERE t.oid = ?::regclass
+`
+
+// AddColumnsQuery adds the model columns to the existing table:
+//
+//    ALTER TABLE "books" ADD COLUMN IF NOT EXISTS "isbn" text,
+//    ADD COLUMN IF NOT EXISTS "pages" bigint NOT NULL DEFAULT 0
+//
+// Columns are defined the same way as in CreateTable.
+type AddColumnsQuery struct {
+	q       *Query
+	columns []string
+}
+
+var (
+	_ QueryAppender = (*AddColumnsQuery)(nil)
+	_ QueryCommand  = (*AddColumnsQuery)(nil)
+)
+
+// NewAddColumnsQuery returns a query that adds the model columns with the
+// given SQL names.
+func NewAddColumnsQuery(q *Query, columns []string) *AddColumnsQuery {
+	return &AddColumnsQuery{
+		q:       q,
+		columns: columns,
+	}
+}
+
+func (q *AddColumnsQuery) String() string {
+	b, err := q.AppendQuery(defaultFmter, nil)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func (q *AddColumnsQuery) Operation() QueryOp {
+	return AddColumnsOp
+}
+
+func (q *AddColumnsQuery) Clone() QueryCommand {
+	return &AddColumnsQuery{
+		q:       q.q.Clone(),
+		columns: q.columns[:len(q.columns):len(q.columns)],
+	}
+}
+
+func (q *AddColumnsQuery) Query() *Query {
+	return q.q
+}
+
+func (q *AddColumnsQuery) AppendTemplate(b []byte) ([]byte, error) {
+	return q.AppendQuery(dummyFormatter{}, b)
+}
+
+func (q *AddColumnsQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err error) {
+	if q.q.stickyErr != nil {
+		return nil, q.q.stickyErr
+	}
+	if q.q.tableModel == nil {
+		return nil, errModelNil
+	}
+	if len(q.columns) == 0 {
+		return nil, errors.New("pg: AddColumns requires at least one column")
+	}
+
+	table := q.q.tableModel.Table()
+	ct := NewCreateTableQuery(q.q, nil)
+
+	b = append(b, "ALTER TABLE "...)
+	b, err = q.q.appendFirstTable(fmter, b)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, column := range q.columns {
+		field, err := table.GetField(column)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, " ADD COLUMN IF NOT EXISTS "...)
+		b = ct.appendColumnDef(b, field)
+	}
+
+	return b, q.q.stickyErr
+}
+
+// missingColumns returns the SQL names of the table fields that are not
+// in the existing columns.
+func missingColumns(table *Table, existing []string) []string {
+	set := make(map[string]struct{}, len(existing))
+	for _, column := range existing {
+		set[column] = struct{}{}
+	}
+
+	var columns []string
+	for _, f := range table.Fields {
+		if _, ok := set[f.SQLName]; !ok {
+			columns = append(columns, f.SQLName)
+		}
+	}
+	return columns
+}
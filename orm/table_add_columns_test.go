@@ -0,0 +1,41 @@
+package orm
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type AddColumnsModel struct {
+	ID    int
+	Title string
+	Isbn  string `pg:",unique"`
+	Pages int    `pg:",notnull,default:0"`
+}
+
+var _ = Describe("AddColumns", func() {
+	It("adds columns if not exist", func() {
+		q := NewQuery(nil, &AddColumnsModel{})
+
+		s := queryString(NewAddColumnsQuery(q, []string{"isbn", "pages"}))
+		Expect(s).To(Equal(`ALTER TABLE "add_columns_models" ADD COLUMN IF NOT EXISTS "isbn" text UNIQUE, ADD COLUMN IF NOT EXISTS "pages" bigint NOT NULL DEFAULT 0`))
+	})
+
+	It("finds missing columns", func() {
+		table := GetTable(reflect.TypeOf(AddColumnsModel{}))
+
+		Expect(missingColumns(table, []string{"id", "title", "dropped"})).To(Equal([]string{"isbn", "pages"}))
+		Expect(missingColumns(table, []string{"id", "title", "isbn", "pages"})).To(BeEmpty())
+	})
+
+	It("returns an error for unknown columns", func() {
+		q := NewQuery(nil, &AddColumnsModel{})
+
+		_, err := NewAddColumnsQuery(q, []string{"unknown"}).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: model=AddColumnsModel does not have column=unknown"))
+
+		_, err = NewAddColumnsQuery(q, nil).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: AddColumns requires at least one column"))
+	})
+})
@@ -1,8 +1,11 @@
 package orm
 
 import (
+	"errors"
+	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/go-pg/pg/v10/types"
 )
@@ -15,8 +18,39 @@ type CreateTableOptions struct {
 	// FKConstraints causes CreateTable to create foreign key constraints
 	// for has one relations. ON DELETE hook can be added using tag
 	// `pg:"on_delete:RESTRICT"` on foreign key field. ON UPDATE hook can be added using tag
-	// `pg:"on_update:CASCADE"`
+	// `pg:"on_update:CASCADE"`. Constraint can be made deferrable using tag
+	// `pg:",deferrable"` (INITIALLY DEFERRED) or `pg:",deferrable:immediate"`.
+	// The tags can also be set on the relation field, e.g.
+	// `pg:"rel:has-one,on_delete:CASCADE"`, and then take precedence.
+	// The foreign key of a belongs to relation is stored in the joined table,
+	// so its constraint is created with the has one relation of that table.
 	FKConstraints bool
+
+	// Exclude adds exclusion constraints in addition to the ones declared
+	// with the `pg:"exclude:name:operator"` field tag.
+	Exclude []ExcludeConstraint
+}
+
+// ExcludeConstraint is an EXCLUDE table constraint, e.g.
+//
+//    EXCLUDE USING gist ("room" WITH =, "during" WITH &&)
+//
+// that prevents overlapping bookings of a room. Using scalar columns such as
+// integers with = in a gist index requires the btree_gist extension, i.e.
+// CREATE EXTENSION btree_gist.
+type ExcludeConstraint struct {
+	// Name is the constraint name. It is optional.
+	Name string
+	// Using is the index method. Defaults to gist.
+	Using    string
+	Elements []ExcludeElement
+}
+
+// ExcludeElement is a column or an expression in parentheses compared
+// using the operator, e.g. "during" and "&&".
+type ExcludeElement struct {
+	Column   string
+	Operator string
 }
 
 type CreateTableQuery struct {
@@ -91,24 +125,15 @@ func (q *CreateTableQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte
 		if i > 0 {
 			b = append(b, ", "...)
 		}
-
-		b = append(b, field.Column...)
-		b = append(b, " "...)
-		b = q.appendSQLType(b, field)
-		if field.hasFlag(NotNullFlag) {
-			b = append(b, " NOT NULL"...)
-		}
-		if field.hasFlag(UniqueFlag) {
-			b = append(b, " UNIQUE"...)
-		}
-		if field.Default != "" {
-			b = append(b, " DEFAULT "...)
-			b = append(b, field.Default...)
-		}
+		b = q.appendColumnDef(b, field)
 	}
 
 	b = appendPKConstraint(b, table.PKs)
 	b = appendUniqueConstraints(b, table)
+	b, err = appendExcludeConstraints(b, table, q.opt)
+	if err != nil {
+		return nil, err
+	}
 
 	if q.opt != nil && q.opt.FKConstraints {
 		for _, rel := range table.Relations {
@@ -130,6 +155,27 @@ func (q *CreateTableQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte
 	return b, q.q.stickyErr
 }
 
+func (q *CreateTableQuery) appendColumnDef(b []byte, field *Field) []byte {
+	b = append(b, field.Column...)
+	b = append(b, " "...)
+	b = q.appendSQLType(b, field)
+	if field.Identity != "" {
+		b = append(b, ' ')
+		b = append(b, field.Identity...)
+	}
+	if field.hasFlag(NotNullFlag) {
+		b = append(b, " NOT NULL"...)
+	}
+	if field.hasFlag(UniqueFlag) {
+		b = append(b, " UNIQUE"...)
+	}
+	if field.Default != "" {
+		b = append(b, " DEFAULT "...)
+		b = append(b, field.Default...)
+	}
+	return b
+}
+
 func (q *CreateTableQuery) appendSQLType(b []byte, field *Field) []byte {
 	if field.UserSQLType != "" {
 		return append(b, field.UserSQLType...)
@@ -141,7 +187,7 @@ func (q *CreateTableQuery) appendSQLType(b []byte, field *Field) []byte {
 		b = append(b, ")"...)
 		return b
 	}
-	if field.hasFlag(PrimaryKeyFlag) {
+	if field.hasFlag(PrimaryKeyFlag) && field.Identity == "" {
 		return append(b, pkSQLType(field.SQLType)...)
 	}
 	return append(b, field.SQLType...)
@@ -179,6 +225,10 @@ func appendUniqueConstraints(b []byte, table *Table) []byte {
 
 	for _, key := range keys {
 		b = appendUnique(b, table.Unique[key])
+		if s := table.UniqueDeferrable[key]; s != "" {
+			b = append(b, ' ')
+			b = append(b, s...)
+		}
 	}
 
 	return b
@@ -191,6 +241,104 @@ func appendUnique(b []byte, fields []*Field) []byte {
 	return b
 }
 
+func appendExcludeConstraints(b []byte, table *Table, opt *CreateTableOptions) (_ []byte, err error) {
+	keys := make([]string, 0, len(table.Exclude))
+	for key := range table.Exclude {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		b, err = appendExclude(b, table.Exclude[key])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opt != nil {
+		for i := range opt.Exclude {
+			b, err = appendExclude(b, &opt.Exclude[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return b, nil
+}
+
+func appendExclude(b []byte, c *ExcludeConstraint) ([]byte, error) {
+	if len(c.Elements) == 0 {
+		return nil, errors.New("pg: exclusion constraint requires at least one element")
+	}
+
+	b = append(b, ", "...)
+	if c.Name != "" {
+		b = append(b, "CONSTRAINT "...)
+		b = types.AppendIdent(b, c.Name, 1)
+		b = append(b, ' ')
+	}
+
+	b = append(b, "EXCLUDE USING "...)
+	if c.Using != "" {
+		if !isIdentifier(c.Using) {
+			return nil, fmt.Errorf("pg: invalid exclusion constraint index method=%q", c.Using)
+		}
+		b = append(b, c.Using...)
+	} else {
+		b = append(b, "gist"...)
+	}
+
+	b = append(b, " ("...)
+	for i, el := range c.Elements {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+
+		if strings.HasPrefix(el.Column, "(") {
+			b = append(b, el.Column...)
+		} else {
+			b = types.AppendIdent(b, el.Column, 1)
+		}
+
+		if !isOperator(el.Operator) {
+			return nil, fmt.Errorf("pg: invalid exclusion constraint operator=%q", el.Operator)
+		}
+		b = append(b, " WITH "...)
+		b = append(b, el.Operator...)
+	}
+	b = append(b, ")"...)
+
+	return b, nil
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' && i > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// isOperator reports whether s consists of the characters allowed
+// in PostgreSQL operator names.
+func isOperator(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte("+-*/<>=~!@#%^&|`?", s[i]) == -1 {
+			return false
+		}
+	}
+	return true
+}
+
 func (q *CreateTableQuery) appendFKConstraint(fmter QueryFormatter, b []byte, rel *Relation) []byte {
 	if rel.Type != HasOneRelation {
 		return b
@@ -206,16 +354,21 @@ func (q *CreateTableQuery) appendFKConstraint(fmter QueryFormatter, b []byte, re
 	b = appendColumns(b, "", rel.JoinFKs)
 	b = append(b, ")"...)
 
-	if s := onDelete(rel.BaseFKs); s != "" {
+	if s := onDelete(rel); s != "" {
 		b = append(b, " ON DELETE "...)
 		b = append(b, s...)
 	}
 
-	if s := onUpdate(rel.BaseFKs); s != "" {
+	if s := onUpdate(rel); s != "" {
 		b = append(b, " ON UPDATE "...)
 		b = append(b, s...)
 	}
 
+	if s := deferrable(rel); s != "" {
+		b = append(b, ' ')
+		b = append(b, s...)
+	}
+
 	return b
 }
 
@@ -225,24 +378,40 @@ func (q *CreateTableQuery) appendTablespace(b []byte, tableSpace types.Safe) []b
 	return b
 }
 
-func onDelete(fks []*Field) string {
-	var onDelete string
-	for _, f := range fks {
+// onDelete returns the ON DELETE action of the relation. Options on the
+// relation field take precedence over options on the foreign key fields.
+func onDelete(rel *Relation) string {
+	if rel.Field.OnDelete != "" {
+		return rel.Field.OnDelete
+	}
+	for _, f := range rel.BaseFKs {
 		if f.OnDelete != "" {
-			onDelete = f.OnDelete
-			break
+			return f.OnDelete
 		}
 	}
-	return onDelete
+	return ""
 }
 
-func onUpdate(fks []*Field) string {
-	var onUpdate string
-	for _, f := range fks {
+func onUpdate(rel *Relation) string {
+	if rel.Field.OnUpdate != "" {
+		return rel.Field.OnUpdate
+	}
+	for _, f := range rel.BaseFKs {
 		if f.OnUpdate != "" {
-			onUpdate = f.OnUpdate
-			break
+			return f.OnUpdate
+		}
+	}
+	return ""
+}
+
+func deferrable(rel *Relation) string {
+	if rel.Field.Deferrable != "" {
+		return rel.Field.Deferrable
+	}
+	for _, f := range rel.BaseFKs {
+		if f.Deferrable != "" {
+			return f.Deferrable
 		}
 	}
-	return onUpdate
+	return ""
 }
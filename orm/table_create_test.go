@@ -3,8 +3,11 @@ package orm
 import (
 	"database/sql"
 	"encoding/json"
+	"reflect"
 	"time"
 
+	"github.com/go-pg/pg/v10/types"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -56,6 +59,73 @@ type CreateTableOnDeleteOnUpdateModel struct {
 	CreateTableModel   *CreateTableModel
 }
 
+type CreateTableRelOnDeleteModel struct {
+	ID                 int
+	CreateTableModelID int               `pg:"on_delete:RESTRICT"`
+	CreateTableModel   *CreateTableModel `pg:"rel:has-one,on_delete:set  null,on_update: cascade"`
+}
+
+type CreateTableIdentityModel struct {
+	ID      int64 `pg:",identity:always"`
+	Counter int   `pg:",identity"`
+}
+
+type CreateTableDeferrableUniqueModel struct {
+	ID       int
+	ListID   int `pg:"unique:list_position:deferrable"`
+	Position int `pg:"unique:list_position"`
+	Code     int `pg:"unique:code:deferred"`
+}
+
+type CreateTableBadOnDeleteModel struct {
+	ID                 int
+	CreateTableModelID int `pg:"on_delete:DROP TABLE users"`
+}
+
+type CreateTableDeferrableModel struct {
+	ID                 int
+	CreateTableModelID int `pg:",deferrable"`
+	CreateTableModel   *CreateTableModel
+}
+
+type CreateTableInetModel struct {
+	ID      int
+	Addr    types.Inet
+	AddrPtr *types.Inet
+	Network types.CIDR
+}
+
+type CreateTableNumericModel struct {
+	ID        int
+	Amount    types.Numeric
+	AmountPtr *types.Numeric
+	Precision types.Numeric `pg:"type:numeric(20,4)"`
+}
+
+type CreateTableUserTypeModel struct {
+	ID        int64            `pg:"type:integer"`
+	Name      string           `pg:"type:varchar(50)"`
+	Price     float64          `pg:"type:numeric(10,2)"`
+	CreatedAt time.Time        `pg:"type:timestamp"`
+	UpdatedAt string           `pg:"type:timestamptz"`
+	Tags      []string         `pg:",array,type:varchar(20)"`
+	Scores    []float64        `pg:"type:numeric(5,2)[]"`
+	Code      CreateTableCode  `pg:"type:char(3)"`
+	Attrs     CreateTableAttrs `pg:"type:json"`
+}
+
+type CreateTableCode string
+
+type CreateTableAttrs struct {
+	Color string
+}
+
+type CreateTableBookingModel struct {
+	ID     int
+	RoomID int    `pg:"exclude:no_overlap"`
+	During string `pg:"type:tstzrange,exclude:no_overlap:&&"`
+}
+
 type CreateTableWithTablespace struct {
 	tableName string `pg:"tablespace:ssd"`
 
@@ -120,6 +190,61 @@ var _ = Describe("CreateTable", func() {
 		Expect(s).To(Equal(`CREATE TABLE "create_table_on_delete_on_update_models" ("id" bigserial, "create_table_model_id" bigint, PRIMARY KEY ("id"), FOREIGN KEY ("create_table_model_id") REFERENCES "create_table_models" ("id") ON DELETE RESTRICT ON UPDATE CASCADE)`))
 	})
 
+	It("creates new table with on_delete and on_update options on the relation", func() {
+		q := NewQuery(nil, &CreateTableRelOnDeleteModel{})
+
+		s := createTableQueryString(q, &CreateTableOptions{FKConstraints: true})
+		Expect(s).To(Equal(`CREATE TABLE "create_table_rel_on_delete_models" ("id" bigserial, "create_table_model_id" bigint, PRIMARY KEY ("id"), FOREIGN KEY ("create_table_model_id") REFERENCES "create_table_models" ("id") ON DELETE SET NULL ON UPDATE CASCADE)`))
+	})
+
+	It("panics on unsupported on_delete", func() {
+		Expect(func() {
+			GetTable(reflect.TypeOf(CreateTableBadOnDeleteModel{}))
+		}).To(Panic())
+	})
+
+	It("creates new table with identity columns", func() {
+		q := NewQuery(nil, &CreateTableIdentityModel{})
+
+		s := createTableQueryString(q, nil)
+		Expect(s).To(Equal(`CREATE TABLE "create_table_identity_models" ("id" bigint GENERATED ALWAYS AS IDENTITY, "counter" bigint GENERATED BY DEFAULT AS IDENTITY, PRIMARY KEY ("id"))`))
+	})
+
+	It("creates new table with deferrable unique constraints", func() {
+		q := NewQuery(nil, &CreateTableDeferrableUniqueModel{})
+
+		s := createTableQueryString(q, nil)
+		Expect(s).To(Equal(`CREATE TABLE "create_table_deferrable_unique_models" ("id" bigserial, "list_id" bigint, "position" bigint, "code" bigint, PRIMARY KEY ("id"), UNIQUE ("code") DEFERRABLE INITIALLY DEFERRED, UNIQUE ("list_id", "position") DEFERRABLE INITIALLY IMMEDIATE)`))
+	})
+
+	It("creates new table with deferrable foreign keys", func() {
+		q := NewQuery(nil, &CreateTableDeferrableModel{})
+
+		s := createTableQueryString(q, &CreateTableOptions{FKConstraints: true})
+		Expect(s).To(Equal(`CREATE TABLE "create_table_deferrable_models" ("id" bigserial, "create_table_model_id" bigint, PRIMARY KEY ("id"), FOREIGN KEY ("create_table_model_id") REFERENCES "create_table_models" ("id") DEFERRABLE INITIALLY DEFERRED)`))
+	})
+
+	It("creates new table with inet and cidr columns", func() {
+		q := NewQuery(nil, &CreateTableInetModel{})
+
+		s := createTableQueryString(q, &CreateTableOptions{})
+		Expect(s).To(Equal(`CREATE TABLE "create_table_inet_models" ("id" bigserial, "addr" inet, "addr_ptr" inet, "network" cidr, PRIMARY KEY ("id"))`))
+	})
+
+	It("creates new table with numeric columns", func() {
+		q := NewQuery(nil, &CreateTableNumericModel{})
+
+		s := createTableQueryString(q, &CreateTableOptions{})
+		Expect(s).To(Equal(`CREATE TABLE "create_table_numeric_models" ("id" bigserial, "amount" numeric, "amount_ptr" numeric, "precision" numeric(20,4), PRIMARY KEY ("id"))`))
+	})
+
+	It("creates new table with user defined types", func() {
+		q := NewQuery(nil, &CreateTableUserTypeModel{})
+
+		s := createTableQueryString(q, &CreateTableOptions{Varchar: 255})
+		Expect(s).To(Equal(`CREATE TABLE "create_table_user_type_models" ("id" integer, "name" varchar(50), "price" numeric(10,2), "created_at" timestamp, "updated_at" timestamptz, "tags" varchar(20)[], "scores" numeric(5,2)[], "code" char(3), "attrs" json, PRIMARY KEY ("id"))`))
+	})
+
 	It("creates new table with tablespace options", func() {
 		q := NewQuery(nil, &CreateTableWithTablespace{})
 
@@ -155,6 +280,31 @@ var _ = Describe("CreateTable", func() {
 		Expect(s).To(Equal(`CREATE TABLE "create_table_with_multiple_named_uniques" ("id" bigserial, "account_id" bigint, "order_number" text, "store_order_number" text, PRIMARY KEY ("id"), UNIQUE ("account_id", "order_number"), UNIQUE ("account_id", "store_order_number"))`))
 	})
 
+	It("creates new table with exclusion constraints", func() {
+		q := NewQuery(nil, &CreateTableBookingModel{})
+
+		s := createTableQueryString(q, &CreateTableOptions{
+			Exclude: []ExcludeConstraint{{
+				Using: "gist",
+				Elements: []ExcludeElement{
+					{Column: "(lower(during))", Operator: "="},
+				},
+			}},
+		})
+		Expect(s).To(Equal(`CREATE TABLE "create_table_booking_models" ("id" bigserial, "room_id" bigint, "during" tstzrange, PRIMARY KEY ("id"), CONSTRAINT "no_overlap" EXCLUDE USING gist ("room_id" WITH =, "during" WITH &&), EXCLUDE USING gist ((lower(during)) WITH =))`))
+	})
+
+	It("returns an error for invalid exclusion operator", func() {
+		q := NewQuery(nil, &CreateTableBookingModel{})
+
+		_, err := NewCreateTableQuery(q, &CreateTableOptions{
+			Exclude: []ExcludeConstraint{{
+				Elements: []ExcludeElement{{Column: "during", Operator: "&&; DROP"}},
+			}},
+		}).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError(`pg: invalid exclusion constraint operator="&&; DROP"`))
+	})
+
 	It("supports model without a table name", func() {
 		type Model struct {
 			tableName struct{} `pg:"_"`
@@ -0,0 +1,140 @@
+package orm
+
+import (
+	"reflect"
+	"sort"
+)
+
+// TableInfo is a read-only description of the table created from a Go struct.
+// It is a copy of the table metadata, so changing it does not affect queries.
+type TableInfo struct {
+	TypeName  string
+	SQLName   string // quoted table name, e.g. "books"
+	Alias     string // quoted table alias, e.g. "book"
+	Columns   []ColumnInfo
+	PKs       []string
+	Relations []RelationInfo
+}
+
+// ColumnInfo describes a column created from a struct field.
+type ColumnInfo struct {
+	GoName  string
+	SQLName string
+	SQLType string
+	Default string
+
+	PK      bool
+	NotNull bool
+	Unique  bool
+	Array   bool
+	UseZero bool
+
+	// Tag is the struct field tag, e.g. to read json or other
+	// non-pg tags.
+	Tag reflect.StructTag
+	// Options are the pg tag options, e.g. {"notnull": "", "type": "text"}.
+	Options map[string]string
+}
+
+// RelationInfo describes a relation created from a struct field.
+type RelationInfo struct {
+	GoName    string
+	Type      string // has-one, belongs-to, has-many or many2many
+	JoinTable string // quoted table name of the joined model
+	BaseFKs   []string
+	JoinFKs   []string
+	M2MTable  string // quoted name of the many2many table
+	JoinOn    string // join condition of has-many relation, e.g. "a && parent.b"
+}
+
+// GetTableInfo returns a description of the table created from the struct
+// type or the pointer to the struct type.
+func GetTableInfo(typ reflect.Type) *TableInfo {
+	return GetTable(indirectType(typ)).Info()
+}
+
+// Info returns a read-only description of the table.
+func (t *Table) Info() *TableInfo {
+	info := &TableInfo{
+		TypeName: t.TypeName,
+		SQLName:  string(t.SQLName),
+		Alias:    string(t.Alias),
+		Columns:  make([]ColumnInfo, 0, len(t.Fields)),
+		PKs:      fieldSQLNames(t.PKs),
+	}
+
+	for _, f := range t.Fields {
+		info.Columns = append(info.Columns, f.info())
+	}
+
+	for _, rel := range t.Relations {
+		relInfo := RelationInfo{
+			GoName:    rel.Field.GoName,
+			Type:      relationTypeName(rel.Type),
+			JoinTable: string(rel.JoinTable.SQLName),
+			BaseFKs:   fieldSQLNames(rel.BaseFKs),
+			JoinFKs:   fieldSQLNames(rel.JoinFKs),
+			M2MTable:  string(rel.M2MTableName),
+			JoinOn:    rel.JoinOn,
+		}
+		if rel.Type == Many2ManyRelation {
+			relInfo.BaseFKs = append([]string(nil), rel.M2MBaseFKs...)
+			relInfo.JoinFKs = append([]string(nil), rel.M2MJoinFKs...)
+		}
+		info.Relations = append(info.Relations, relInfo)
+	}
+	// Relations are stored in a map, so sort them to get a stable order.
+	sort.Slice(info.Relations, func(i, j int) bool {
+		return info.Relations[i].GoName < info.Relations[j].GoName
+	})
+
+	return info
+}
+
+func (f *Field) info() ColumnInfo {
+	options := make(map[string]string, len(f.options))
+	for k, v := range f.options {
+		options[k] = v
+	}
+
+	return ColumnInfo{
+		GoName:  f.GoName,
+		SQLName: f.SQLName,
+		SQLType: f.SQLType,
+		Default: string(f.Default),
+
+		PK:      f.hasFlag(PrimaryKeyFlag),
+		NotNull: f.hasFlag(NotNullFlag),
+		Unique:  f.hasFlag(UniqueFlag),
+		Array:   f.hasFlag(ArrayFlag),
+		UseZero: f.hasFlag(UseZeroFlag),
+
+		Tag:     f.Field.Tag,
+		Options: options,
+	}
+}
+
+func fieldSQLNames(fields []*Field) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.SQLName
+	}
+	return names
+}
+
+func relationTypeName(typ int) string {
+	switch typ {
+	case HasOneRelation:
+		return "has-one"
+	case BelongsToRelation:
+		return "belongs-to"
+	case HasManyRelation:
+		return "has-many"
+	case Many2ManyRelation:
+		return "many2many"
+	}
+	return ""
+}
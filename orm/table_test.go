@@ -1,8 +1,11 @@
 package orm_test
 
 import (
+	"database/sql"
 	"reflect"
+	"time"
 
+	"github.com/go-pg/pg/v10/internal/pool"
 	"github.com/go-pg/pg/v10/orm"
 	"github.com/go-pg/pg/v10/types"
 
@@ -118,6 +121,336 @@ var _ = Describe("struct field", func() {
 	})
 })
 
+type ScanOnlyModel struct {
+	Id       int
+	Distance float64 `pg:",scanonly"`
+	Rank     int     `pg:"score,scanonly"`
+}
+
+var _ = Describe("scanonly field", func() {
+	var table *orm.Table
+
+	BeforeEach(func() {
+		strct := reflect.ValueOf(ScanOnlyModel{})
+		table = orm.GetTable(strct.Type())
+	})
+
+	It("is scanned but not used as a column", func() {
+		Expect(table.Fields).To(HaveLen(1))
+		Expect(table.HasField("distance")).To(BeTrue())
+		Expect(table.HasField("score")).To(BeTrue())
+	})
+})
+
+type DefaultOnNullModel struct {
+	ID      int
+	Count   int       `pg:",default_on_null:-1"`
+	Ratio   float32   `pg:",default_on_null:0.5"`
+	Active  bool      `pg:",default_on_null:true"`
+	Name    string    `pg:",default_on_null:'n/a'"`
+	Since   time.Time `pg:",default_on_null:'2020-01-01 00:00:00+00'"`
+	Score   *uint     `pg:",default_on_null"`
+	Comment string    `pg:",default_on_null"`
+}
+
+type InvalidDefaultOnNullModel struct {
+	ID    int
+	Count int8 `pg:",default_on_null:1000"`
+}
+
+var _ = Describe("default_on_null field", func() {
+	It("scans the default instead of NULL", func() {
+		table := orm.GetTable(reflect.TypeOf(DefaultOnNullModel{}))
+		model := &DefaultOnNullModel{Comment: "old"}
+		strct := reflect.ValueOf(model).Elem()
+
+		for _, f := range table.Fields {
+			err := f.ScanValue(strct, pool.NewBytesReader(nil), -1)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(model.Count).To(Equal(-1))
+		Expect(model.Ratio).To(Equal(float32(0.5)))
+		Expect(model.Active).To(BeTrue())
+		Expect(model.Name).To(Equal("n/a"))
+		Expect(model.Since.Equal(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))).To(BeTrue())
+		Expect(model.Score).NotTo(BeNil())
+		Expect(*model.Score).To(Equal(uint(0)))
+		Expect(model.Comment).To(Equal(""))
+
+		b := []byte("7")
+		err := table.FieldsMap["count"].ScanValue(strct, pool.NewBytesReader(b), len(b))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Count).To(Equal(7))
+	})
+
+	It("panics on invalid default", func() {
+		Expect(func() {
+			orm.GetTable(reflect.TypeOf(InvalidDefaultOnNullModel{}))
+		}).To(Panic())
+	})
+})
+
+type BoolTypeModel struct {
+	ID       int
+	Char     bool  `pg:",bool_type:char"`
+	Smallint bool  `pg:",bool_type:smallint"`
+	Int      *bool `pg:",bool_type:int"`
+	Typed    bool  `pg:"type:bpchar(3),bool_type:char"`
+}
+
+type InvalidBoolTypeModel struct {
+	ID   int
+	Flag string `pg:",bool_type:char"`
+}
+
+type UnknownBoolTypeModel struct {
+	ID   int
+	Flag bool `pg:",bool_type:yes_no"`
+}
+
+var _ = Describe("bool_type field", func() {
+	var table *orm.Table
+
+	BeforeEach(func() {
+		table = orm.GetTable(reflect.TypeOf(BoolTypeModel{}))
+	})
+
+	It("uses the SQL type of the representation", func() {
+		Expect(table.FieldsMap["char"].SQLType).To(Equal("char(1)"))
+		Expect(table.FieldsMap["smallint"].SQLType).To(Equal("smallint"))
+		Expect(table.FieldsMap["int"].SQLType).To(Equal("integer"))
+		Expect(table.FieldsMap["typed"].SQLType).To(Equal("bpchar(3)"))
+	})
+
+	It("appends bools using the representation", func() {
+		yes := true
+		model := &BoolTypeModel{Char: true, Int: &yes}
+		strct := reflect.ValueOf(model).Elem()
+
+		b := table.FieldsMap["char"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("'Y'"))
+		b = table.FieldsMap["smallint"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("0"))
+		b = table.FieldsMap["int"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("1"))
+
+		model.Char = false
+		model.Int = nil
+		b = table.FieldsMap["char"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("'N'"))
+		b = table.FieldsMap["int"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("NULL"))
+	})
+
+	It("scans bools using the representation", func() {
+		model := new(BoolTypeModel)
+		strct := reflect.ValueOf(model).Elem()
+
+		scan := func(name, value string) {
+			b := []byte(value)
+			err := table.FieldsMap[name].ScanValue(strct, pool.NewBytesReader(b), len(b))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		scan("char", "Y")
+		Expect(model.Char).To(BeTrue())
+		scan("char", "y")
+		Expect(model.Char).To(BeTrue())
+		scan("char", "N")
+		Expect(model.Char).To(BeFalse())
+		scan("typed", "Y  ")
+		Expect(model.Typed).To(BeTrue())
+		scan("smallint", "1")
+		Expect(model.Smallint).To(BeTrue())
+		scan("smallint", "0")
+		Expect(model.Smallint).To(BeFalse())
+		scan("int", "1")
+		Expect(model.Int).NotTo(BeNil())
+		Expect(*model.Int).To(BeTrue())
+
+		err := table.FieldsMap["int"].ScanValue(strct, pool.NewBytesReader(nil), -1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Int).To(BeNil())
+	})
+
+	It("panics on unsupported field type", func() {
+		Expect(func() {
+			orm.GetTable(reflect.TypeOf(InvalidBoolTypeModel{}))
+		}).To(Panic())
+	})
+
+	It("panics on unknown representation", func() {
+		Expect(func() {
+			orm.GetTable(reflect.TypeOf(UnknownBoolTypeModel{}))
+		}).To(Panic())
+	})
+})
+
+type TimestampModel struct {
+	ID          int
+	CreatedAt   time.Time
+	Local       time.Time  `pg:"type:timestamp"`
+	LocalPtr    *time.Time `pg:"type:timestamp(3)"`
+	WithoutZone time.Time  `pg:"type:timestamp without time zone"`
+}
+
+type DefaultTimestampModel struct {
+	ID          int
+	CreatedAt   time.Time
+	DeletedAt   *time.Time
+	NullTime    types.NullTime
+	SQLNullTime sql.NullTime
+}
+
+var _ = Describe("timestamp field", func() {
+	var table *orm.Table
+
+	BeforeEach(func() {
+		table = orm.GetTable(reflect.TypeOf(TimestampModel{}))
+	})
+
+	It("uses the SQL type from the tag", func() {
+		Expect(table.FieldsMap["created_at"].SQLType).To(Equal("timestamptz"))
+		Expect(table.FieldsMap["local"].SQLType).To(Equal("timestamp"))
+		Expect(table.FieldsMap["local_ptr"].SQLType).To(Equal("timestamp(3)"))
+		Expect(table.FieldsMap["without_zone"].SQLType).To(Equal("timestamp without time zone"))
+	})
+
+	It("appends timestamp as wall clock", func() {
+		loc := time.FixedZone("UTC-5", -5*3600)
+		tm := time.Date(2021, time.March, 14, 1, 30, 0, 0, loc)
+		model := &TimestampModel{CreatedAt: tm, Local: tm, WithoutZone: tm}
+		strct := reflect.ValueOf(model).Elem()
+
+		b := table.FieldsMap["created_at"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("'2021-03-14 06:30:00+00:00:00'"))
+		b = table.FieldsMap["local"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("'2021-03-14 01:30:00'"))
+		b = table.FieldsMap["without_zone"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("'2021-03-14 01:30:00'"))
+		b = table.FieldsMap["local_ptr"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("NULL"))
+
+		model.LocalPtr = &tm
+		b = table.FieldsMap["local_ptr"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("'2021-03-14 01:30:00'"))
+	})
+
+	It("scans timestamp as wall clock in UTC", func() {
+		model := new(TimestampModel)
+		strct := reflect.ValueOf(model).Elem()
+
+		b := []byte("2021-03-14 01:30:00")
+		err := table.FieldsMap["local"].ScanValue(strct, pool.NewBytesReader(b), len(b))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Local).To(Equal(time.Date(2021, time.March, 14, 1, 30, 0, 0, time.UTC)))
+	})
+
+	It("supports changing the default time type", func() {
+		orm.SetDefaultTimeType("timestamp")
+		defer orm.SetDefaultTimeType("timestamptz")
+
+		table := orm.GetTable(reflect.TypeOf(DefaultTimestampModel{}))
+		Expect(table.FieldsMap["created_at"].SQLType).To(Equal("timestamp"))
+		Expect(table.FieldsMap["deleted_at"].SQLType).To(Equal("timestamp"))
+
+		tm := time.Date(2021, time.March, 14, 1, 30, 0, 0, time.FixedZone("UTC-5", -5*3600))
+		model := &DefaultTimestampModel{CreatedAt: tm}
+		b := table.FieldsMap["created_at"].AppendValue(nil, reflect.ValueOf(model).Elem(), 1)
+		Expect(string(b)).To(Equal("'2021-03-14 01:30:00'"))
+
+		Expect(table.FieldsMap["null_time"].SQLType).To(Equal("timestamp"))
+		Expect(table.FieldsMap["sql_null_time"].SQLType).To(Equal("timestamp"))
+
+		strct := reflect.ValueOf(model).Elem()
+		b = table.FieldsMap["null_time"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("NULL"))
+		b = table.FieldsMap["sql_null_time"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("NULL"))
+
+		model.NullTime = types.NullTime{Time: tm}
+		model.SQLNullTime = sql.NullTime{Time: tm, Valid: true}
+		b = table.FieldsMap["null_time"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("'2021-03-14 01:30:00'"))
+		b = table.FieldsMap["sql_null_time"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal("'2021-03-14 01:30:00'"))
+	})
+
+	It("panics on unsupported default time type", func() {
+		Expect(func() {
+			orm.SetDefaultTimeType("date")
+		}).To(Panic())
+	})
+})
+
+type InfoAuthor struct {
+	tableName struct{} `pg:"authors,alias:a"`
+
+	ID    int
+	Books []*InfoBook `pg:"rel:has-many"`
+}
+
+type InfoBook struct {
+	ID           int
+	Title        string   `pg:",notnull,default:'untitled'" json:"title"`
+	Tags         []string `pg:",array"`
+	InfoAuthorID int
+	InfoAuthor   *InfoAuthor `pg:"rel:has-one"`
+}
+
+var _ = Describe("GetTableInfo", func() {
+	It("describes columns", func() {
+		info := orm.GetTableInfo(reflect.TypeOf((*InfoBook)(nil)))
+		Expect(info.TypeName).To(Equal("InfoBook"))
+		Expect(info.SQLName).To(Equal(`"info_books"`))
+		Expect(info.Alias).To(Equal(`"info_book"`))
+		Expect(info.PKs).To(Equal([]string{"id"}))
+		Expect(info.Columns).To(HaveLen(4))
+
+		title := info.Columns[1]
+		Expect(title.GoName).To(Equal("Title"))
+		Expect(title.SQLName).To(Equal("title"))
+		Expect(title.SQLType).To(Equal("text"))
+		Expect(title.Default).To(Equal(`'untitled'`))
+		Expect(title.NotNull).To(BeTrue())
+		Expect(title.Tag.Get("json")).To(Equal("title"))
+		Expect(title.Options).To(Equal(map[string]string{
+			"notnull": "",
+			"default": "'untitled'",
+		}))
+
+		tags := info.Columns[2]
+		Expect(tags.Array).To(BeTrue())
+		Expect(tags.SQLType).To(Equal("text[]"))
+	})
+
+	It("describes relations", func() {
+		info := orm.GetTableInfo(reflect.TypeOf(InfoBook{}))
+		Expect(info.Relations).To(Equal([]orm.RelationInfo{{
+			GoName:    "InfoAuthor",
+			Type:      "has-one",
+			JoinTable: `"authors"`,
+			BaseFKs:   []string{"info_author_id"},
+			JoinFKs:   []string{"id"},
+		}}))
+
+		info = orm.GetTableInfo(reflect.TypeOf(InfoAuthor{}))
+		Expect(info.Alias).To(Equal(`"a"`))
+		Expect(info.Relations).To(HaveLen(1))
+		Expect(info.Relations[0].Type).To(Equal("has-many"))
+		Expect(info.Relations[0].JoinTable).To(Equal(`"info_books"`))
+	})
+
+	It("returns a copy", func() {
+		info := orm.GetTableInfo(reflect.TypeOf(InfoBook{}))
+		info.Columns[1].Options["notnull"] = "changed"
+
+		info = orm.GetTableInfo(reflect.TypeOf(InfoBook{}))
+		Expect(info.Columns[1].Options["notnull"]).To(Equal(""))
+	})
+})
+
 type f struct {
 	Id int
 	G  *g
@@ -0,0 +1,115 @@
+package orm
+
+import "errors"
+
+type TruncateOptions struct {
+	RestartIdentity  bool
+	ContinueIdentity bool
+	Cascade          bool
+	Restrict         bool
+}
+
+func (opt *TruncateOptions) validate() error {
+	if opt.RestartIdentity && opt.ContinueIdentity {
+		return errors.New("pg: RestartIdentity and ContinueIdentity are mutually exclusive")
+	}
+	if opt.Cascade && opt.Restrict {
+		return errors.New("pg: Cascade and Restrict are mutually exclusive")
+	}
+	return nil
+}
+
+// TruncateQuery truncates the model table and any tables added with Table:
+//
+//    db.Model((*Book)(nil)).Table("authors").Truncate(&orm.TruncateOptions{
+//    	RestartIdentity: true,
+//    })
+//
+// generates
+//
+//    TRUNCATE "books", "authors" RESTART IDENTITY
+type TruncateQuery struct {
+	q   *Query
+	opt *TruncateOptions
+}
+
+var (
+	_ QueryAppender = (*TruncateQuery)(nil)
+	_ QueryCommand  = (*TruncateQuery)(nil)
+)
+
+func NewTruncateQuery(q *Query, opt *TruncateOptions) *TruncateQuery {
+	return &TruncateQuery{
+		q:   q,
+		opt: opt,
+	}
+}
+
+func (q *TruncateQuery) String() string {
+	b, err := q.AppendQuery(defaultFmter, nil)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func (q *TruncateQuery) Operation() QueryOp {
+	return TruncateOp
+}
+
+func (q *TruncateQuery) Clone() QueryCommand {
+	return &TruncateQuery{
+		q:   q.q.Clone(),
+		opt: q.opt,
+	}
+}
+
+func (q *TruncateQuery) Query() *Query {
+	return q.q
+}
+
+func (q *TruncateQuery) AppendTemplate(b []byte) ([]byte, error) {
+	return q.AppendQuery(dummyFormatter{}, b)
+}
+
+func (q *TruncateQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err error) {
+	if q.q.stickyErr != nil {
+		return nil, q.q.stickyErr
+	}
+	if !q.q.hasTables() {
+		return nil, errModelNil
+	}
+	if q.opt != nil {
+		if err := q.opt.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	b = append(b, "TRUNCATE "...)
+	b, err = q.q.appendFirstTable(fmter, b)
+	if err != nil {
+		return nil, err
+	}
+	if q.q.hasMultiTables() {
+		b = append(b, ", "...)
+		b, err = q.q.appendOtherTables(fmter, b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if q.opt != nil {
+		if q.opt.RestartIdentity {
+			b = append(b, " RESTART IDENTITY"...)
+		} else if q.opt.ContinueIdentity {
+			b = append(b, " CONTINUE IDENTITY"...)
+		}
+		if q.opt.Cascade {
+			b = append(b, " CASCADE"...)
+		} else if q.opt.Restrict {
+			b = append(b, " RESTRICT"...)
+		}
+	}
+
+	return b, q.q.stickyErr
+}
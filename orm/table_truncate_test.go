@@ -0,0 +1,55 @@
+package orm
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type TruncateTableModel struct{}
+
+var _ = Describe("Truncate", func() {
+	It("truncates table", func() {
+		q := NewQuery(nil, &TruncateTableModel{})
+
+		s := truncateQueryString(q, nil)
+		Expect(s).To(Equal(`TRUNCATE "truncate_table_models"`))
+	})
+
+	It("truncates multiple tables", func() {
+		q := NewQuery(nil, &TruncateTableModel{}).Table("other_table")
+
+		s := truncateQueryString(q, &TruncateOptions{
+			RestartIdentity: true,
+			Cascade:         true,
+		})
+		Expect(s).To(Equal(`TRUNCATE "truncate_table_models", "other_table" RESTART IDENTITY CASCADE`))
+	})
+
+	It("truncates tables without model", func() {
+		q := NewQuery(nil).Table("table1", "table2")
+
+		s := truncateQueryString(q, &TruncateOptions{ContinueIdentity: true, Restrict: true})
+		Expect(s).To(Equal(`TRUNCATE "table1", "table2" CONTINUE IDENTITY RESTRICT`))
+	})
+
+	It("returns an error on conflicting options", func() {
+		q := NewQuery(nil, &TruncateTableModel{})
+
+		_, err := NewTruncateQuery(q, &TruncateOptions{
+			RestartIdentity:  true,
+			ContinueIdentity: true,
+		}).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: RestartIdentity and ContinueIdentity are mutually exclusive"))
+
+		_, err = NewTruncateQuery(q, &TruncateOptions{
+			Cascade:  true,
+			Restrict: true,
+		}).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: Cascade and Restrict are mutually exclusive"))
+	})
+})
+
+func truncateQueryString(q *Query, opt *TruncateOptions) string {
+	qq := NewTruncateQuery(q, opt)
+	return queryString(qq)
+}
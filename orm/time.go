@@ -0,0 +1,94 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+var defaultTimeSQLType = pgTypeTimestampTz
+
+// SetDefaultTimeType changes the SQL type of time.Time fields without
+// the type tag. Supported types are timestamptz (default) and timestamp.
+// It must be called before models are used, because tables are cached.
+//
+// timestamptz stores an instant: values are sent in UTC and are read back
+// as the same instant in UTC regardless of the client or server time zone.
+//
+// timestamp stores a wall clock time without a time zone: values are sent
+// as the wall clock in their own location, e.g. 10:00 in Europe/Berlin is
+// stored as 10:00, and are read back with the same wall clock in UTC.
+// The original location is lost, so times in different locations are not
+// comparable, and wall clock times around DST transitions are ambiguous
+// (fall back) or do not exist (spring forward) in the original location.
+// This applies to time.Time, types.NullTime, and sql.NullTime fields.
+//
+// Query params are not fields, so time.Time params are still sent in UTC
+// and the server drops the offset when it compares them with a timestamp
+// column. Wrap them with pg.Timestamp to send the wall clock instead:
+//
+//    err := db.Model(&items).Where("created_at = ?", pg.Timestamp(tm)).Select()
+//
+// Use time.Date with the wanted location to restore it, e.g.
+//
+//    tm = time.Date(tm.Year(), tm.Month(), tm.Day(),
+//    	tm.Hour(), tm.Minute(), tm.Second(), tm.Nanosecond(), loc)
+func SetDefaultTimeType(sqlType string) {
+	switch sqlType {
+	case pgTypeTimestampTz, pgTypeTimestamp:
+		defaultTimeSQLType = sqlType
+	default:
+		panic(fmt.Errorf("pg: unsupported default time type %q (supported: timestamptz, timestamp)", sqlType))
+	}
+}
+
+// isTimestamp reports whether the SQL type is a timestamp without time zone,
+// e.g. timestamp, timestamp(3), or timestamp without time zone.
+func isTimestamp(sqlType string) bool {
+	s := strings.ToLower(sqlType)
+	if i := strings.IndexByte(s, '('); i >= 0 {
+		if j := strings.IndexByte(s[i:], ')'); j >= 0 {
+			s = s[:i] + s[i+j+1:]
+		}
+	}
+	s = strings.TrimSpace(s)
+	return s == pgTypeTimestamp || s == "timestamp without time zone"
+}
+
+// isTimeType reports whether typ is a time type that is mapped to timestamptz
+// by default.
+func isTimeType(typ reflect.Type) bool {
+	switch typ {
+	case timeType, nullTimeType, sqlNullTimeType:
+		return true
+	}
+	return false
+}
+
+func appendWallTimeValue(b []byte, v reflect.Value, flags int) []byte {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return types.AppendNull(b, flags)
+		}
+		v = v.Elem()
+	}
+
+	switch tm := v.Interface().(type) {
+	case types.NullTime:
+		if tm.IsZero() {
+			return types.AppendNull(b, flags)
+		}
+		return types.AppendTimestamp(b, tm.Time, flags)
+	case sql.NullTime:
+		if !tm.Valid {
+			return types.AppendNull(b, flags)
+		}
+		return types.AppendTimestamp(b, tm.Time, flags)
+	default:
+		return types.AppendTimestamp(b, v.Interface().(time.Time), flags)
+	}
+}
@@ -19,6 +19,7 @@ const (
 	pgTypeBoolean = "boolean"
 
 	// Numeric Types
+	pgTypeNumeric = "numeric" // exact numeric of selectable precision
 
 	// Floating Point Types
 	pgTypeReal            = "real"             // 4 byte floating point (6 digit precision)
@@ -45,4 +46,7 @@ const (
 
 	// Binary Data Types
 	pgTypeBytea = "bytea" // binary string
+
+	// UUID Type
+	pgTypeUUID = "uuid" // universally unique identifier
 )
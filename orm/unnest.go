@@ -0,0 +1,96 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+// UnnestQuery is an unnest call that expands a slice to a set of rows and
+// can be used as a table expression, e.g. in Join or TableExpr:
+//
+//    q.TableExpr("?", NewUnnestQuery([]string{"b", "a"}).As("tag").WithOrdinality())
+//
+// generates
+//
+//    FROM unnest('{"b","a"}'::text[]) WITH ORDINALITY AS "tag" ("tag", "ordinality")
+//
+// The array is cast to the SQL type of the slice elements.
+type UnnestQuery struct {
+	slice      interface{}
+	value      reflect.Value
+	append     types.AppenderFunc
+	typ        string
+	alias      string
+	ordinality bool
+	err        error
+}
+
+var _ QueryAppender = (*UnnestQuery)(nil)
+
+// NewUnnestQuery returns an unnest call for the slice.
+func NewUnnestQuery(slice interface{}) *UnnestQuery {
+	q := &UnnestQuery{
+		slice: slice,
+	}
+	q.err = q.init()
+	return q
+}
+
+func (q *UnnestQuery) init() error {
+	v := reflect.ValueOf(q.slice)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+	default:
+		return fmt.Errorf("pg: Unnest(unsupported %T)", q.slice)
+	}
+
+	elemType := indirectType(v.Type().Elem())
+	if elemType.Kind() == reflect.Uint8 {
+		return fmt.Errorf("pg: Unnest(unsupported %T)", q.slice)
+	}
+
+	q.value = v
+	q.append = types.ArrayAppender(v.Type())
+	q.typ = sqlType(elemType) + "[]"
+	return nil
+}
+
+// As sets the alias of the unnest call. The value column has the same name
+// so it can be referenced both as alias and alias.alias.
+func (q *UnnestQuery) As(alias string) *UnnestQuery {
+	q.alias = alias
+	return q
+}
+
+// WithOrdinality adds the ordinality column holding the 1-based position
+// of the element in the slice. The column is named ordinality, so it can
+// be scanned into an Ordinality struct field and used to preserve
+// the order of the slice.
+func (q *UnnestQuery) WithOrdinality() *UnnestQuery {
+	q.ordinality = true
+	return q
+}
+
+func (q *UnnestQuery) AppendQuery(fmter QueryFormatter, b []byte) ([]byte, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	b = append(b, "unnest("...)
+	if isTemplateFormatter(fmter) {
+		b = append(b, '?')
+	} else {
+		b = q.append(b, q.value, 1)
+	}
+	b = append(b, "::"...)
+	b = append(b, q.typ...)
+	b = append(b, ')')
+	b = appendFuncTableAlias(b, q.alias, q.ordinality)
+
+	return b, nil
+}
@@ -0,0 +1,41 @@
+package orm
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unnest", func() {
+	It("unnests a slice with ordinality", func() {
+		q := NewQuery(nil).
+			ColumnExpr("tag, ordinality").
+			TableExpr("?", NewUnnestQuery([]string{"b", `a"`}).As("tag").WithOrdinality()).
+			Order("ordinality")
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT tag, ordinality FROM unnest('{"b","a\""}'::text[]) WITH ORDINALITY AS "tag" ("tag", "ordinality") ORDER BY "ordinality"`))
+	})
+
+	It("joins unnested ids to preserve their order", func() {
+		q := NewQuery(nil, &SelectModel{}).
+			Column("select_model.id").
+			Join("JOIN ? ON ids.ids = select_model.id", NewUnnestQuery([]int32{3, 1, 2}).As("ids").WithOrdinality()).
+			Order("ids.ordinality")
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "select_model"."id" FROM "select_models" AS "select_model" JOIN unnest('{3,1,2}'::integer[]) WITH ORDINALITY AS "ids" ("ids", "ordinality") ON ids.ids = select_model.id ORDER BY "ids"."ordinality"`))
+	})
+
+	It("omits alias", func() {
+		b, err := NewUnnestQuery([]int64{1}).AppendQuery(defaultFmter, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(`unnest('{1}'::bigint[])`))
+	})
+
+	It("returns an error for unsupported values", func() {
+		for _, v := range []interface{}{1, []byte("foo")} {
+			_, err := NewUnnestQuery(v).AppendQuery(defaultFmter, nil)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
 
 	"github.com/go-pg/pg/v10/types"
 )
@@ -61,6 +62,8 @@ func (q *UpdateQuery) AppendQuery(fmter QueryFormatter, b []byte) (_ []byte, err
 		return nil, q.q.stickyErr
 	}
 
+	b = q.q.appendComment(b)
+
 	if len(q.q.with) > 0 {
 		b, err = q.q.appendWith(fmter, b)
 		if err != nil {
@@ -117,7 +120,25 @@ func (q *UpdateQuery) mustAppendWhere(
 	b = append(b, " WHERE "...)
 
 	if !isSliceModelWithData {
-		return q.q.mustAppendWhere(fmter, b)
+		versionField := q.q.versionField()
+		if versionField == nil {
+			return q.q.mustAppendWhere(fmter, b)
+		}
+
+		b = append(b, '(')
+		b, err = q.q.mustAppendWhere(fmter, b)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, ") AND "...)
+		b = append(b, q.q.tableModel.Table().Alias...)
+		b = append(b, '.')
+		b = append(b, versionField.Column...)
+		b = append(b, " = "...)
+		if q.placeholder {
+			return append(b, '?'), nil
+		}
+		return versionField.AppendValue(b, q.q.tableModel.Value(), 1), nil
 	}
 
 	if len(q.q.where) > 0 {
@@ -192,17 +213,24 @@ func (q *UpdateQuery) appendMapSet(b []byte, m map[string]interface{}) []byte {
 }
 
 func (q *UpdateQuery) appendSetStruct(fmter QueryFormatter, b []byte, strct reflect.Value) ([]byte, error) {
-	fields, err := q.q.getFields()
+	fields, err := q.q.getUpdateFields(false)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(fields) == 0 {
 		fields = q.q.tableModel.Table().DataFields
+	} else {
+		fields = q.appendAutoNowFields(fields)
 	}
 
+	versionField := q.q.versionField()
+
 	pos := len(b)
 	for _, f := range fields {
+		if f == versionField {
+			continue
+		}
 		if q.omitZero && f.NullZero() && f.HasZeroValue(strct) {
 			continue
 		}
@@ -231,6 +259,20 @@ func (q *UpdateQuery) appendSetStruct(fmter QueryFormatter, b []byte, strct refl
 		}
 	}
 
+	if versionField != nil {
+		if len(b) != pos {
+			b = append(b, ", "...)
+		}
+
+		b = append(b, versionField.Column...)
+		b = append(b, " = "...)
+		if q.placeholder {
+			b = append(b, '?')
+		} else {
+			b = appendNextVersion(b, versionField.Value(strct))
+		}
+	}
+
 	for i, v := range q.q.extraValues {
 		if i > 0 || len(fields) > 0 {
 			b = append(b, ", "...)
@@ -249,13 +291,15 @@ func (q *UpdateQuery) appendSetStruct(fmter QueryFormatter, b []byte, strct refl
 }
 
 func (q *UpdateQuery) appendSetSlice(b []byte) ([]byte, error) {
-	fields, err := q.q.getFields()
+	fields, err := q.q.getUpdateFields(false)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(fields) == 0 {
 		fields = q.q.tableModel.Table().DataFields
+	} else {
+		fields = q.appendAutoNowFields(fields)
 	}
 
 	var table *Table
@@ -290,12 +334,13 @@ func (q *UpdateQuery) appendSetSlice(b []byte) ([]byte, error) {
 }
 
 func (q *UpdateQuery) appendSliceModelData(fmter QueryFormatter, b []byte) ([]byte, error) {
-	columns, err := q.q.getDataFields()
+	columns, err := q.q.getUpdateFields(true)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(columns) > 0 {
+		columns = q.appendAutoNowFields(columns)
 		columns = append(columns, q.q.tableModel.Table().PKs...)
 	} else {
 		columns = q.q.tableModel.Table().Fields
@@ -376,3 +421,49 @@ func appendWhereColumnAndColumn(b []byte, alias types.Safe, fields []*Field) []b
 	}
 	return b
 }
+
+// appendAutoNowFields adds auto_now fields missing from the explicitly
+// listed columns so they are updated together with the other columns.
+func (q *UpdateQuery) appendAutoNowFields(fields []*Field) []*Field {
+	autoNow := q.q.tableModel.Table().AutoNowFields
+	if len(autoNow) == 0 {
+		return fields
+	}
+
+	fields = fields[:len(fields):len(fields)]
+	for _, f := range autoNow {
+		if !fieldsContain(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func fieldsContain(fields []*Field, field *Field) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// appendNextVersion appends the version incremented by 1.
+func appendNextVersion(b []byte, version reflect.Value) []byte {
+	switch version.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.AppendUint(b, version.Uint()+1, 10)
+	default:
+		return strconv.AppendInt(b, version.Int()+1, 10)
+	}
+}
+
+// setNextVersion sets the version field to the old version incremented by 1.
+func setNextVersion(fv, old reflect.Value) {
+	switch fv.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fv.SetUint(old.Uint() + 1)
+	default:
+		fv.SetInt(old.Int() + 1)
+	}
+}
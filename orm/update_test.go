@@ -1,12 +1,15 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
+	"reflect"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/go-pg/pg/v10/internal/pool"
 	"github.com/go-pg/pg/v10/types"
 )
 
@@ -20,6 +23,40 @@ type SerialUpdateTest struct {
 	Value string
 }
 
+type VersionUpdateTest struct {
+	Id      int
+	Value   string
+	Version int `pg:",version"`
+}
+
+type AutoNowUpdateTest struct {
+	Id        int
+	Value     string
+	CreatedAt time.Time  `pg:",auto_now_add"`
+	UpdatedAt *time.Time `pg:",auto_now"`
+}
+
+type JSONMeta struct {
+	Tags  []string `json:",omitempty"`
+	Score int      `json:",omitempty"`
+}
+
+type JSONOmitEmptyUpdateTest struct {
+	Id      int
+	Meta    JSONMeta  `pg:",omitempty"`
+	MetaPtr *JSONMeta `pg:",omitempty"`
+	Raw     JSONMeta
+	RawPtr  *JSONMeta `pg:",use_zero"`
+	Name    string    `pg:",json,use_zero"`
+}
+
+type UpdateColumnsTest struct {
+	Id    int
+	Name  string
+	Email string
+	Age   int
+}
+
 var _ = Describe("Update", func() {
 	It("updates model", func() {
 		q := NewQuery(nil, &UpdateTest{}).WherePK()
@@ -219,6 +256,200 @@ var _ = Describe("Update", func() {
 		s := updateQueryString(q)
 		Expect(s).To(Equal(`UPDATE "items" AS "item" SET "text" = _data."text" FROM (VALUES (2::bigint, 'two'::text), (1::bigint, 'one'::text)) AS _data("id", "text") WHERE "item"."id" = "_data"."id"`))
 	})
+
+	It("checks and increments version", func() {
+		q := NewQuery(nil, &VersionUpdateTest{Id: 1, Value: "foo", Version: 2}).
+			WherePK().
+			WhereOr("value = ?", "bar")
+
+		s := updateQueryString(q)
+		Expect(s).To(Equal(`UPDATE "version_update_tests" AS "version_update_test" SET "value" = 'foo', "version" = 3 WHERE ("version_update_test"."id" = 1 OR (value = 'bar')) AND "version_update_test"."version" = 2`))
+	})
+
+	It("checks zero version", func() {
+		q := NewQuery(nil, &VersionUpdateTest{Id: 1}).Column("value").WherePK()
+
+		s := updateQueryString(q)
+		Expect(s).To(Equal(`UPDATE "version_update_tests" AS "version_update_test" SET "value" = NULL, "version" = 1 WHERE ("version_update_test"."id" = 1) AND "version_update_test"."version" = 0`))
+	})
+
+	It("does not check version with Set", func() {
+		q := NewQuery(nil, &VersionUpdateTest{Id: 1, Version: 2}).Set("value = ?", "foo").WherePK()
+
+		s := updateQueryString(q)
+		Expect(s).To(Equal(`UPDATE "version_update_tests" AS "version_update_test" SET value = 'foo' WHERE "version_update_test"."id" = 1`))
+	})
+
+	It("adds auto_now columns to explicitly listed columns", func() {
+		tm := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		q := NewQuery(nil, &AutoNowUpdateTest{Id: 1, Value: "foo", UpdatedAt: &tm}).
+			Column("value").
+			WherePK()
+
+		s := updateQueryString(q)
+		Expect(s).To(Equal(`UPDATE "auto_now_update_tests" AS "auto_now_update_test" SET "value" = 'foo', "updated_at" = '2020-01-02 03:04:05+00:00:00' WHERE "auto_now_update_test"."id" = 1`))
+	})
+
+	It("updates only UpdateColumns", func() {
+		model := &UpdateColumnsTest{Id: 1, Name: "name", Email: "email", Age: 10}
+		q := NewQuery(nil, model).
+			Column("age").
+			UpdateColumns("name", "email", "name").
+			WherePK()
+
+		s := updateQueryString(q)
+		Expect(s).To(Equal(`UPDATE "update_columns_tests" AS "update_columns_test" SET "name" = 'name', "email" = 'email' WHERE "update_columns_test"."id" = 1`))
+	})
+
+	It("bulk updates only UpdateColumns", func() {
+		slice := []*UpdateColumnsTest{
+			{Id: 1, Name: "foo", Age: 10},
+			{Id: 2, Name: "bar", Age: 20},
+		}
+		q := NewQuery(nil, &slice).UpdateColumns("id", "name")
+
+		s := updateQueryString(q)
+		Expect(s).To(Equal(`UPDATE "update_columns_tests" AS "update_columns_test" SET "id" = _data."id", "name" = _data."name" FROM (VALUES ('foo'::text, 1::bigint), ('bar'::text, 2::bigint)) AS _data("name", "id") WHERE "update_columns_test"."id" = _data."id"`))
+	})
+
+	It("validates UpdateColumns", func() {
+		q := NewQuery(nil, &UpdateColumnsTest{}).UpdateColumns("name", "password")
+		_, err := q.Update()
+		Expect(err).To(MatchError(`pg: model=UpdateColumnsTest does not have column=password`))
+
+		q = NewQuery(nil, &UpdateColumnsTest{}).UpdateColumns()
+		_, err = q.Update()
+		Expect(err).To(MatchError("pg: UpdateColumns requires at least one column"))
+	})
+
+	It("sets auto_now and auto_now_add fields", func() {
+		created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		model := &AutoNowUpdateTest{Id: 1, CreatedAt: created}
+
+		err := NewQuery(nil, model).setAutoNow(false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.CreatedAt).To(Equal(created))
+		Expect(model.UpdatedAt).NotTo(BeNil())
+
+		models := []AutoNowUpdateTest{{Id: 1}, {Id: 2, CreatedAt: created}}
+		err = NewQuery(nil, &models).setAutoNow(true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(models[0].CreatedAt.IsZero()).To(BeFalse())
+		Expect(models[0].UpdatedAt).NotTo(BeNil())
+		Expect(models[1].CreatedAt).To(Equal(created))
+		Expect(models[1].UpdatedAt).NotTo(BeNil())
+	})
+
+	It("updates empty JSON fields with omitempty as NULL", func() {
+		q := NewQuery(nil, &JSONOmitEmptyUpdateTest{Id: 1, MetaPtr: &JSONMeta{}}).WherePK()
+
+		s := updateQueryString(q)
+		Expect(s).To(Equal(`UPDATE "json_omit_empty_update_tests" AS "json_omit_empty_update_test" SET "meta" = NULL, "meta_ptr" = NULL, "raw" = '{}', "raw_ptr" = NULL, "name" = '""' WHERE "json_omit_empty_update_test"."id" = 1`))
+
+		q = NewQuery(nil, &JSONOmitEmptyUpdateTest{
+			Id:      1,
+			Meta:    JSONMeta{Score: 1},
+			MetaPtr: &JSONMeta{Tags: []string{"a"}},
+			RawPtr:  &JSONMeta{},
+			Name:    "foo",
+		}).WherePK()
+
+		s = updateQueryString(q)
+		Expect(s).To(Equal(`UPDATE "json_omit_empty_update_tests" AS "json_omit_empty_update_test" SET "meta" = '{"Score":1}', "meta_ptr" = '{"Tags":["a"]}', "raw" = '{}', "raw_ptr" = '{}', "name" = '"foo"' WHERE "json_omit_empty_update_test"."id" = 1`))
+	})
+
+	It("scans NULL into JSON fields", func() {
+		table := GetTable(reflect.TypeOf(JSONOmitEmptyUpdateTest{}))
+		Expect(table.FieldsMap["name"].SQLType).To(Equal("jsonb"))
+
+		model := &JSONOmitEmptyUpdateTest{
+			Meta:    JSONMeta{Score: 1},
+			MetaPtr: &JSONMeta{Score: 1},
+		}
+		strct := reflect.ValueOf(model).Elem()
+
+		for _, column := range []string{"meta", "meta_ptr"} {
+			err := table.FieldsMap[column].ScanValue(strct, pool.NewBytesReader(nil), -1)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(model.Meta).To(Equal(JSONMeta{}))
+		Expect(model.MetaPtr).To(BeNil())
+
+		b := []byte(`"bar"`)
+		err := table.FieldsMap["name"].ScanValue(strct, pool.NewBytesReader(b), len(b))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Name).To(Equal("bar"))
+	})
+
+	It("updates models with composite primary key", func() {
+		q := NewQuery(nil, &CompositePKTest{TenantID: 1, ID: 2, Name: "a"}).WherePK()
+
+		s := updateQueryString(q)
+		Expect(s).To(Equal(`UPDATE "composite_pk_tests" AS "composite_pk_test" SET "name" = 'a' WHERE "composite_pk_test"."tenant_id" = 1 AND "composite_pk_test"."id" = 2`))
+
+		models := []CompositePKTest{{TenantID: 1, ID: 2, Name: "a"}, {TenantID: 3, ID: 4, Name: "b"}}
+		s = updateQueryString(NewQuery(nil, &models))
+		Expect(s).To(Equal(`UPDATE "composite_pk_tests" AS "composite_pk_test" SET "name" = _data."name" FROM (VALUES (1::bigint, 2::bigint, 'a'::text), (3::bigint, 4::bigint, 'b'::text)) AS _data("tenant_id", "id", "name") WHERE "composite_pk_test"."tenant_id" = _data."tenant_id" AND "composite_pk_test"."id" = _data."id"`))
+	})
+
+	It("scans bulk update RETURNING rows by primary key", func() {
+		models := []UpdateTest{{Id: 1, Value: "a"}, {Id: 2, Value: "b"}, {Id: 3, Value: "c"}}
+		q := NewQuery(nil, &models)
+		m := newBulkUpdateModel(q.tableModel.(*sliceTableModel))
+
+		scanRow := func(values ...string) error {
+			row := m.NextColumnScanner()
+			for i := 0; i < len(values); i += 2 {
+				col := types.ColumnInfo{Name: values[i]}
+				b := []byte(values[i+1])
+				if err := row.ScanColumn(col, pool.NewBytesReader(b), len(b)); err != nil {
+					return err
+				}
+			}
+			return row.(AfterScanHook).AfterScan(context.Background())
+		}
+
+		// RETURNING * includes the VALUES columns after the table columns.
+		Expect(scanRow("id", "3", "value", "updated c", "id", "3", "value", "c")).To(Succeed())
+		Expect(scanRow("value", "updated a", "id", "1")).To(Succeed())
+		Expect(models).To(Equal([]UpdateTest{
+			{Id: 1, Value: "updated a"},
+			{Id: 2, Value: "b"},
+			{Id: 3, Value: "updated c"},
+		}))
+
+		err := scanRow("value", "x")
+		Expect(err).To(MatchError(`pg: bulk update RETURNING must include "id"`))
+	})
+
+	It("supports UpdateFrom with a subquery", func() {
+		src := NewQuery(nil, &SerialUpdateTest{}).Column("id", "value").Where("value != ?", "")
+		q := NewQuery(nil, (*UpdateTest)(nil)).
+			UpdateFrom(src, "s").
+			SetFrom("s", "value").
+			Where("update_test.id = s.id")
+
+		s := updateQueryString(q)
+		Expect(s).To(Equal(`UPDATE "update_tests" AS "update_test" SET "value" = "s"."value" FROM (SELECT "id", "value" FROM "serial_update_tests" AS "serial_update_test" WHERE (value != '')) AS "s" WHERE (update_test.id = s.id)`))
+	})
+
+	It("supports UpdateFrom with a table and a model", func() {
+		q := NewQuery(nil, (*UpdateTest)(nil)).
+			UpdateFrom("src_table", "s").
+			UpdateFrom((*SerialUpdateTest)(nil), "t").
+			Set("value = s.value || t.value").
+			Where("update_test.id = s.id AND s.id = t.id")
+
+		s := updateQueryString(q)
+		Expect(s).To(Equal(`UPDATE "update_tests" AS "update_test" SET value = s.value || t.value FROM "src_table" AS "s", "serial_update_tests" AS "t" WHERE (update_test.id = s.id AND s.id = t.id)`))
+	})
+
+	It("returns an error for unsupported UpdateFrom source", func() {
+		q := NewQuery(nil, (*UpdateTest)(nil)).UpdateFrom(123, "s")
+
+		_, err := NewUpdateQuery(q, false).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: UpdateFrom does not support int"))
+	})
 })
 
 func updateQueryString(q *Query) string {
@@ -0,0 +1,135 @@
+package orm
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+// UpsertAction is the action INSERT ... ON CONFLICT took for a row.
+// See Query.ReturningAction.
+type UpsertAction uint8
+
+const (
+	UpsertInserted UpsertAction = iota + 1
+	UpsertUpdated
+)
+
+func (a UpsertAction) String() string {
+	switch a {
+	case UpsertInserted:
+		return "inserted"
+	case UpsertUpdated:
+		return "updated"
+	}
+	return fmt.Sprintf("UpsertAction(%d)", uint8(a))
+}
+
+const upsertActionColumn = "_upsert_inserted"
+
+func checkUpsertActions(actions interface{}) error {
+	switch actions.(type) {
+	case *[]UpsertAction, *[]bool:
+		return nil
+	}
+	return fmt.Errorf("pg: ReturningAction(unsupported %T)", actions)
+}
+
+// upsertActionModel scans the upsert action column into the actions
+// slice and the rest of the columns into the model.
+type upsertActionModel struct {
+	Model
+	actions interface{}
+	start   int
+}
+
+func newUpsertActionModel(model Model, actions interface{}) Model {
+	m := &upsertActionModel{
+		Model:   model,
+		actions: actions,
+		start:   upsertActionsLen(actions),
+	}
+	if _, ok := model.(useQueryOne); ok {
+		return upsertActionOneModel{m}
+	}
+	return m
+}
+
+func (m *upsertActionModel) Init() error {
+	switch actions := m.actions.(type) {
+	case *[]UpsertAction:
+		*actions = (*actions)[:m.start]
+	case *[]bool:
+		*actions = (*actions)[:m.start]
+	}
+	return m.Model.Init()
+}
+
+func (m *upsertActionModel) NextColumnScanner() ColumnScanner {
+	return &upsertActionScanner{
+		ColumnScanner: m.Model.NextColumnScanner(),
+		actions:       m.actions,
+	}
+}
+
+func (m *upsertActionModel) AddColumnScanner(s ColumnScanner) error {
+	if s, ok := s.(*upsertActionScanner); ok {
+		return m.Model.AddColumnScanner(s.ColumnScanner)
+	}
+	return m.Model.AddColumnScanner(s)
+}
+
+type upsertActionOneModel struct {
+	*upsertActionModel
+}
+
+func (upsertActionOneModel) useQueryOne() bool {
+	return true
+}
+
+type upsertActionScanner struct {
+	ColumnScanner
+	actions interface{}
+}
+
+func (s *upsertActionScanner) ScanColumn(col types.ColumnInfo, rd types.Reader, n int) error {
+	if col.Name != upsertActionColumn {
+		return s.ColumnScanner.ScanColumn(col, rd, n)
+	}
+
+	inserted, err := types.ScanBool(rd, n)
+	if err != nil {
+		return err
+	}
+
+	switch actions := s.actions.(type) {
+	case *[]UpsertAction:
+		action := UpsertUpdated
+		if inserted {
+			action = UpsertInserted
+		}
+		*actions = append(*actions, action)
+	case *[]bool:
+		*actions = append(*actions, inserted)
+	}
+	return nil
+}
+
+func upsertActionsLen(actions interface{}) int {
+	switch actions := actions.(type) {
+	case *[]UpsertAction:
+		return len(*actions)
+	case *[]bool:
+		return len(*actions)
+	}
+	return 0
+}
+
+func resetUpsertActions(actions interface{}) {
+	switch actions := actions.(type) {
+	case *[]UpsertAction:
+		*actions = (*actions)[:0]
+	case *[]bool:
+		*actions = (*actions)[:0]
+	}
+}
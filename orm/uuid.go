@@ -0,0 +1,138 @@
+package orm
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+var (
+	driverValuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	valueAppenderType = reflect.TypeOf((*types.ValueAppender)(nil)).Elem()
+	valueScannerType  = reflect.TypeOf((*types.ValueScanner)(nil)).Elem()
+)
+
+// isUUIDArray reports whether typ is a [16]byte that does not encode itself,
+// e.g. a plain [16]byte. Types like github.com/google/uuid.UUID implement
+// driver.Valuer and sql.Scanner and are handled by the types package.
+func isUUIDArray(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Array || typ.Len() != 16 || typ.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	ptr := reflect.PtrTo(typ)
+	return !typ.Implements(driverValuerType) && !ptr.Implements(driverValuerType) &&
+		!typ.Implements(valueAppenderType) && !ptr.Implements(valueAppenderType) &&
+		!isScanner(typ) && !ptr.Implements(valueScannerType)
+}
+
+func appendUUIDValue(b []byte, v reflect.Value, flags int) []byte {
+	var u [16]byte
+	for i := range u {
+		u[i] = byte(v.Index(i).Uint())
+	}
+	if flags == 1 {
+		b = append(b, '\'')
+	}
+	b = appendUUID(b, u)
+	if flags == 1 {
+		b = append(b, '\'')
+	}
+	return b
+}
+
+func scanUUIDValue(v reflect.Value, rd types.Reader, n int) error {
+	if n == -1 {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	tmp, err := rd.ReadFullTemp()
+	if err != nil {
+		return err
+	}
+
+	u, err := parseUUID(tmp)
+	if err != nil {
+		return err
+	}
+	reflect.Copy(v, reflect.ValueOf(u[:]))
+	return nil
+}
+
+func appendUUID(b []byte, u [16]byte) []byte {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return append(b, buf[:]...)
+}
+
+func parseUUID(b []byte) ([16]byte, error) {
+	var u [16]byte
+	var src [32]byte
+
+	n := 0
+	for _, c := range b {
+		if c == '-' {
+			continue
+		}
+		if n == len(src) {
+			return u, fmt.Errorf("pg: invalid uuid %q", b)
+		}
+		src[n] = c
+		n++
+	}
+	if n != len(src) {
+		return u, fmt.Errorf("pg: invalid uuid %q", b)
+	}
+
+	if _, err := hex.Decode(u[:], src[:]); err != nil {
+		return u, fmt.Errorf("pg: invalid uuid %q", b)
+	}
+	return u, nil
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() ([16]byte, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return u, err
+	}
+	u[6] = u[6]&0x0f | 0x40 // version 4
+	u[8] = u[8]&0x3f | 0x80 // variant 10
+	return u, nil
+}
+
+func setUUIDFieldFunc(typ reflect.Type) func(fv reflect.Value) error {
+	switch {
+	case typ.Kind() == reflect.String:
+		return func(fv reflect.Value) error {
+			u, err := newUUID()
+			if err != nil {
+				return err
+			}
+			fv.SetString(string(appendUUID(nil, u)))
+			return nil
+		}
+	case typ.Kind() == reflect.Array && typ.Len() == 16 && typ.Elem().Kind() == reflect.Uint8:
+		return func(fv reflect.Value) error {
+			u, err := newUUID()
+			if err != nil {
+				return err
+			}
+			reflect.Copy(fv, reflect.ValueOf(u[:]))
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package orm
+
+import (
+	"reflect"
+
+	"github.com/go-pg/pg/v10/internal/pool"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type UUIDDefaultModel struct {
+	ID    string `pg:"type:uuid,default:gen_random_uuid()"`
+	Value string
+}
+
+type UUIDGenerateModel struct {
+	ID    string   `pg:",uuid,generate"`
+	Ref   [16]byte `pg:",uuid,generate"`
+	Other [16]byte `pg:",type:uuid"`
+}
+
+type InvalidUUIDGenerateModel struct {
+	ID int `pg:",uuid,generate"`
+}
+
+const uuidV4Pattern = `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`
+
+var _ = Describe("uuid", func() {
+	It("uses the server default", func() {
+		q := NewQuery(nil, &UUIDDefaultModel{Value: "hello"})
+
+		s := queryString(NewCreateTableQuery(q, nil))
+		Expect(s).To(Equal(`CREATE TABLE "uuid_default_models" ("id" uuid DEFAULT gen_random_uuid(), "value" text, PRIMARY KEY ("id"))`))
+
+		s = queryString(NewInsertQuery(q))
+		Expect(s).To(Equal(`INSERT INTO "uuid_default_models" ("id", "value") VALUES (DEFAULT, 'hello') RETURNING "id"`))
+	})
+
+	It("encodes and decodes [16]byte", func() {
+		table := GetTable(reflect.TypeOf(UUIDGenerateModel{}))
+		Expect(table.FieldsMap["ref"].SQLType).To(Equal("uuid"))
+		Expect(table.FieldsMap["other"].SQLType).To(Equal("uuid"))
+
+		model := &UUIDGenerateModel{
+			Other: [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00},
+		}
+		strct := reflect.ValueOf(model).Elem()
+
+		b := table.FieldsMap["other"].AppendValue(nil, strct, 1)
+		Expect(string(b)).To(Equal(`'123e4567-e89b-12d3-a456-426614174000'`))
+
+		model.Other = [16]byte{}
+		src := []byte("123e4567-e89b-12d3-a456-426614174000")
+		err := table.FieldsMap["other"].ScanValue(strct, pool.NewBytesReader(src), len(src))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(model.Other[0]).To(Equal(byte(0x12)))
+		Expect(string(table.FieldsMap["other"].AppendValue(nil, strct, 0))).To(Equal(string(src)))
+
+		src = []byte("123e4567")
+		err = table.FieldsMap["other"].ScanValue(strct, pool.NewBytesReader(src), len(src))
+		Expect(err).To(MatchError(`pg: invalid uuid "123e4567"`))
+	})
+
+	It("generates zero uuids on insert", func() {
+		model := &UUIDGenerateModel{}
+		q := NewQuery(nil, model)
+		Expect(q.setUUIDs()).NotTo(HaveOccurred())
+
+		Expect(model.ID).To(MatchRegexp(uuidV4Pattern))
+		Expect(string(appendUUID(nil, model.Ref))).To(MatchRegexp(uuidV4Pattern))
+		Expect(model.Other).To(Equal([16]byte{}))
+
+		id, ref := model.ID, model.Ref
+		Expect(q.setUUIDs()).NotTo(HaveOccurred())
+		Expect(model.ID).To(Equal(id))
+		Expect(model.Ref).To(Equal(ref))
+	})
+
+	It("generates uuids for slices", func() {
+		models := []UUIDGenerateModel{{ID: "keep"}, {}}
+		q := NewQuery(nil, &models)
+		Expect(q.setUUIDs()).NotTo(HaveOccurred())
+
+		Expect(models[0].ID).To(Equal("keep"))
+		Expect(models[1].ID).To(MatchRegexp(uuidV4Pattern))
+		Expect(models[0].Ref).NotTo(Equal(models[1].Ref))
+	})
+
+	It("panics on unsupported field type", func() {
+		Expect(func() {
+			GetTable(reflect.TypeOf(InvalidUUIDGenerateModel{}))
+		}).To(Panic())
+	})
+})
@@ -0,0 +1,107 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+// ValuesQuery is a VALUES list built from a slice of structs that can be used
+// as a table expression, e.g. in Join or TableExpr:
+//
+//    rows := []Row{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+//    q.Join("JOIN ? ON v.id = book.id", NewValuesQuery(rows).As("v"))
+//
+// generates
+//
+//    JOIN (VALUES (1::bigint, 'a'::text), (2::bigint, 'b'::text)) AS "v" ("id", "name")
+//    ON v.id = book.id
+//
+// Columns are the struct fields and every value is cast to the field SQL type.
+type ValuesQuery struct {
+	slice  reflect.Value
+	fields []*Field
+	alias  string
+	err    error
+}
+
+var _ QueryAppender = (*ValuesQuery)(nil)
+
+// NewValuesQuery returns a VALUES list for the slice of structs or
+// pointers to structs.
+func NewValuesQuery(rows interface{}) *ValuesQuery {
+	v := reflect.Indirect(reflect.ValueOf(rows))
+	if v.Kind() != reflect.Slice || indirectType(v.Type().Elem()).Kind() != reflect.Struct {
+		return &ValuesQuery{
+			err: fmt.Errorf("pg: Values(unsupported %T)", rows),
+		}
+	}
+
+	table := GetTable(indirectType(v.Type().Elem()))
+	return &ValuesQuery{
+		slice:  v,
+		fields: table.Fields,
+	}
+}
+
+// As sets the alias of the VALUES list. The alias is followed by the list
+// of column names so the columns can be referenced as alias.column.
+func (q *ValuesQuery) As(alias string) *ValuesQuery {
+	q.alias = alias
+	return q
+}
+
+func (q *ValuesQuery) AppendQuery(fmter QueryFormatter, b []byte) ([]byte, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	if q.slice.Len() == 0 {
+		return nil, errors.New("pg: Values requires at least one row")
+	}
+
+	isPlaceholder := isTemplateFormatter(fmter)
+
+	b = append(b, "(VALUES "...)
+	for i := 0; i < q.slice.Len(); i++ {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+
+		strct := indirect(q.slice.Index(i))
+		if !strct.IsValid() {
+			return nil, fmt.Errorf("pg: Values got nil row at index %d", i)
+		}
+
+		b = append(b, '(')
+		for j, f := range q.fields {
+			if j > 0 {
+				b = append(b, ", "...)
+			}
+			if isPlaceholder {
+				b = append(b, '?')
+			} else {
+				b = f.AppendValue(b, strct, 1)
+			}
+			b = append(b, "::"...)
+			b = append(b, f.SQLType...)
+		}
+		b = append(b, ')')
+
+		if isPlaceholder {
+			break
+		}
+	}
+	b = append(b, ')')
+
+	if q.alias != "" {
+		b = append(b, " AS "...)
+		b = types.AppendIdent(b, q.alias, 1)
+		b = append(b, " ("...)
+		b = appendColumns(b, "", q.fields)
+		b = append(b, ')')
+	}
+
+	return b, nil
+}
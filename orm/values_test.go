@@ -0,0 +1,40 @@
+package orm
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type ValuesRow struct {
+	ID   int
+	Name string
+}
+
+var _ = Describe("Values", func() {
+	It("joins a VALUES list", func() {
+		rows := []ValuesRow{{ID: 1, Name: "a"}, {ID: 2, Name: "it's"}}
+		q := NewQuery(nil, &SelectModel{}).
+			Column("select_model.id").
+			Join("JOIN ? ON v.id = select_model.id", NewValuesQuery(rows).As("v")).
+			Order("v.name")
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT "select_model"."id" FROM "select_models" AS "select_model" JOIN (VALUES (1::bigint, 'a'::text), (2::bigint, 'it''s'::text)) AS "v" ("id", "name") ON v.id = select_model.id ORDER BY "v"."name"`))
+	})
+
+	It("selects from a VALUES list of pointers", func() {
+		rows := []*ValuesRow{{ID: 1}}
+		q := NewQuery(nil).TableExpr("?", NewValuesQuery(&rows).As("v"))
+
+		s := selectQueryString(q)
+		Expect(s).To(Equal(`SELECT * FROM (VALUES (1::bigint, NULL::text)) AS "v" ("id", "name")`))
+	})
+
+	It("returns an error for empty and unsupported rows", func() {
+		_, err := NewValuesQuery([]ValuesRow{}).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: Values requires at least one row"))
+
+		_, err = NewValuesQuery([]int{1}).AppendQuery(defaultFmter, nil)
+		Expect(err).To(MatchError("pg: Values(unsupported []int)"))
+	})
+})
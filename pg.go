@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"strconv"
+	"time"
 
 	"github.com/go-pg/pg/v10/internal"
 	"github.com/go-pg/pg/v10/orm"
@@ -17,16 +18,79 @@ var Discard orm.Discard
 // PostgreSQL NULL.
 type NullTime = types.NullTime
 
+// Inet represents PostgreSQL inet type that holds an IPv4 or IPv6 host
+// address with an optional netmask.
+type Inet = types.Inet
+
+// CIDR represents PostgreSQL cidr type that holds an IPv4 or IPv6 network.
+type CIDR = types.CIDR
+
+// ParseInet parses inet value, e.g. 192.168.0.1 or 192.168.0.1/24.
+func ParseInet(s string) (Inet, error) {
+	return types.ParseInet(s)
+}
+
+// ParseCIDR parses cidr value, e.g. 192.168.0.0/24.
+func ParseCIDR(s string) (CIDR, error) {
+	return types.ParseCIDR(s)
+}
+
+// Numeric represents PostgreSQL numeric type with arbitrary precision.
+type Numeric = types.Numeric
+
+// ParseNumeric parses numeric value, e.g. 123.4500.
+func ParseNumeric(s string) (Numeric, error) {
+	return types.ParseNumeric(s)
+}
+
+// Grouping returns GROUPING(columns...) expression that distinguishes
+// subtotal rows produced by ROLLUP, CUBE, and GROUPING SETS from NULL values.
+func Grouping(columns ...string) types.Safe {
+	return orm.Grouping(columns...)
+}
+
+// HstoreGet returns `column -> key` expression that selects the value
+// of the hstore key.
+func HstoreGet(column, key string) types.Safe {
+	return orm.HstoreGet(column, key)
+}
+
+// WithAllDeleted returns a copy of the context that disables filtering
+// of soft deleted rows in queries and their relations.
+// See orm.WithAllDeleted for details.
+func WithAllDeleted(ctx context.Context) context.Context {
+	return orm.WithAllDeleted(ctx)
+}
+
 // Scan returns ColumnScanner that copies the columns in the
 // row into the values.
 func Scan(values ...interface{}) orm.ColumnScanner {
 	return orm.Scan(values...)
 }
 
-// Safe represents a safe SQL query.
+// ScanPositional returns ColumnScanner that copies the columns in the
+// row into the fields of dest by position instead of by name. Dest must
+// be a pointer to a struct or a slice of structs, and the query must
+// return exactly one column per field:
+//
+//    var res []struct {
+//        Name  string
+//        Total int
+//    }
+//    _, err := db.Query(pg.ScanPositional(&res),
+//        "SELECT lower(name), count(*) FROM users GROUP BY 1")
+func ScanPositional(dest interface{}) orm.ColumnScanner {
+	return orm.ScanPositional(dest)
+}
+
+// Safe represents a safe SQL query. It is appended to the query verbatim
+// wherever it is used as a param, so it must never contain user input.
 type Safe = types.Safe
 
 // Ident represents a SQL identifier, e.g. table or column name.
+// Ident is quoted wherever it is used as a param, e.g. in ColumnExpr,
+// TableExpr, Where, or Set. Dots separate qualified names and a "*"
+// segment is kept as a wildcard, e.g. Ident("book.*") is "book".*.
 type Ident = types.Ident
 
 // SafeQuery replaces any placeholders found in the query.
@@ -42,6 +106,10 @@ func SafeQuery(query string, params ...interface{}) *orm.SafeQueryAppender {
 // produces
 //
 //    WHERE id IN (1, 2, 3, 4)
+//
+// Empty slice produces `IN (NULL)` that matches no rows. It also matches
+// no rows in `NOT IN (?)`, so use Query.WhereNotIn for the negated form.
+// Nested slices and nested In values are enclosed in parentheses.
 func In(slice interface{}) types.ValueAppender {
 	return types.In(slice)
 }
@@ -58,6 +126,48 @@ func InMulti(values ...interface{}) types.ValueAppender {
 	return types.InMulti(values...)
 }
 
+// Values accepts a slice of structs and returns a VALUES list with
+// a value cast to the field type for every column. It can be joined
+// or selected from like a table:
+//
+//    q.Join("JOIN ? ON v.id = book.id", pg.Values(rows).As("v"))
+//
+// produces
+//
+//    JOIN (VALUES (1::bigint, 'a'::text), (2::bigint, 'b'::text)) AS "v" ("id", "name")
+//    ON v.id = book.id
+func Values(rows interface{}) *orm.ValuesQuery {
+	return orm.NewValuesQuery(rows)
+}
+
+// GenerateSeries returns a generate_series call that can be joined or
+// selected from like a table. Bounds are integers with an optional integer
+// step, or time.Time values with an interval step, e.g. "1 day" or
+// time.Duration:
+//
+//    q.TableExpr("?", pg.GenerateSeries(start, stop, "1 day").As("day"))
+//
+// produces
+//
+//    FROM generate_series('2020-01-01 00:00:00+00:00:00'::timestamptz,
+//    '2020-01-07 00:00:00+00:00:00'::timestamptz, '1 day'::interval) AS "day" ("day")
+func GenerateSeries(start, stop, step interface{}) *orm.SeriesQuery {
+	return orm.NewSeriesQuery(start, stop, step)
+}
+
+// Unnest returns an unnest call that expands the slice to a set of rows
+// and can be joined or selected from like a table. WithOrdinality adds
+// the ordinality column with the position of every element:
+//
+//    q.TableExpr("?", pg.Unnest(tags).As("tag").WithOrdinality())
+//
+// produces
+//
+//    FROM unnest('{"b","a"}'::text[]) WITH ORDINALITY AS "tag" ("tag", "ordinality")
+func Unnest(slice interface{}) *orm.UnnestQuery {
+	return orm.NewUnnestQuery(slice)
+}
+
 // Array accepts a slice and returns a wrapper for working with PostgreSQL
 // array data type.
 //
@@ -71,14 +181,46 @@ func Array(v interface{}) *types.Array {
 // Hstore accepts a map and returns a wrapper for working with hstore data type.
 // Supported map types are:
 //   - map[string]string
+//   - map[string]*string, where nil values are NULL
 //
 // For struct fields you can use hstore tag:
 //
 //    Attrs map[string]string `pg:",hstore"`
+//
+// Use map[string]*string to tell NULL values apart from missing keys;
+// map[string]string scans NULL values as empty strings.
+// hstore type requires `CREATE EXTENSION hstore`.
 func Hstore(v interface{}) *types.Hstore {
 	return types.NewHstore(v)
 }
 
+// ByteaWriter returns a scanner that streams bytea column value to the
+// writer without loading the whole value into the memory:
+//
+//    f, err := os.Create("blob.bin")
+//    _, err = db.QueryOne(pg.Scan(pg.ByteaWriter(f)), "SELECT data FROM blobs WHERE id = ?", id)
+func ByteaWriter(w io.Writer) *types.ByteaWriter {
+	return types.NewByteaWriter(w)
+}
+
+// ByteaReader returns an appender that encodes data read from the reader
+// as bytea. The reader is consumed by the first query, so the appender can't
+// be reused and queries using it fail instead of being retried:
+//
+//    _, err := db.Exec("INSERT INTO blobs (data) VALUES (?)", pg.ByteaReader(f))
+func ByteaReader(r io.Reader) *types.ByteaReader {
+	return types.NewByteaReader(r)
+}
+
+// Timestamp returns an appender that formats tm as timestamp without time
+// zone using the wall clock of tm in its own location. Use it for params
+// compared with timestamp columns, because time.Time params are sent in UTC:
+//
+//    err := db.Model(&events).Where("starts_at = ?", pg.Timestamp(tm)).Select()
+func Timestamp(tm time.Time) types.Timestamp {
+	return types.Timestamp{Time: tm}
+}
+
 // SetLogger sets the logger to the given one.
 func SetLogger(logger internal.Logging) {
 	internal.Logger = logger
@@ -88,6 +230,9 @@ func SetLogger(logger internal.Logging) {
 
 type Query = orm.Query
 
+// RowError is returned by Query.SelectLenient for rows that can't be scanned.
+type RowError = orm.RowError
+
 // Model returns a new query for the optional model.
 func Model(model ...interface{}) *Query {
 	return orm.NewQuery(nil, model...)
@@ -0,0 +1,187 @@
+package pg
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-pg/pg/v10/internal"
+	"github.com/go-pg/pg/v10/internal/pool"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// replicaSet load balances read queries across read replicas in
+// round-robin order skipping replicas that failed a health check.
+type replicaSet struct {
+	replicas []*replica
+	next     uint32
+
+	exit      chan struct{}
+	closeOnce sync.Once
+}
+
+type replica struct {
+	addr string
+	pool *pool.ConnPool
+	down uint32 // atomic
+}
+
+func newReplicaSet(db *baseDB) *replicaSet {
+	s := &replicaSet{
+		exit: make(chan struct{}),
+	}
+	for _, addr := range db.opt.ReplicaAddrs {
+		opt := *db.opt
+		opt.Addr = addr
+		// Dialing through Addrs remembers the address for TLS ServerName.
+		opt.Addrs = []string{addr}
+		s.replicas = append(s.replicas, &replica{
+			addr: addr,
+			pool: newConnPool(&opt),
+		})
+	}
+
+	if db.opt.ReplicaHealthCheckFrequency > 0 {
+		go s.healthCheck(db, db.opt.ReplicaHealthCheckFrequency)
+	}
+
+	return s
+}
+
+// pick returns the next healthy replica or nil if all replicas are down.
+func (s *replicaSet) pick() *replica {
+	n := uint32(len(s.replicas))
+	start := atomic.AddUint32(&s.next, 1)
+	for i := uint32(0); i < n; i++ {
+		r := s.replicas[(start+i)%n]
+		if r.healthy() {
+			return r
+		}
+	}
+	return nil
+}
+
+func (s *replicaSet) healthCheck(db *baseDB, frequency time.Duration) {
+	// Health checks are not reported to query hooks.
+	db = db.clone()
+	db.queryHooks = nil
+
+	ticker := time.NewTicker(frequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.checkReplicas(db, frequency)
+		case <-s.exit:
+			return
+		}
+	}
+}
+
+func (s *replicaSet) checkReplicas(db *baseDB, timeout time.Duration) {
+	for _, r := range s.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := db.withPool(r.pool).Ping(ctx)
+		cancel()
+
+		if err != nil {
+			if r.healthy() {
+				internal.Logger.Printf(ctx, "pg: replica %s is down: %s", r.addr, err)
+			}
+			r.markDown()
+		} else {
+			r.markUp()
+		}
+	}
+}
+
+func (s *replicaSet) Close() error {
+	var firstErr error
+	s.closeOnce.Do(func() {
+		close(s.exit)
+		for _, r := range s.replicas {
+			if err := r.pool.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	})
+	return firstErr
+}
+
+func (r *replica) healthy() bool {
+	return atomic.LoadUint32(&r.down) == 0
+}
+
+func (r *replica) markDown() {
+	atomic.StoreUint32(&r.down, 1)
+}
+
+func (r *replica) markUp() {
+	atomic.StoreUint32(&r.down, 0)
+}
+
+// isReplicaDown reports whether the query failed because the replica is not
+// reachable or is shutting down. Query timeouts are not counted, because
+// the same query would likely time out on the primary too.
+func isReplicaDown(err error) bool {
+	switch err {
+	case nil:
+		return false
+	case io.EOF, io.ErrUnexpectedEOF:
+		return true
+	}
+
+	switch err := err.(type) {
+	case *net.OpError:
+		return err.Op == "dial" || !err.Timeout()
+	case net.Error:
+		return !err.Timeout()
+	case Error:
+		return err.Field('V') == "FATAL"
+	}
+	return false
+}
+
+// replicaPool is the pool of a DB returned by Replica. Replica pools
+// are owned and closed by the primary DB.
+type replicaPool struct {
+	pool.Pooler
+}
+
+func (replicaPool) Close() error {
+	return nil
+}
+
+// readReplica returns the replica for the query or nil if the query must
+// run on the primary.
+func (db *baseDB) readReplica(query interface{}) *replica {
+	if db.replicas == nil {
+		return nil
+	}
+	sel, ok := query.(*orm.SelectQuery)
+	if !ok || !sel.ReadOnly() {
+		return nil
+	}
+	return db.replicas.pick()
+}
+
+// Replica returns a DB that runs all queries on the next healthy read
+// replica, e.g. to run raw read queries that are not routed automatically.
+// It returns db itself when there are no healthy replicas.
+//
+// The returned DB must not be used for writes and does not need to be
+// closed: replica connections are closed together with db.
+func (db *DB) Replica() *DB {
+	if db.replicas == nil {
+		return db
+	}
+	r := db.replicas.pick()
+	if r == nil {
+		return db
+	}
+	return newDB(db.ctx, db.baseDB.withPool(replicaPool{r.pool}))
+}
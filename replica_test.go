@@ -0,0 +1,118 @@
+package pg
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/go-pg/pg/v10/internal"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+type replicaTestModel struct {
+	ID int
+}
+
+func TestReplicaSetPick(t *testing.T) {
+	s := &replicaSet{
+		replicas: []*replica{{addr: "a"}, {addr: "b"}, {addr: "c"}},
+	}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, s.pick().addr)
+	}
+	if want := []string{"b", "c", "a", "b"}; !equalStrings(got, want) {
+		t.Fatalf("got %v, wanted %v", got, want)
+	}
+
+	s.replicas[0].markDown()
+	s.replicas[2].markDown()
+	for i := 0; i < 3; i++ {
+		if r := s.pick(); r.addr != "b" {
+			t.Fatalf("got %s, wanted b", r.addr)
+		}
+	}
+
+	s.replicas[1].markDown()
+	if r := s.pick(); r != nil {
+		t.Fatalf("got %s, wanted nil", r.addr)
+	}
+
+	s.replicas[2].markUp()
+	if r := s.pick(); r.addr != "c" {
+		t.Fatalf("got %s, wanted c", r.addr)
+	}
+}
+
+func TestReadReplica(t *testing.T) {
+	db := &baseDB{
+		replicas: &replicaSet{
+			replicas: []*replica{{addr: "a"}},
+		},
+	}
+	newQuery := func() *orm.Query {
+		return orm.NewQuery(nil, (*replicaTestModel)(nil))
+	}
+
+	tests := []struct {
+		query   interface{}
+		replica bool
+	}{
+		{orm.NewSelectQuery(newQuery()), true},
+		{orm.NewSelectQuery(newQuery().With("q", newQuery())), true},
+		{orm.NewSelectQuery(newQuery().UsePrimary()), false},
+		{orm.NewSelectQuery(newQuery().For("UPDATE")), false},
+		{orm.NewSelectQuery(newQuery().WithDelete("q", newQuery())), false},
+		{orm.NewDeleteQuery(newQuery()), false},
+		{"SELECT 1", false},
+	}
+	for i, test := range tests {
+		r := db.readReplica(test.query)
+		if (r != nil) != test.replica {
+			t.Fatalf("#%d: got replica=%v, wanted %v", i, r != nil, test.replica)
+		}
+	}
+
+	if r := db.withPool(nil).readReplica(orm.NewSelectQuery(newQuery())); r != nil {
+		t.Fatal("withPool must not use replicas")
+	}
+}
+
+func TestIsReplicaDown(t *testing.T) {
+	tests := []struct {
+		err  error
+		down bool
+	}{
+		{nil, false},
+		{io.EOF, true},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{&net.OpError{Op: "read", Err: timeoutErr{}}, false},
+		{internal.ErrNoRows, false},
+		{errors.New("pg: can't find column"), false},
+	}
+	for i, test := range tests {
+		if got := isReplicaDown(test.err); got != test.down {
+			t.Fatalf("#%d: got %v, wanted %v", i, got, test.down)
+		}
+	}
+}
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -40,6 +40,14 @@ func (res *result) parse(b []byte) error {
 	return nil
 }
 
+// setCopyRows sets the number of rows counted by the client when the COPY
+// command tag does not contain the row count, e.g. on servers before 8.2.
+func (res *result) setCopyRows(rows int) {
+	if res.affected == -1 {
+		res.affected = rows
+	}
+}
+
 func (res *result) Model() orm.Model {
 	return res.model
 }
@@ -0,0 +1,23 @@
+package pg
+
+import "testing"
+
+func TestResultCopyRows(t *testing.T) {
+	var res result
+	if err := res.parse([]byte("COPY 3\x00")); err != nil {
+		t.Fatal(err)
+	}
+	res.setCopyRows(5)
+	if res.RowsAffected() != 3 {
+		t.Fatalf("got %d, wanted 3", res.RowsAffected())
+	}
+
+	res = result{}
+	if err := res.parse([]byte("COPY\x00")); err != nil {
+		t.Fatal(err)
+	}
+	res.setCopyRows(5)
+	if res.RowsAffected() != 5 {
+		t.Fatalf("got %d, wanted 5", res.RowsAffected())
+	}
+}
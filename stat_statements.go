@@ -0,0 +1,110 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+// StatStatement is a row of pg_stat_statements view.
+type StatStatement struct {
+	QueryID int64
+	Query   string
+	Calls   int64
+	Rows    int64
+	// TotalTime is the total time spent executing the statement.
+	TotalTime time.Duration
+	// MeanTime is the mean time spent executing the statement.
+	MeanTime time.Duration
+}
+
+// StatStatementsOptions configures StatStatements.
+type StatStatementsOptions struct {
+	// Limit is the maximum number of returned statements.
+	// Default is 10.
+	Limit int
+	// OrderBy is one of "total_time" (default), "mean_time", or "calls".
+	// Statements are sorted in descending order.
+	OrderBy string
+	// MinCalls excludes statements that were executed fewer times.
+	MinCalls int64
+}
+
+const statStatementsHasExecTimeQuery = `
+SELECT EXISTS (
+  SELECT 1
+  FROM pg_attribute
+  WHERE attrelid = 'pg_stat_statements'::regclass
+    AND attname = 'total_exec_time'
+    AND NOT attisdropped
+)
+`
+
+const statStatementsQuery = `
+SELECT
+  coalesce(queryid, 0) AS query_id,
+  query,
+  calls,
+  rows,
+  (? * 1000000)::bigint AS total_time,
+  (? * 1000000)::bigint AS mean_time
+FROM pg_stat_statements
+WHERE calls >= ?
+ORDER BY ? DESC
+LIMIT ?
+`
+
+// StatStatements returns the statements with the highest execution time
+// as reported by pg_stat_statements. Statement times are reported by
+// columns total_exec_time and mean_exec_time since PostgreSQL 13 and by
+// total_time and mean_time before that; both are supported.
+//
+// It requires pg_stat_statements extension that must be loaded via
+// shared_preload_libraries and created in the database:
+//
+//    CREATE EXTENSION IF NOT EXISTS pg_stat_statements
+func (db *baseDB) StatStatements(
+	ctx context.Context, opt *StatStatementsOptions,
+) ([]StatStatement, error) {
+	if opt == nil {
+		opt = new(StatStatementsOptions)
+	}
+
+	limit := opt.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	var orderBy types.Safe
+	switch opt.OrderBy {
+	case "", "total_time":
+		orderBy = "total_time"
+	case "mean_time":
+		orderBy = "mean_time"
+	case "calls":
+		orderBy = "calls"
+	default:
+		return nil, fmt.Errorf("pg: StatStatements does not support OrderBy=%q", opt.OrderBy)
+	}
+
+	var hasExecTime bool
+	_, err := db.QueryOneContext(ctx, Scan(&hasExecTime), statStatementsHasExecTimeQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	totalTime, meanTime := types.Safe("total_time"), types.Safe("mean_time")
+	if hasExecTime {
+		totalTime, meanTime = "total_exec_time", "mean_exec_time"
+	}
+
+	var stmts []StatStatement
+	_, err = db.QueryContext(ctx, &stmts, statStatementsQuery,
+		totalTime, meanTime, opt.MinCalls, orderBy, limit)
+	if err != nil {
+		return nil, err
+	}
+	return stmts, nil
+}
@@ -3,8 +3,11 @@ package pg
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/go-pg/pg/v10/internal"
+	"github.com/go-pg/pg/v10/internal/parser"
 	"github.com/go-pg/pg/v10/internal/pool"
 	"github.com/go-pg/pg/v10/orm"
 	"github.com/go-pg/pg/v10/types"
@@ -14,6 +17,10 @@ var errStmtClosed = errors.New("pg: statement is closed")
 
 // Stmt is a prepared statement. Stmt is safe for concurrent use by
 // multiple goroutines.
+//
+// When Options.DisablePreparedStatements is set, the statement is not
+// prepared on the server. Instead parameters are inlined into the query
+// and it is sent using the simple query protocol on every execution.
 type Stmt struct {
 	db        *baseDB
 	stickyErr error
@@ -39,6 +46,10 @@ func prepareStmt(db *baseDB, q string) (*Stmt, error) {
 }
 
 func (stmt *Stmt) prepare(ctx context.Context, q string) error {
+	if stmt.db.opt.DisablePreparedStatements {
+		return nil
+	}
+
 	var lastErr error
 	for attempt := 0; attempt <= stmt.db.opt.MaxRetries; attempt++ {
 		if attempt > 0 {
@@ -102,7 +113,11 @@ func (stmt *Stmt) exec(ctx context.Context, params ...interface{}) (Result, erro
 		}
 
 		lastErr = stmt.withConn(ctx, func(c context.Context, cn *pool.Conn) error {
-			res, err = stmt.extQuery(ctx, cn, stmt.name, params...)
+			if stmt.db.opt.DisablePreparedStatements {
+				res, err = stmt.simpleQuery(ctx, cn, nil, params...)
+			} else {
+				res, err = stmt.extQuery(ctx, cn, stmt.name, params...)
+			}
 			return err
 		})
 		if !stmt.db.shouldRetry(lastErr) {
@@ -167,7 +182,11 @@ func (stmt *Stmt) query(ctx context.Context, model interface{}, params ...interf
 		}
 
 		lastErr = stmt.withConn(ctx, func(c context.Context, cn *pool.Conn) error {
-			res, err = stmt.extQueryData(ctx, cn, stmt.name, model, stmt.columns, params...)
+			if stmt.db.opt.DisablePreparedStatements {
+				res, err = stmt.simpleQuery(ctx, cn, model, params...)
+			} else {
+				res, err = stmt.extQueryData(ctx, cn, stmt.name, model, stmt.columns, params...)
+			}
 			return err
 		})
 		if !stmt.db.shouldRetry(lastErr) {
@@ -275,8 +294,84 @@ func (stmt *Stmt) extQueryData(
 	return res, nil
 }
 
+// simpleQuery executes the statement using the simple query protocol.
+// The model is nil when results are discarded.
+func (stmt *Stmt) simpleQuery(
+	c context.Context, cn *pool.Conn, model interface{}, params ...interface{},
+) (Result, error) {
+	wb := pool.GetWriteBuffer()
+	defer pool.PutWriteBuffer(wb)
+
+	err := writeQueryMsg(wb, stmt.db.fmter, &inlineParamsQuery{
+		q:      stmt.q,
+		params: params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		return stmt.db.simpleQuery(c, cn, wb)
+	}
+	return stmt.db.simpleQueryData(c, cn, model, wb)
+}
+
 func (stmt *Stmt) closeStmt() error {
 	return stmt.withConn(context.TODO(), func(c context.Context, cn *pool.Conn) error {
 		return stmt.db.closeStmt(c, cn, stmt.name)
 	})
 }
+
+//------------------------------------------------------------------------------
+
+// inlineParamsQuery replaces positional parameters like $1 with the quoted
+// parameter values. Parameters inside string literals, quoted identifiers,
+// dollar-quoted strings, and comments are left untouched.
+type inlineParamsQuery struct {
+	q      string
+	params []interface{}
+}
+
+var _ orm.QueryAppender = (*inlineParamsQuery)(nil)
+
+func (q *inlineParamsQuery) AppendQuery(_ orm.QueryFormatter, b []byte) ([]byte, error) {
+	p := parser.NewString(q.q)
+	var prev byte
+	for p.Valid() {
+		if lit, ok := p.ReadLiteral(); ok {
+			b = append(b, lit...)
+			prev = 0
+			continue
+		}
+
+		c := p.Read()
+		// $ is also allowed in identifiers, e.g. a$1.
+		if c != '$' || !isDigit(p.Peek()) || isIdentByte(prev) {
+			b = append(b, c)
+			prev = c
+			continue
+		}
+
+		num := p.Bytes()
+		for isDigit(p.Peek()) {
+			p.Advance()
+		}
+		num = num[:len(num)-len(p.Bytes())]
+
+		n, err := strconv.Atoi(internal.BytesToString(num))
+		if err != nil || n < 1 || n > len(q.params) {
+			return nil, fmt.Errorf("pg: no value for parameter $%s", num)
+		}
+		b = types.Append(b, q.params[n-1], 1)
+		prev = 0
+	}
+	return b, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c) || c == '_' || c == '$'
+}
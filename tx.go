@@ -3,13 +3,16 @@ package pg
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/go-pg/pg/v10/internal"
 	"github.com/go-pg/pg/v10/internal/pool"
 	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-pg/pg/v10/types"
 )
 
 // ErrTxDone is returned by any operation that is performed on a transaction
@@ -25,7 +28,8 @@ var ErrTxDone = errors.New("pg: transaction has already been committed or rolled
 // with ErrTxDone.
 //
 // The statements prepared for a transaction by calling the transaction's
-// Prepare or Stmt methods are closed by the call to Commit or Rollback.
+// Prepare or Stmt methods and cursors declared with Cursor are closed
+// by the call to Commit or Rollback.
 type Tx struct {
 	db  *baseDB
 	ctx context.Context
@@ -33,6 +37,9 @@ type Tx struct {
 	stmtsMu sync.Mutex
 	stmts   []*Stmt
 
+	cursorsMu sync.Mutex
+	cursors   []*Cursor
+
 	_closed int32
 }
 
@@ -60,6 +67,12 @@ func (db *baseDB) BeginContext(ctx context.Context) (*Tx, error) {
 		return nil, err
 	}
 
+	if db.opt.OnTxBegin != nil {
+		if c := db.opt.OnTxBegin(ctx, tx); c != nil {
+			tx.ctx = c
+		}
+	}
+
 	return tx, nil
 }
 
@@ -71,7 +84,7 @@ func (db *baseDB) RunInTransaction(ctx context.Context, fn func(*Tx) error) erro
 	if err != nil {
 		return err
 	}
-	return tx.RunInTransaction(ctx, fn)
+	return tx.RunInTransaction(tx.ctx, fn)
 }
 
 // Begin returns current transaction. It does not start new transaction.
@@ -280,12 +293,12 @@ func (tx *Tx) queryOne(
 
 // Model is an alias for DB.Model.
 func (tx *Tx) Model(model ...interface{}) *Query {
-	return orm.NewQuery(tx, model...)
+	return tx.db.withMaxRows(orm.NewQuery(tx, model...))
 }
 
 // ModelContext acts like Model but additionally receives a context.
 func (tx *Tx) ModelContext(c context.Context, model ...interface{}) *Query {
-	return orm.NewQueryContext(c, tx, model...)
+	return tx.db.withMaxRows(orm.NewQueryContext(c, tx, model...))
 }
 
 // CopyFrom is an alias for DB.CopyFrom.
@@ -306,11 +319,107 @@ func (tx *Tx) CopyTo(w io.Writer, query interface{}, params ...interface{}) (res
 	return res, err
 }
 
+// CopyToQuery is an alias for DB.CopyToQuery.
+func (tx *Tx) CopyToQuery(c context.Context, model interface{}, q *Query) (res Result, err error) {
+	err = tx.withConn(c, func(c context.Context, cn *pool.Conn) error {
+		res, err = tx.db.copyToQuery(c, cn, model, q)
+		return err
+	})
+	return res, err
+}
+
+// ImportCSV is an alias for DB.ImportCSV.
+func (tx *Tx) ImportCSV(
+	c context.Context, table string, r io.Reader, opt *ImportOptions,
+) (res Result, err error) {
+	src, err := newCSVImportSource(r, opt)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.withConn(c, func(c context.Context, cn *pool.Conn) error {
+		res, err = tx.db.importFrom(c, cn, table, src)
+		return err
+	})
+	return res, err
+}
+
+// ImportJSONLines is an alias for DB.ImportJSONLines.
+func (tx *Tx) ImportJSONLines(
+	c context.Context, table string, r io.Reader, opt *ImportOptions,
+) (res Result, err error) {
+	src, err := newJSONImportSource(r, opt)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.withConn(c, func(c context.Context, cn *pool.Conn) error {
+		res, err = tx.db.importFrom(c, cn, table, src)
+		return err
+	})
+	return res, err
+}
+
 // Formatter is an alias for DB.Formatter.
 func (tx *Tx) Formatter() orm.QueryFormatter {
 	return tx.db.Formatter()
 }
 
+// SetConstraints sets the checking mode (DEFERRED or IMMEDIATE) of the named
+// constraints for the current transaction. All deferrable constraints are
+// affected when no names are given.
+func (tx *Tx) SetConstraints(ctx context.Context, mode string, constraints ...string) error {
+	switch mode {
+	case "DEFERRED", "IMMEDIATE":
+	default:
+		return fmt.Errorf("pg: unsupported constraint mode=%q", mode)
+	}
+
+	b := []byte("SET CONSTRAINTS ")
+	if len(constraints) == 0 {
+		b = append(b, "ALL"...)
+	}
+	for i, name := range constraints {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = types.AppendIdent(b, name, 1)
+	}
+	b = append(b, ' ')
+	b = append(b, mode...)
+
+	_, err := tx.ExecContext(ctx, internal.BytesToString(b))
+	return err
+}
+
+// DeferConstraints defers checking of all deferrable constraints
+// until the transaction is committed.
+func (tx *Tx) DeferConstraints(ctx context.Context) error {
+	return tx.SetConstraints(ctx, "DEFERRED")
+}
+
+// SetConfig sets the configuration parameter, e.g. a custom setting like
+// app.current_user_id used by row-level security policies. Name and value
+// are passed to set_config() as query params. When local is true the
+// setting only lasts until the end of the current transaction.
+func (tx *Tx) SetConfig(ctx context.Context, name string, value interface{}, local bool) error {
+	_, err := tx.ExecContext(ctx, "SELECT set_config(?, ?::text, ?)", name, value, local)
+	return err
+}
+
+// CurrentSetting scans the current value of the configuration parameter
+// into value. Unset and empty settings are scanned as NULL.
+func (tx *Tx) CurrentSetting(ctx context.Context, name string, value interface{}) error {
+	_, err := tx.QueryOneContext(
+		ctx, Scan(value), "SELECT NULLIF(current_setting(?, true), '')", name)
+	return err
+}
+
+// WithStatementTimeout overrides statement_timeout for the rest of the
+// transaction using SET LOCAL. Zero duration disables the timeout.
+func (tx *Tx) WithStatementTimeout(ctx context.Context, d time.Duration) error {
+	_, err := tx.ExecContext(ctx, "SET LOCAL statement_timeout = ?", durationMillis(d))
+	return err
+}
+
 func (tx *Tx) begin(ctx context.Context) error {
 	var lastErr error
 	for attempt := 0; attempt <= tx.db.opt.MaxRetries; attempt++ {
@@ -339,8 +448,12 @@ func (tx *Tx) Commit() error {
 
 // Commit commits the transaction.
 func (tx *Tx) CommitContext(ctx context.Context) error {
+	closed := tx.closed()
 	_, err := tx.ExecContext(internal.UndoContext(ctx), "COMMIT")
 	tx.close()
+	if !closed && tx.db.opt.OnTxCommit != nil {
+		tx.db.opt.OnTxCommit(tx.ctx, tx, err)
+	}
 	return err
 }
 
@@ -350,8 +463,12 @@ func (tx *Tx) Rollback() error {
 
 // Rollback aborts the transaction.
 func (tx *Tx) RollbackContext(ctx context.Context) error {
+	closed := tx.closed()
 	_, err := tx.ExecContext(internal.UndoContext(ctx), "ROLLBACK")
 	tx.close()
+	if !closed && tx.db.opt.OnTxRollback != nil {
+		tx.db.opt.OnTxRollback(tx.ctx, tx, err)
+	}
 	return err
 }
 
@@ -372,6 +489,8 @@ func (tx *Tx) close() {
 		return
 	}
 
+	tx.closeCursors()
+
 	tx.stmtsMu.Lock()
 	defer tx.stmtsMu.Unlock()
 
@@ -2,12 +2,14 @@ package pg_test
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
 )
 
 var _ = Describe("Tx", func() {
@@ -148,6 +150,78 @@ var _ = Describe("Tx", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("supports deferred constraints", func() {
+		qs := []string{
+			`DROP TABLE IF EXISTS test_deferred`,
+			`CREATE TABLE test_deferred (
+				id int PRIMARY KEY,
+				next_id int REFERENCES test_deferred (id) DEFERRABLE INITIALLY IMMEDIATE
+			)`,
+		}
+		for _, q := range qs {
+			_, err := db.Exec(q)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		err := db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+			if err := tx.DeferConstraints(ctx); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO test_deferred VALUES (1, 2), (2, 1)`)
+			return err
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		tx, err := db.Begin()
+		Expect(err).NotTo(HaveOccurred())
+
+		err = tx.SetConstraints(ctx, "IMMEDIATE", "test_deferred_next_id_fkey")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = tx.SetConstraints(ctx, "LATER")
+		Expect(err).To(MatchError(`pg: unsupported constraint mode="LATER"`))
+
+		err = tx.Rollback()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = db.Exec("DROP TABLE test_deferred")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("sets and reads custom settings", func() {
+		err := db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+			if err := tx.SetConfig(ctx, "app.current_user_id", 42, true); err != nil {
+				return err
+			}
+			if err := tx.SetConfig(ctx, "app.name", "O'Reilly; DROP TABLE x", true); err != nil {
+				return err
+			}
+
+			var id int64
+			if err := tx.CurrentSetting(ctx, "app.current_user_id", &id); err != nil {
+				return err
+			}
+			Expect(id).To(Equal(int64(42)))
+
+			var name string
+			if err := tx.CurrentSetting(ctx, "app.name", &name); err != nil {
+				return err
+			}
+			Expect(name).To(Equal("O'Reilly; DROP TABLE x"))
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		tx, err := db.Begin()
+		Expect(err).NotTo(HaveOccurred())
+		defer tx.Rollback()
+
+		var id int64
+		err = tx.CurrentSetting(ctx, "app.current_user_id", &id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(BeZero())
+	})
+
 	It("drops bad connections", func() {
 		_ = db.RunInTransaction(ctx, func(tx *pg.Tx) error {
 			stmt, err := tx.Prepare("invalid statement")
@@ -160,4 +234,235 @@ var _ = Describe("Tx", func() {
 		_, err := db.Exec("select 1")
 		Expect(err).NotTo(HaveOccurred())
 	})
+
+	It("selects into temp table dropped on commit", func() {
+		tx, err := db.Begin()
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := tx.Model().
+			TableExpr("generate_series(1, 10) AS n").
+			Where("n > ?", 5).
+			SelectInto("staged_series", &orm.SelectIntoOptions{OnCommitDrop: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RowsAffected()).To(Equal(5))
+
+		var sum int
+		_, err = tx.QueryOne(pg.Scan(&sum), "SELECT sum(n) FROM staged_series")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sum).To(Equal(40))
+
+		err = tx.Commit()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = db.Exec("SELECT 1 FROM staged_series")
+		Expect(err).To(MatchError(`ERROR #42P01 relation "staged_series" does not exist`))
+	})
+
+	It("selects rows in chunks using a cursor", func() {
+		tx, err := db.Begin()
+		Expect(err).NotTo(HaveOccurred())
+		defer tx.Rollback()
+
+		var ns []int
+		err = tx.Model().
+			TableExpr("generate_series(1, 10) AS n").
+			Column("n").
+			ChunkSize(3).
+			ForEach(func(n int) error {
+				ns = append(ns, n)
+				return nil
+			})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ns).To(Equal([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}))
+
+		var values []struct {
+			N int
+		}
+		err = tx.Model(&values).
+			TableExpr("generate_series(1, 9) AS n").
+			Column("n").
+			ChunkSize(3).
+			Select()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(HaveLen(9))
+		Expect(values[8].N).To(Equal(9))
+	})
+
+	It("requires a transaction to select in chunks", func() {
+		var ns []int
+		err := db.Model().
+			TableExpr("generate_series(1, 10) AS n").
+			Column("n").
+			ChunkSize(3).
+			Select(&ns)
+		Expect(err).To(MatchError("ERROR #25P01 DECLARE CURSOR can only be used in transaction blocks"))
+	})
+
+	It("fetches rows in batches using Tx.Cursor", func() {
+		tx, err := db.Begin()
+		Expect(err).NotTo(HaveOccurred())
+		defer tx.Rollback()
+
+		cur, err := tx.Cursor(ctx, "SELECT n FROM generate_series(1, ?) AS n", 7)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sum, batches int
+		for {
+			var ns []int
+			n, err := cur.Fetch(3, &ns)
+			Expect(err).NotTo(HaveOccurred())
+			if n == 0 {
+				break
+			}
+			Expect(ns).To(HaveLen(n))
+			for _, v := range ns {
+				sum += v
+			}
+			batches++
+		}
+		Expect(sum).To(Equal(28))
+		Expect(batches).To(Equal(3))
+
+		Expect(cur.Close()).NotTo(HaveOccurred())
+		Expect(cur.Close()).NotTo(HaveOccurred())
+
+		cur, err = tx.Cursor(ctx, tx.Model().
+			TableExpr("generate_series(1, 5) AS n").
+			Column("n").
+			Order("n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		var ns []int
+		n, err := cur.Fetch(2, &ns)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+		Expect(ns).To(Equal([]int{1, 2}))
+
+		err = tx.Commit()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = cur.Fetch(2, &ns)
+		Expect(err).To(MatchError("pg: cursor is closed"))
+		Expect(cur.Close()).NotTo(HaveOccurred())
+	})
+
+	It("closes the cursor when Fetch fails", func() {
+		tx, err := db.Begin()
+		Expect(err).NotTo(HaveOccurred())
+		defer tx.Rollback()
+
+		cur, err := tx.Cursor(ctx, "SELECT 1/0")
+		Expect(err).NotTo(HaveOccurred())
+
+		var ns []int
+		_, err = cur.Fetch(1, &ns)
+		Expect(err).To(MatchError("ERROR #22012 division by zero"))
+
+		_, err = cur.Fetch(1, &ns)
+		Expect(err).To(MatchError("pg: cursor is closed"))
+	})
+
+	It("supports weaker lock strengths", func() {
+		_, err := db.Exec("DROP TABLE IF EXISTS lock_tests")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.Exec("CREATE TABLE lock_tests (id int PRIMARY KEY)")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.Exec("INSERT INTO lock_tests VALUES (1)")
+		Expect(err).NotTo(HaveOccurred())
+
+		tx1, err := db.Begin()
+		Expect(err).NotTo(HaveOccurred())
+		defer tx1.Rollback()
+
+		var id int
+		err = tx1.Model().Table("lock_tests").Column("id").
+			For("NO KEY UPDATE").
+			Select(pg.Scan(&id))
+		Expect(err).NotTo(HaveOccurred())
+
+		tx2, err := db.Begin()
+		Expect(err).NotTo(HaveOccurred())
+		defer tx2.Rollback()
+
+		err = tx2.Model().Table("lock_tests").Column("id").
+			For("KEY SHARE OF lock_tests").
+			NoWait().
+			Select(pg.Scan(&id))
+		Expect(err).NotTo(HaveOccurred())
+
+		err = tx2.Model().Table("lock_tests").Column("id").
+			For("SHARE").
+			NoWait().
+			Select(pg.Scan(&id))
+		Expect(err).To(MatchError(`ERROR #55P03 could not obtain lock on row in relation "lock_tests"`))
+
+		var ids []int
+		err = db.Model().Table("lock_tests").Column("id").
+			For("UPDATE").
+			SkipLocked().
+			Select(&ids)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(BeEmpty())
+	})
+})
+
+type txSpanKey struct{}
+
+var _ = Describe("Tx hooks", func() {
+	var db *pg.DB
+	var events []string
+
+	BeforeEach(func() {
+		events = nil
+
+		opt := pgOptions()
+		opt.OnTxBegin = func(ctx context.Context, tx *pg.Tx) context.Context {
+			events = append(events, "begin")
+			return context.WithValue(ctx, txSpanKey{}, len(events))
+		}
+		opt.OnTxCommit = func(ctx context.Context, tx *pg.Tx, err error) {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctx.Value(txSpanKey{})).NotTo(BeNil())
+			events = append(events, "commit")
+		}
+		opt.OnTxRollback = func(ctx context.Context, tx *pg.Tx, err error) {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctx.Value(txSpanKey{})).NotTo(BeNil())
+			events = append(events, "rollback")
+		}
+		db = pg.Connect(opt)
+	})
+
+	AfterEach(func() {
+		err := db.Close()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("calls hooks for Begin", func() {
+		tx, err := db.Begin()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tx.Commit()).NotTo(HaveOccurred())
+
+		tx, err = db.Begin()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tx.Rollback()).NotTo(HaveOccurred())
+		Expect(tx.Close()).NotTo(HaveOccurred())
+
+		Expect(events).To(Equal([]string{"begin", "commit", "begin", "rollback"}))
+	})
+
+	It("calls hooks for RunInTransaction", func() {
+		err := db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+			Expect(tx.Context().Value(txSpanKey{})).To(Equal(1))
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+			return errors.New("fail")
+		})
+		Expect(err).To(MatchError("fail"))
+
+		Expect(events).To(Equal([]string{"begin", "commit", "begin", "rollback"}))
+	})
 })
@@ -13,10 +13,11 @@ func AppendIdentBytes(b []byte, field []byte, flags int) []byte {
 func appendIdent(b, src []byte, flags int) []byte {
 	var quoted bool
 loop:
-	for _, c := range src {
+	for i, c := range src {
 		switch c {
 		case '*':
-			if !quoted {
+			// Only a whole "*" segment is a wildcard, e.g. table.*.
+			if !quoted && (i+1 == len(src) || src[i+1] == '.') {
 				b = append(b, '*')
 				continue loop
 			}
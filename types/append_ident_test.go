@@ -25,6 +25,11 @@ var appendFieldTests = []struct {
 
 	{`"`, `""""`},
 	{`'`, `"'"`},
+
+	{"*id", `"*id"`},
+	{"**", `"**"`},
+	{"table.*id", `"table"."*id"`},
+	{`id" FROM users; DROP TABLE users; --`, `"id"" FROM users; DROP TABLE users; --"`},
 }
 
 func TestAppendField(t *testing.T) {
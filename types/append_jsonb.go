@@ -27,11 +27,17 @@ func AppendJSONB(b, jsonb []byte, flags int) []byte {
 			continue
 		case '\\':
 			if p.SkipBytes([]byte("u0000")) {
-				b = append(b, "\\\\u0000"...)
+				b = appendJSONBBackslash(b, flags)
+				b = appendJSONBBackslash(b, flags)
+				b = append(b, "u0000"...)
 			} else {
-				b = append(b, '\\')
+				b = appendJSONBBackslash(b, flags)
 				if p.Valid() {
-					b = append(b, p.Read())
+					c := p.Read()
+					if (c == '"' || c == '\\') && hasFlag(flags, arrayFlag) {
+						b = append(b, '\\')
+					}
+					b = append(b, c)
 				}
 			}
 		default:
@@ -47,3 +53,12 @@ func AppendJSONB(b, jsonb []byte, flags int) []byte {
 
 	return b
 }
+
+// appendJSONBBackslash appends a backslash that is escaped
+// when JSON is an array element.
+func appendJSONBBackslash(b []byte, flags int) []byte {
+	if hasFlag(flags, arrayFlag) {
+		b = append(b, '\\')
+	}
+	return append(b, '\\')
+}
@@ -2,6 +2,7 @@ package types_test
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 
 	"github.com/go-pg/pg/v10/pgjson"
@@ -28,6 +29,17 @@ func TestAppendJSONB(t *testing.T) {
 	}
 }
 
+func TestAppendJSONBArray(t *testing.T) {
+	maps := []map[string]string{{"quote": `"'\`}, nil}
+	appendArray := types.ArrayAppender(reflect.TypeOf(maps))
+
+	got := appendArray(nil, reflect.ValueOf(maps), 1)
+	wanted := `'{"{\"quote\":\"\\\"''\\\\\"}","null"}'`
+	if string(got) != wanted {
+		t.Errorf("got %s, wanted %s", got, wanted)
+	}
+}
+
 func BenchmarkAppendJSONB(b *testing.B) {
 	bytes, err := pgjson.Marshal(jsonbTests)
 	if err != nil {
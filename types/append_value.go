@@ -56,11 +56,18 @@ func init() {
 	}
 }
 
-var appendersMap sync.Map
+var (
+	appendersMap           sync.Map
+	registeredAppendersMap sync.Map
+)
 
 // RegisterAppender registers an appender func for the value type.
 // Expecting to be used only during initialization, it panics
 // if there is already a registered appender for the given type.
+//
+// When the type is used as an array element, e.g. []T, fn is called
+// with zero flags to get the raw element text and the array encoder
+// quotes and escapes it as an array element.
 func RegisterAppender(value interface{}, fn AppenderFunc) {
 	registerAppender(reflect.TypeOf(value), fn)
 }
@@ -72,6 +79,7 @@ func registerAppender(typ reflect.Type, fn AppenderFunc) {
 			typ.String())
 		panic(err)
 	}
+	registeredAppendersMap.Store(typ, fn)
 }
 
 func Appender(typ reflect.Type) AppenderFunc {
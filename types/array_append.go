@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"strconv"
 	"sync"
+
+	"github.com/go-pg/pg/v10/internal"
 )
 
 var (
@@ -47,6 +49,10 @@ func arrayAppender(typ reflect.Type) AppenderFunc {
 
 	elemType := typ.Elem()
 
+	if fn := registeredElemAppender(elemType); fn != nil {
+		return arrayElemAppender(fn)
+	}
+
 	if kind == reflect.Slice {
 		switch elemType {
 		case stringType:
@@ -60,7 +66,64 @@ func arrayAppender(typ reflect.Type) AppenderFunc {
 		}
 	}
 
-	appendElem := appender(elemType, true)
+	return arrayElemAppender(appender(elemType, true))
+}
+
+// CustomArrayAppender returns an AppenderFunc that encodes typ as a PostgreSQL
+// array using appendElem to encode array elements. It is used for element types
+// that can't be handled by ArrayAppender, for example, composite types.
+func CustomArrayAppender(typ reflect.Type, appendElem AppenderFunc) AppenderFunc {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		return arrayElemAppender(appendElem)
+	default:
+		return nil
+	}
+}
+
+// registeredElemAppender returns an array element appender that uses
+// the appender registered with RegisterAppender for typ or, when typ
+// is a pointer, for the pointed-to type.
+func registeredElemAppender(typ reflect.Type) AppenderFunc {
+	if v, ok := registeredAppendersMap.Load(typ); ok {
+		return quotedElemAppender(v.(AppenderFunc))
+	}
+
+	if typ.Kind() != reflect.Ptr {
+		return nil
+	}
+	v, ok := registeredAppendersMap.Load(typ.Elem())
+	if !ok {
+		return nil
+	}
+
+	appendElem := quotedElemAppender(v.(AppenderFunc))
+	return func(b []byte, v reflect.Value, flags int) []byte {
+		if v.IsNil() {
+			return AppendNull(b, flags)
+		}
+		return appendElem(b, v.Elem(), flags)
+	}
+}
+
+// quotedElemAppender calls fn without flags to get the raw element text
+// and quotes it as an array element, escaping double quotes and backslashes.
+// Like AppendNull, fn returns nil for NULL elements.
+func quotedElemAppender(fn AppenderFunc) AppenderFunc {
+	return func(b []byte, v reflect.Value, flags int) []byte {
+		elem := fn(make([]byte, 0, 32), v, 0)
+		if elem == nil {
+			return AppendNull(b, flags)
+		}
+		return appendString2(b, internal.BytesToString(elem), flags)
+	}
+}
+
+func arrayElemAppender(appendElem AppenderFunc) AppenderFunc {
 	return func(b []byte, v reflect.Value, flags int) []byte {
 		flags |= arrayFlag
 
@@ -86,7 +149,13 @@ func arrayAppender(typ reflect.Type) AppenderFunc {
 		b = append(b, '{')
 		for i := 0; i < v.Len(); i++ {
 			elem := v.Index(i)
+			prev := b
 			b = appendElem(b, elem, flags)
+			if b == nil {
+				// AppendNull returns nil without quoteFlag, but a NULL
+				// element must be written as the keyword.
+				b = append(prev, "NULL"...)
+			}
 			b = append(b, ',')
 		}
 		if v.Len() > 0 {
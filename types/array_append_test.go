@@ -0,0 +1,75 @@
+package types_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10/types"
+)
+
+type CustomString string
+
+func init() {
+	types.RegisterAppender(CustomString(""), func(b []byte, v reflect.Value, flags int) []byte {
+		s := v.String()
+		if s == "null" {
+			return types.AppendNull(b, flags)
+		}
+		return types.AppendString(b, "custom:"+strings.ToUpper(s), flags)
+	})
+}
+
+func TestAppendCustomStringArray(t *testing.T) {
+	s1 := CustomString(`o'k`)
+	tests := []struct {
+		value  interface{}
+		flags  int
+		wanted string
+	}{
+		{CustomString(`a"b`), 1, `'custom:A"B'`},
+		{[]CustomString{`a"b`, `c\d`, `o'k`, "", "null"}, 1,
+			`'{"custom:A\"B","custom:C\\D","custom:O''K","custom:",NULL}'`},
+		{[]CustomString{`a"b`, `o'k`}, 0, `{"custom:A\"B","custom:O'K"}`},
+		{[]*CustomString{&s1, nil}, 1, `'{"custom:O''K",NULL}'`},
+		{[][]CustomString{{"a"}, {`b,c`}}, 1, `'{{"custom:A"},{"custom:B,C"}}'`},
+	}
+
+	for _, test := range tests {
+		appendValue := types.Appender(reflect.TypeOf(test.value))
+		if reflect.TypeOf(test.value).Kind() == reflect.Slice {
+			appendValue = types.ArrayAppender(reflect.TypeOf(test.value))
+		}
+
+		got := appendValue(nil, reflect.ValueOf(test.value), test.flags)
+		if string(got) != test.wanted {
+			t.Errorf("got %s, wanted %s", got, test.wanted)
+		}
+	}
+}
+
+func TestAppendArrayNull(t *testing.T) {
+	tm := time.Unix(0, 0).UTC()
+	tests := []struct {
+		value  interface{}
+		flags  int
+		wanted []byte
+	}{
+		{[]time.Time(nil), 0, nil},
+		{[]float32(nil), 0, nil},
+		{[]int16(nil), 0, nil},
+		{[]time.Time(nil), 1, []byte("NULL")},
+		{[]*time.Time{&tm, nil}, 0, []byte(`{1970-01-01 00:00:00+00:00:00,NULL}`)},
+		{[]CustomString{"a", "null"}, 0, []byte(`{"custom:A",NULL}`)},
+		{[][]int16{{1}, nil}, 0, []byte(`{{1},NULL}`)},
+	}
+
+	for _, test := range tests {
+		appendValue := types.ArrayAppender(reflect.TypeOf(test.value))
+		got := appendValue(nil, reflect.ValueOf(test.value), test.flags)
+		if !reflect.DeepEqual(got, test.wanted) {
+			t.Errorf("%#v: got %q, wanted %q", test.value, got, test.wanted)
+		}
+	}
+}
@@ -49,7 +49,25 @@ func ArrayScanner(typ reflect.Type) ScannerFunc {
 		}
 	}
 
-	scanElem := scanner(elemType, true)
+	return arrayElemScanner(scanner(elemType, true))
+}
+
+// CustomArrayScanner returns a ScannerFunc that decodes a PostgreSQL array
+// into typ using scanElem to decode array elements.
+func CustomArrayScanner(typ reflect.Type, scanElem ScannerFunc) ScannerFunc {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		return arrayElemScanner(scanElem)
+	default:
+		return nil
+	}
+}
+
+func arrayElemScanner(scanElem ScannerFunc) ScannerFunc {
 	return func(v reflect.Value, rd Reader, n int) error {
 		v = reflect.Indirect(v)
 		if !v.CanSet() {
@@ -75,6 +93,7 @@ func ArrayScanner(typ reflect.Type) ScannerFunc {
 
 		p := newArrayParser(rd)
 		nextValue := internal.MakeSliceNextElemFunc(v)
+		nilElem := kind == reflect.Slice && v.Type().Elem().Kind() == reflect.Ptr
 		var elemRd *pool.BytesReader
 
 		for {
@@ -100,6 +119,13 @@ func ArrayScanner(typ reflect.Type) ScannerFunc {
 			}
 
 			elemValue := nextValue()
+			if elemN == -1 && nilElem {
+				// Keep NULL elements as nil pointers.
+				elem := v.Index(v.Len() - 1)
+				elem.Set(reflect.Zero(elem.Type()))
+				continue
+			}
+
 			err = scanElem(elemValue, elemRd, elemN)
 			if err != nil {
 				return err
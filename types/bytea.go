@@ -0,0 +1,84 @@
+package types
+
+import (
+	"errors"
+	"io"
+)
+
+// ByteaWriter is a bytea scanner that decodes column value directly into
+// the writer so large values are never fully loaded into the memory.
+type ByteaWriter struct {
+	w io.Writer
+}
+
+var _ ValueScanner = (*ByteaWriter)(nil)
+
+func NewByteaWriter(w io.Writer) *ByteaWriter {
+	return &ByteaWriter{
+		w: w,
+	}
+}
+
+func (bw *ByteaWriter) ScanValue(rd Reader, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	dec, err := NewHexDecoder(rd, n)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(bw.w, dec)
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+var errByteaReaderUsed = errors.New(
+	"pg: ByteaReader is already consumed (it can't be formatted twice or retried)")
+
+// ByteaReader is a bytea appender that hex encodes data read from the reader
+// directly into the query without allocating an intermediate byte slice.
+//
+// The reader can be consumed only once, so formatting the query again,
+// e.g. when the query is retried because of Options.MaxRetries or formatted
+// by a hook, returns an error instead of sending an empty bytea.
+type ByteaReader struct {
+	r    io.Reader
+	used bool
+}
+
+var _ ValueAppender = (*ByteaReader)(nil)
+
+func NewByteaReader(r io.Reader) *ByteaReader {
+	return &ByteaReader{
+		r: r,
+	}
+}
+
+func (br *ByteaReader) AppendValue(b []byte, flags int) ([]byte, error) {
+	if br.r == nil {
+		return AppendNull(b, flags), nil
+	}
+	if br.used {
+		return nil, errByteaReaderUsed
+	}
+	br.used = true
+
+	enc := NewHexEncoder(b, flags)
+
+	// Empty write makes sure that empty reader is encoded as an empty bytea
+	// and not as NULL.
+	if _, err := enc.Write(nil); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(enc, br.r); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return enc.Bytes(), nil
+}
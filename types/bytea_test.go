@@ -0,0 +1,55 @@
+package types
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-pg/pg/v10/internal/pool"
+)
+
+var byteaTests = []struct {
+	s       string
+	encoded string
+}{
+	{"", `'\x'`},
+	{"hello", `'\x68656c6c6f'`},
+	{"\x00\xff", `'\x00ff'`},
+}
+
+func TestByteaReader(t *testing.T) {
+	for _, test := range byteaTests {
+		got, err := NewByteaReader(strings.NewReader(test.s)).AppendValue(nil, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.encoded {
+			t.Fatalf("got %q, wanted %q", got, test.encoded)
+		}
+	}
+}
+
+func TestByteaReaderUsedTwice(t *testing.T) {
+	br := NewByteaReader(strings.NewReader("hello"))
+	if _, err := br.AppendValue(nil, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := br.AppendValue(nil, 1); err != errByteaReaderUsed {
+		t.Fatalf("got %v, wanted %v", err, errByteaReaderUsed)
+	}
+}
+
+func TestByteaWriter(t *testing.T) {
+	for _, test := range byteaTests {
+		src := strings.Trim(test.encoded, "'")
+
+		var buf bytes.Buffer
+		err := NewByteaWriter(&buf).ScanValue(pool.NewBytesReader([]byte(src)), len(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.s {
+			t.Fatalf("got %q, wanted %q", buf.String(), test.s)
+		}
+	}
+}
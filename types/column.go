@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/json"
+	"reflect"
 
 	"github.com/go-pg/pg/v10/internal/pool"
 	"github.com/go-pg/pg/v10/pgjson"
@@ -111,3 +112,47 @@ func ReadColumnValue(col ColumnInfo, rd Reader, n int) (interface{}, error) {
 		}, nil
 	}
 }
+
+var (
+	boolType     = reflect.TypeOf(false)
+	int16Type    = reflect.TypeOf(int16(0))
+	int32Type    = reflect.TypeOf(int32(0))
+	float32Type  = reflect.TypeOf(float32(0))
+	bytesType    = reflect.TypeOf([]byte(nil))
+	rawValueType = reflect.TypeOf(RawValue{})
+)
+
+// ColumnValueType returns the type of values returned by ReadColumnValue
+// for the column.
+func ColumnValueType(col ColumnInfo) reflect.Type {
+	switch col.DataType {
+	case pgBool:
+		return boolType
+	case pgInt2:
+		return int16Type
+	case pgInt4:
+		return int32Type
+	case pgInt8:
+		return int64Type
+	case pgFloat4:
+		return float32Type
+	case pgFloat8:
+		return float64Type
+	case pgBytea:
+		return bytesType
+	case pgText, pgVarchar, pgUUID:
+		return stringType
+	case pgJSON, pgJSONB:
+		return jsonRawMessageType
+	case pgTimestamp, pgTimestamptz:
+		return timeType
+	case pgInt32Array, pgInt8Array:
+		return sliceInt64Type
+	case pgFloat8Array:
+		return sliceFloat64Type
+	case pgStringArray:
+		return sliceStringType
+	default:
+		return rawValueType
+	}
+}
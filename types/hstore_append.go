@@ -5,12 +5,19 @@ import (
 	"reflect"
 )
 
-var mapStringStringType = reflect.TypeOf(map[string]string(nil))
+var (
+	stringPtrType          = reflect.TypeOf((*string)(nil))
+	mapStringStringType    = reflect.TypeOf(map[string]string(nil))
+	mapStringStringPtrType = reflect.TypeOf(map[string]*string(nil))
+)
 
 func HstoreAppender(typ reflect.Type) AppenderFunc {
 	if typ.Key() == stringType && typ.Elem() == stringType {
 		return appendMapStringStringValue
 	}
+	if typ.Key() == stringType && typ.Elem() == stringPtrType {
+		return appendMapStringStringPtrValue
+	}
 
 	return func(b []byte, v reflect.Value, flags int) []byte {
 		err := fmt.Errorf("pg.Hstore(unsupported %s)", v.Type())
@@ -48,3 +55,40 @@ func appendMapStringStringValue(b []byte, v reflect.Value, flags int) []byte {
 	m := v.Convert(mapStringStringType).Interface().(map[string]string)
 	return appendMapStringString(b, m, flags)
 }
+
+// appendMapStringStringPtr appends nil values as hstore NULL so they
+// can be distinguished from missing keys.
+func appendMapStringStringPtr(b []byte, m map[string]*string, flags int) []byte {
+	if m == nil {
+		return AppendNull(b, flags)
+	}
+
+	if hasFlag(flags, quoteFlag) {
+		b = append(b, '\'')
+	}
+
+	for key, value := range m {
+		b = appendString2(b, key, flags)
+		b = append(b, '=', '>')
+		if value == nil {
+			b = append(b, hstoreNull...)
+		} else {
+			b = appendString2(b, *value, flags)
+		}
+		b = append(b, ',')
+	}
+	if len(m) > 0 {
+		b = b[:len(b)-1] // Strip trailing comma.
+	}
+
+	if hasFlag(flags, quoteFlag) {
+		b = append(b, '\'')
+	}
+
+	return b
+}
+
+func appendMapStringStringPtrValue(b []byte, v reflect.Value, flags int) []byte {
+	m := v.Convert(mapStringStringPtrType).Interface().(map[string]*string)
+	return appendMapStringStringPtr(b, m, flags)
+}
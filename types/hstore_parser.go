@@ -2,6 +2,7 @@ package types
 
 import (
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/go-pg/pg/v10/internal/parser"
@@ -9,6 +10,8 @@ import (
 
 var errEndOfHstore = errors.New("pg: end of hstore")
 
+const hstoreNull = "NULL"
+
 type hstoreParser struct {
 	p parser.StreamingParser
 }
@@ -45,15 +48,11 @@ func (p *hstoreParser) NextKey() ([]byte, error) {
 	return key, nil
 }
 
-func (p *hstoreParser) NextValue() ([]byte, error) {
-	err := p.p.SkipByte('"')
-	if err != nil {
-		return nil, err
-	}
-
-	value, err := p.p.ReadSubstring(nil)
+// NextValue returns the next value and reports whether it is NULL.
+func (p *hstoreParser) NextValue() ([]byte, bool, error) {
+	value, null, err := p.readValue()
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	err = p.p.SkipByte(',')
@@ -61,5 +60,31 @@ func (p *hstoreParser) NextValue() ([]byte, error) {
 		_ = p.p.SkipByte(' ')
 	}
 
-	return value, nil
+	return value, null, nil
+}
+
+func (p *hstoreParser) readValue() ([]byte, bool, error) {
+	c, err := p.p.ReadByte()
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch c {
+	case '"':
+		value, err := p.p.ReadSubstring(nil)
+		if err != nil {
+			return nil, false, err
+		}
+		return value, false, nil
+	case 'N':
+		for i := 1; i < len(hstoreNull); i++ {
+			err := p.p.SkipByte(hstoreNull[i])
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		return nil, true, nil
+	default:
+		return nil, false, fmt.Errorf("pg: got %q, wanted '\"' or NULL", c)
+	}
 }
@@ -41,3 +41,49 @@ func TestHstoreParser(t *testing.T) {
 		}
 	}
 }
+
+func TestHstoreParserNull(t *testing.T) {
+	s := `"a"=>"1", "b"=>NULL, "c"=>""`
+
+	got, err := scanMapStringStringPtr(pool.NewBytesReader([]byte(s)), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("got %d elements, wanted 3 (got=%#v)", len(got), got)
+	}
+	if v := got["a"]; v == nil || *v != "1" {
+		t.Fatalf(`got %v for "a", wanted "1"`, v)
+	}
+	if v, ok := got["b"]; !ok || v != nil {
+		t.Fatalf(`got %v for "b", wanted NULL`, v)
+	}
+	if v := got["c"]; v == nil || *v != "" {
+		t.Fatalf(`got %v for "c", wanted ""`, v)
+	}
+
+	m, err := scanMapStringString(pool.NewBytesReader([]byte(s)), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := m["b"]; !ok || v != "" {
+		t.Fatalf(`got %q for "b", wanted ""`, v)
+	}
+}
+
+func TestAppendHstoreNull(t *testing.T) {
+	value := "it's"
+	m := map[string]*string{"k": &value}
+
+	got := string(appendMapStringStringPtr(nil, m, 1))
+	if wanted := `'"k"=>"it''s"'`; got != wanted {
+		t.Fatalf("got %s, wanted %s", got, wanted)
+	}
+
+	m = map[string]*string{"k": nil}
+	got = string(appendMapStringStringPtr(nil, m, 1))
+	if wanted := `'"k"=>NULL'`; got != wanted {
+		t.Fatalf("got %s, wanted %s", got, wanted)
+	}
+}
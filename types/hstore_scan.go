@@ -9,6 +9,9 @@ func HstoreScanner(typ reflect.Type) ScannerFunc {
 	if typ.Key() == stringType && typ.Elem() == stringType {
 		return scanMapStringStringValue
 	}
+	if typ.Key() == stringType && typ.Elem() == stringPtrType {
+		return scanMapStringStringPtrValue
+	}
 	return func(v reflect.Value, rd Reader, n int) error {
 		return fmt.Errorf("pg.Hstore(unsupported %s)", v.Type())
 	}
@@ -20,10 +23,12 @@ func scanMapStringStringValue(v reflect.Value, rd Reader, n int) error {
 		return err
 	}
 
-	v.Set(reflect.ValueOf(m))
+	v.Set(reflect.ValueOf(m).Convert(v.Type()))
 	return nil
 }
 
+// scanMapStringString scans hstore into map[string]string.
+// NULL values are scanned as empty strings.
 func scanMapStringString(rd Reader, n int) (map[string]string, error) {
 	if n == -1 {
 		return nil, nil
@@ -40,7 +45,7 @@ func scanMapStringString(rd Reader, n int) (map[string]string, error) {
 			return nil, err
 		}
 
-		value, err := p.NextValue()
+		value, _, err := p.NextValue()
 		if err != nil {
 			return nil, err
 		}
@@ -49,3 +54,46 @@ func scanMapStringString(rd Reader, n int) (map[string]string, error) {
 	}
 	return m, nil
 }
+
+func scanMapStringStringPtrValue(v reflect.Value, rd Reader, n int) error {
+	m, err := scanMapStringStringPtr(rd, n)
+	if err != nil {
+		return err
+	}
+
+	v.Set(reflect.ValueOf(m).Convert(v.Type()))
+	return nil
+}
+
+// scanMapStringStringPtr scans hstore into map[string]*string
+// using nil for NULL values.
+func scanMapStringStringPtr(rd Reader, n int) (map[string]*string, error) {
+	if n == -1 {
+		return nil, nil
+	}
+
+	p := newHstoreParser(rd)
+	m := make(map[string]*string)
+	for {
+		key, err := p.NextKey()
+		if err != nil {
+			if err == errEndOfHstore {
+				break
+			}
+			return nil, err
+		}
+
+		value, null, err := p.NextValue()
+		if err != nil {
+			return nil, err
+		}
+
+		if null {
+			m[string(key)] = nil
+		} else {
+			s := string(value)
+			m[string(key)] = &s
+		}
+	}
+	return m, nil
+}
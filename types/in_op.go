@@ -5,6 +5,8 @@ import (
 	"reflect"
 )
 
+var inOpType = reflect.TypeOf((*inOp)(nil))
+
 type inOp struct {
 	slice     reflect.Value
 	stickyErr error
@@ -40,6 +42,12 @@ func (in *inOp) AppendValue(b []byte, flags int) ([]byte, error) {
 
 func appendIn(b []byte, slice reflect.Value, flags int) []byte {
 	sliceLen := slice.Len()
+	if sliceLen == 0 {
+		// Empty list is not valid SQL so NULL is used instead,
+		// which matches no rows.
+		return append(b, "NULL"...)
+	}
+
 	for i := 0; i < sliceLen; i++ {
 		if i > 0 {
 			b = append(b, ',')
@@ -50,11 +58,20 @@ func appendIn(b []byte, slice reflect.Value, flags int) []byte {
 			elem = elem.Elem()
 		}
 
-		if elem.Kind() == reflect.Slice {
+		switch {
+		case elem.Kind() == reflect.Slice && elem.Type().Elem().Kind() != reflect.Uint8:
 			b = append(b, '(')
 			b = appendIn(b, elem, flags)
 			b = append(b, ')')
-		} else {
+		case elem.Type() == inOpType:
+			in := elem.Interface().(*inOp)
+			if in.stickyErr != nil {
+				return AppendError(b, in.stickyErr)
+			}
+			b = append(b, '(')
+			b = appendIn(b, in.slice, flags)
+			b = append(b, ')')
+		default:
 			b = appendValue(b, elem, flags)
 		}
 	}
@@ -16,11 +16,14 @@ func TestInOp(t *testing.T) {
 		app    types.ValueAppender
 		wanted string
 	}{
-		{types.InMulti(), ""},
+		{types.InMulti(), "NULL"},
+		{types.In([]int{}), "NULL"},
 		{types.InMulti(1), "1"},
 		{types.InMulti(1, 2, 3), "1,2,3"},
 		{types.InMulti([]int{1, 2, 3}), "(1,2,3)"},
 		{types.InMulti([]int{1, 2}, []int{3, 4}), "(1,2),(3,4)"},
+		{types.InMulti(types.In([]int{1, 2}), types.In([]int{3, 4})), "(1,2),(3,4)"},
+		{types.In([][]byte{[]byte("foo")}), `\x666f6f`},
 		{types.InMulti(types.NewArray([]int{1, 2}), types.NewArray([]int{3, 4})), "{1,2},{3,4}"},
 	}
 
@@ -0,0 +1,181 @@
+package types
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/go-pg/pg/v10/internal"
+)
+
+// Inet represents PostgreSQL inet type that holds an IPv4 or IPv6 host
+// address with an optional netmask. Unlike net.ParseCIDR it preserves
+// host bits, e.g. 192.168.0.1/24 is kept as is. Zero value is marshaled
+// as PostgreSQL NULL.
+type Inet struct {
+	net.IPNet
+}
+
+var (
+	_ ValueAppender = (*Inet)(nil)
+	_ ValueScanner  = (*Inet)(nil)
+)
+
+// ParseInet parses inet value in the PostgreSQL text format,
+// e.g. 192.168.0.1, 192.168.0.1/24 or ::1/128.
+func ParseInet(s string) (Inet, error) {
+	var addr, prefix string
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		addr, prefix = s[:i], s[i+1:]
+	} else {
+		addr = s
+	}
+
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return Inet{}, fmt.Errorf("pg: invalid inet=%q", s)
+	}
+	// IPv4-mapped IPv6 addresses, e.g. ::ffff:10.0.0.0/104, are IPv6
+	// in PostgreSQL, so only addresses written in IPv4 form are converted.
+	if strings.IndexByte(addr, ':') == -1 {
+		ip = ip.To4()
+	}
+
+	bits := len(ip) * 8
+	ones := bits
+	if prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n < 0 || n > bits {
+			return Inet{}, fmt.Errorf("pg: invalid inet=%q", s)
+		}
+		ones = n
+	}
+
+	return Inet{
+		IPNet: net.IPNet{
+			IP:   ip,
+			Mask: net.CIDRMask(ones, bits),
+		},
+	}, nil
+}
+
+// IsZero reports whether inet has no address.
+func (inet Inet) IsZero() bool {
+	return inet.IP == nil
+}
+
+// String returns the address in the PostgreSQL text format omitting
+// the netmask when it covers the whole address.
+func (inet Inet) String() string {
+	if inet.IP == nil {
+		return ""
+	}
+	ones, bits := inet.Mask.Size()
+	if ones == bits {
+		return ipString(inet.IP, bits)
+	}
+	return ipString(inet.IP, bits) + "/" + strconv.Itoa(ones)
+}
+
+// ipString is like ip.String, but keeps IPv4-mapped IPv6 addresses
+// in IPv6 form when the mask has IPv6 length.
+func ipString(ip net.IP, bits int) string {
+	if bits == 8*net.IPv6len {
+		if ip4 := ip.To4(); ip4 != nil {
+			return "::ffff:" + ip4.String()
+		}
+	}
+	return ip.String()
+}
+
+func (inet Inet) AppendValue(b []byte, flags int) ([]byte, error) {
+	if inet.IsZero() {
+		return AppendNull(b, flags), nil
+	}
+	return AppendString(b, inet.String(), flags), nil
+}
+
+func (inet *Inet) ScanValue(rd Reader, n int) error {
+	if n == -1 {
+		*inet = Inet{}
+		return nil
+	}
+
+	tmp, err := rd.ReadFullTemp()
+	if err != nil {
+		return err
+	}
+
+	newinet, err := ParseInet(internal.BytesToString(tmp))
+	if err != nil {
+		return err
+	}
+	*inet = newinet
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// CIDR represents PostgreSQL cidr type that holds an IPv4 or IPv6 network.
+// Zero value is marshaled as PostgreSQL NULL.
+type CIDR struct {
+	net.IPNet
+}
+
+var (
+	_ ValueAppender = (*CIDR)(nil)
+	_ ValueScanner  = (*CIDR)(nil)
+)
+
+// ParseCIDR parses cidr value in the PostgreSQL text format,
+// e.g. 192.168.0.0/24 or 2001:db8::/32. Host bits are cleared.
+func ParseCIDR(s string) (CIDR, error) {
+	_, ipnet, err := net.ParseCIDR(s)
+	if err != nil {
+		return CIDR{}, fmt.Errorf("pg: invalid cidr=%q", s)
+	}
+	return CIDR{IPNet: *ipnet}, nil
+}
+
+// IsZero reports whether cidr has no network address.
+func (cidr CIDR) IsZero() bool {
+	return cidr.IP == nil
+}
+
+// String returns the network in the PostgreSQL text format.
+func (cidr CIDR) String() string {
+	if cidr.IP == nil {
+		return ""
+	}
+	if ones, bits := cidr.Mask.Size(); bits == 8*net.IPv6len {
+		return ipString(cidr.IP, bits) + "/" + strconv.Itoa(ones)
+	}
+	return cidr.IPNet.String()
+}
+
+func (cidr CIDR) AppendValue(b []byte, flags int) ([]byte, error) {
+	if cidr.IsZero() {
+		return AppendNull(b, flags), nil
+	}
+	return AppendString(b, cidr.String(), flags), nil
+}
+
+func (cidr *CIDR) ScanValue(rd Reader, n int) error {
+	if n == -1 {
+		*cidr = CIDR{}
+		return nil
+	}
+
+	tmp, err := rd.ReadFullTemp()
+	if err != nil {
+		return err
+	}
+
+	newcidr, err := ParseCIDR(internal.BytesToString(tmp))
+	if err != nil {
+		return err
+	}
+	*cidr = newcidr
+	return nil
+}
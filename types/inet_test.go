@@ -0,0 +1,88 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/go-pg/pg/v10/internal/pool"
+)
+
+var inetTests = []struct {
+	s      string
+	wanted string
+}{
+	{"192.168.0.1", "192.168.0.1"},
+	{"192.168.0.1/32", "192.168.0.1"},
+	{"192.168.0.1/24", "192.168.0.1/24"},
+	{"::1", "::1"},
+	{"2001:db8::1/64", "2001:db8::1/64"},
+	{"::ffff:10.0.0.1", "::ffff:10.0.0.1"},
+	{"::ffff:10.0.0.0/104", "::ffff:10.0.0.0/104"},
+}
+
+func TestInet(t *testing.T) {
+	for _, test := range inetTests {
+		var inet Inet
+		err := inet.ScanValue(pool.NewBytesReader([]byte(test.s)), len(test.s))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := inet.AppendValue(nil, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wanted := "'" + test.wanted + "'"; string(got) != wanted {
+			t.Fatalf("got %q, wanted %q", got, wanted)
+		}
+	}
+}
+
+var cidrTests = []struct {
+	s      string
+	wanted string
+}{
+	{"192.168.0.0/24", "192.168.0.0/24"},
+	{"10.1.2.3/8", "10.0.0.0/8"},
+	{"2001:db8::/32", "2001:db8::/32"},
+	{"::ffff:10.0.0.0/104", "::ffff:10.0.0.0/104"},
+}
+
+func TestCIDR(t *testing.T) {
+	for _, test := range cidrTests {
+		var cidr CIDR
+		err := cidr.ScanValue(pool.NewBytesReader([]byte(test.s)), len(test.s))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := cidr.AppendValue(nil, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wanted := "'" + test.wanted + "'"; string(got) != wanted {
+			t.Fatalf("got %q, wanted %q", got, wanted)
+		}
+	}
+}
+
+func TestInetNull(t *testing.T) {
+	got, err := Inet{}.AppendValue(nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "NULL" {
+		t.Fatalf("got %q, wanted NULL", got)
+	}
+
+	got, err = CIDR{}.AppendValue(nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "NULL" {
+		t.Fatalf("got %q, wanted NULL", got)
+	}
+
+	if _, err := ParseInet("192.168.0.1/33"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,155 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/go-pg/pg/v10/internal"
+)
+
+// Numeric represents PostgreSQL numeric type with arbitrary precision.
+// Unlike float64 it does not lose precision when scanning numeric values.
+//
+// Numeric with Valid set to false is marshaled as PostgreSQL NULL and
+// NULL is scanned as Numeric with Valid set to false.
+//
+// Values that can be represented as a finite decimal fraction, e.g. 1/8, are
+// encoded exactly. Other values, e.g. 1/3, are rounded to Scale digits after
+// the decimal point with halves rounded away from zero. NaN and infinity are
+// not supported.
+//
+// Third-party decimal types that implement sql.Scanner and driver.Valuer,
+// e.g. github.com/shopspring/decimal, are supported as is and can be mapped
+// to numeric columns with `pg:"type:numeric"` tag.
+type Numeric struct {
+	// Rat is the value. Scanning allocates a new Rat, so copies of Numeric
+	// are not changed when the value is scanned again. Nil Rat is zero.
+	Rat *big.Rat
+	// Scale is the number of digits after the decimal point. It is set by
+	// ParseNumeric and Scan and used to round values that are not finite
+	// decimal fractions.
+	Scale int
+	Valid bool
+}
+
+var (
+	_ ValueAppender = (*Numeric)(nil)
+	_ ValueScanner  = (*Numeric)(nil)
+)
+
+// ParseNumeric parses numeric value in the PostgreSQL text format,
+// e.g. 123.4500 or -1e-3.
+func ParseNumeric(s string) (Numeric, error) {
+	num := Numeric{Rat: new(big.Rat)}
+	if _, ok := num.Rat.SetString(s); !ok {
+		return Numeric{}, fmt.Errorf("pg: invalid numeric=%q", s)
+	}
+	num.Scale = numericScale(s)
+	num.Valid = true
+	return num, nil
+}
+
+// numericScale returns the number of digits after the decimal point.
+func numericScale(s string) int {
+	var scale int
+	var frac bool
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '.':
+			frac = true
+		case c >= '0' && c <= '9':
+			if frac {
+				scale++
+			}
+		case c == '-' || c == '+':
+		default:
+			// Exponent notation is normalized by the exact scale.
+			return 0
+		}
+	}
+	return scale
+}
+
+// String returns the value in the PostgreSQL text format.
+func (num Numeric) String() string {
+	if !num.Valid {
+		return ""
+	}
+	r := num.rat()
+	scale := num.Scale
+	if n, ok := exactScale(r); ok && n > scale {
+		scale = n
+	}
+	return r.FloatString(scale)
+}
+
+// Float64 returns the nearest float64 value.
+func (num Numeric) Float64() float64 {
+	f, _ := num.rat().Float64()
+	return f
+}
+
+func (num Numeric) rat() *big.Rat {
+	if num.Rat == nil {
+		return new(big.Rat)
+	}
+	return num.Rat
+}
+
+// exactScale returns the number of digits after the decimal point needed
+// to represent the value exactly. It reports false when the value is not
+// a finite decimal fraction.
+func exactScale(r *big.Rat) (int, bool) {
+	d := new(big.Int).Set(r.Denom())
+	two, five := big.NewInt(2), big.NewInt(5)
+	mod := new(big.Int)
+
+	var n2, n5 int
+	for {
+		if q, m := new(big.Int).QuoRem(d, two, mod); m.Sign() == 0 {
+			d = q
+			n2++
+			continue
+		}
+		if q, m := new(big.Int).QuoRem(d, five, mod); m.Sign() == 0 {
+			d = q
+			n5++
+			continue
+		}
+		break
+	}
+
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+	if n2 > n5 {
+		return n2, true
+	}
+	return n5, true
+}
+
+func (num Numeric) AppendValue(b []byte, flags int) ([]byte, error) {
+	if !num.Valid {
+		return AppendNull(b, flags), nil
+	}
+	return append(b, num.String()...), nil
+}
+
+func (num *Numeric) ScanValue(rd Reader, n int) error {
+	if n == -1 {
+		*num = Numeric{}
+		return nil
+	}
+
+	tmp, err := rd.ReadFullTemp()
+	if err != nil {
+		return err
+	}
+
+	newnum, err := ParseNumeric(internal.BytesToString(tmp))
+	if err != nil {
+		return err
+	}
+	*num = newnum
+	return nil
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/go-pg/pg/v10/internal/pool"
+)
+
+var numericTests = []struct {
+	s      string
+	wanted string
+}{
+	{"0", "0"},
+	{"-1.50", "-1.50"},
+	{"123.4500", "123.4500"},
+	{"12345678901234567890.123456789012345678901", "12345678901234567890.123456789012345678901"},
+	{"1e-3", "0.001"},
+	{"+2.5E2", "250"},
+}
+
+func TestNumeric(t *testing.T) {
+	for _, test := range numericTests {
+		var num Numeric
+		err := num.ScanValue(pool.NewBytesReader([]byte(test.s)), len(test.s))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := num.AppendValue(nil, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.wanted {
+			t.Fatalf("got %q, wanted %q", got, test.wanted)
+		}
+	}
+}
+
+func TestNumericRounding(t *testing.T) {
+	num := Numeric{Rat: big.NewRat(1, 8), Valid: true}
+	if s := num.String(); s != "0.125" {
+		t.Fatalf("got %q, wanted 0.125", s)
+	}
+
+	num.Rat = big.NewRat(2, 3)
+	num.Scale = 2
+	if s := num.String(); s != "0.67" {
+		t.Fatalf("got %q, wanted 0.67", s)
+	}
+}
+
+func TestNumericNull(t *testing.T) {
+	got, err := Numeric{}.AppendValue(nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "NULL" {
+		t.Fatalf("got %q, wanted NULL", got)
+	}
+
+	num := Numeric{Valid: true}
+	if err := num.ScanValue(nil, -1); err != nil {
+		t.Fatal(err)
+	}
+	if num.Valid {
+		t.Fatal("expected NULL numeric")
+	}
+
+	if _, err := ParseNumeric("NaN"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestNumericCopy(t *testing.T) {
+	var num Numeric
+	s := "1.5"
+	if err := num.ScanValue(pool.NewBytesReader([]byte(s)), len(s)); err != nil {
+		t.Fatal(err)
+	}
+	cp := num
+
+	s = "2.5"
+	if err := num.ScanValue(pool.NewBytesReader([]byte(s)), len(s)); err != nil {
+		t.Fatal(err)
+	}
+	if got := cp.String(); got != "1.5" {
+		t.Fatalf("got %q, wanted 1.5", got)
+	}
+
+	if got := (Numeric{Valid: true}).String(); got != "0" {
+		t.Fatalf("got %q, wanted 0", got)
+	}
+}
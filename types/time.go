@@ -54,3 +54,29 @@ func AppendTime(b []byte, tm time.Time, flags int) []byte {
 	}
 	return b
 }
+
+// AppendTimestamp appends tm as timestamp without time zone using the wall
+// clock of tm in its own location, i.e. the time is not converted to UTC.
+func AppendTimestamp(b []byte, tm time.Time, flags int) []byte {
+	if flags == 1 {
+		b = append(b, '\'')
+	}
+	b = tm.AppendFormat(b, timestampFormat)
+	if flags == 1 {
+		b = append(b, '\'')
+	}
+	return b
+}
+
+// Timestamp is a time.Time wrapper that is formatted as timestamp without
+// time zone, i.e. as the wall clock in its own location instead of UTC.
+// It is meant for query params compared with timestamp columns.
+type Timestamp struct {
+	time.Time
+}
+
+var _ ValueAppender = (*Timestamp)(nil)
+
+func (tm Timestamp) AppendValue(b []byte, flags int) ([]byte, error) {
+	return AppendTimestamp(b, tm.Time, flags), nil
+}
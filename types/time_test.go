@@ -30,6 +30,19 @@ func TestParseTimeString(t *testing.T) {
 	}
 }
 
+func TestTimestampAppendValue(t *testing.T) {
+	loc := time.FixedZone("UTC+1", 3600)
+	tm := types.Timestamp{Time: time.Date(2021, time.March, 14, 10, 0, 0, 0, loc)}
+
+	b, err := tm.AppendValue(nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, wanted := string(b), "'2021-03-14 10:00:00'"; got != wanted {
+		t.Fatalf("got %s, wanted %s", got, wanted)
+	}
+}
+
 func BenchmarkParseTime(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		types.ParseTimeString("2001-02-03 04:05:06+07")
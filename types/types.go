@@ -16,7 +16,8 @@ type ValueAppender interface {
 
 //------------------------------------------------------------------------------
 
-// Safe represents a safe SQL query.
+// Safe represents a safe SQL query. It is appended to the query verbatim
+// wherever it is used as a param, so it must never contain user input.
 type Safe string
 
 var _ ValueAppender = (*Safe)(nil)
@@ -28,6 +29,9 @@ func (q Safe) AppendValue(b []byte, flags int) ([]byte, error) {
 //------------------------------------------------------------------------------
 
 // Ident represents a SQL identifier, e.g. table or column name.
+// Ident is quoted wherever it is used as a param, e.g. in ColumnExpr,
+// TableExpr, Where, or Set. Dots separate qualified names and a "*"
+// segment is kept as a wildcard, e.g. Ident("book.*") is "book".*.
 type Ident string
 
 var _ ValueAppender = (*Ident)(nil)